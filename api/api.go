@@ -0,0 +1,29 @@
+// Package api embeds the OpenAPI 3 document describing the HTTP API.
+//
+// openapi.json is the contract for the handlers in internal/handler and the
+// client in pkg/client; change all three together.
+package api
+
+import (
+	_ "embed"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Spec is the OpenAPI document as served at /api/openapi.json.
+//
+//go:embed openapi.json
+var Spec []byte
+
+// Load parses and validates Spec.
+func Load() (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(Spec)
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	"github.com/Siddarth2230/url-shortener/internal/bulk"
+	"github.com/Siddarth2230/url-shortener/internal/models"
+	"github.com/Siddarth2230/url-shortener/internal/screen"
+)
+
+func runImport(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("import", "-file <path> [-format csv|jsonl] [-rejects <path>] [-checkpoint <path>] [-batch N]")
+	file := fs.String("file", "", "input file (required)")
+	format := fs.String("format", "", "csv or jsonl (default: from file extension)")
+	rejects := fs.String("rejects", "", "reject file (default: <file>.rejects.jsonl)")
+	checkpoint := fs.String("checkpoint", "", "checkpoint file (default: <file>.checkpoint)")
+	batch := fs.Int("batch", 5000, "rows per COPY batch")
+	quiet := fs.Bool("q", false, "don't print progress to stderr")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *file == "" || fs.NArg() != 0 {
+		return usageErr(fs, "-file is required")
+	}
+	f, err := bulk.ParseFormat(*format, *file)
+	if err != nil {
+		return usageErr(fs, err.Error())
+	}
+	// Imported destinations are screened like links created through the API
+	if a.svc.Screener, err = importScreener(); err != nil {
+		return err
+	}
+
+	opts := bulk.ImportOptions{
+		Path:           *file,
+		Format:         f,
+		RejectPath:     *rejects,
+		CheckpointPath: *checkpoint,
+		BatchSize:      *batch,
+	}
+	if !*quiet {
+		opts.Progress = func(cp bulk.Checkpoint) {
+			fmt.Fprintf(os.Stderr, "line %d: imported=%d unchanged=%d rejected=%d\n", cp.Line, cp.Imported, cp.Unchanged, cp.Rejected)
+		}
+	}
+
+	cp, err := bulk.Import(ctx, a.svc, opts)
+	if err != nil {
+		if cp != nil {
+			fmt.Fprintf(os.Stderr, "urlctl import: stopped after line %d; rerun the same command to resume\n", cp.Line)
+		}
+		return err
+	}
+	return a.out.importSummary(cp)
+}
+
+// importScreener builds the API's screening chain from the same SCREEN_* variables.
+func importScreener() (*screen.Chain, error) {
+	chain := &screen.Chain{FailClosed: os.Getenv("SCREEN_FAIL_CLOSED") == "true"}
+	if path := os.Getenv("SCREEN_LISTS_PATH"); path != "" {
+		lists, err := screen.LoadLists(path)
+		if err != nil {
+			return nil, fmt.Errorf("load screening lists: %w", err)
+		}
+		chain.Screeners = append(chain.Screeners, lists)
+	}
+	network := &screen.Network{}
+	if os.Getenv("SCREEN_RESOLVE_DNS") != "false" {
+		network.Resolver = net.DefaultResolver
+	}
+	chain.Screeners = append(chain.Screeners, network, &screen.Homograph{BlockIDN: os.Getenv("SCREEN_BLOCK_IDN") == "true"})
+	return chain, nil
+}
+
+func runExport(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("export", "[-file <path>] [-format csv|jsonl] [-owner O] [-tag T] [-from T] [-to T]")
+	file := fs.String("file", "-", "output file, - for stdout")
+	format := fs.String("format", "", "csv or jsonl (default: from file extension, jsonl for stdout)")
+	owner := fs.String("owner", "", "only links owned by this owner")
+	tag := fs.String("tag", "", "only links with this tag")
+	from := fs.String("from", "", "only links created at or after this time (RFC3339)")
+	to := fs.String("to", "", "only links created before this time (RFC3339)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return usageErr(fs, "unexpected arguments")
+	}
+
+	formatName := *format
+	if formatName == "" && *file == "-" {
+		formatName = string(bulk.FormatJSONL)
+	}
+	f, err := bulk.ParseFormat(formatName, *file)
+	if err != nil {
+		return usageErr(fs, err.Error())
+	}
+
+	filter := models.URLFilter{Owner: *owner, Tag: *tag}
+	if filter.CreatedAfter, err = parseOptionalTime(*from); err != nil {
+		return usageErr(fs, "invalid -from: "+err.Error())
+	}
+	if filter.CreatedBefore, err = parseOptionalTime(*to); err != nil {
+		return usageErr(fs, "invalid -to: "+err.Error())
+	}
+
+	var w io.Writer = os.Stdout
+	if *file != "-" {
+		out, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		w = out
+	}
+
+	n, err := bulk.Export(ctx, a.svc, w, f, filter)
+	if err != nil {
+		return err
+	}
+	if *file != "-" {
+		fmt.Fprintf(os.Stderr, "exported %d links to %s\n", n, *file)
+	}
+	return nil
+}
+
+func parseOptionalTime(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, err
+	}
+	t = t.UTC()
+	return &t, nil
+}
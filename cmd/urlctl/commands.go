@@ -0,0 +1,398 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+	"github.com/Siddarth2230/url-shortener/internal/service"
+)
+
+func runCreate(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("create", "-url <long url> [-code <custom code>]")
+	longURL := fs.String("url", "", "destination URL (required)")
+	code := fs.String("code", "", "custom short code")
+	interstitial := fs.Bool("interstitial", false, "show a preview page before redirecting")
+	title := fs.String("title", "", "link title")
+	notes := fs.String("notes", "", "free-form notes")
+	tags := fs.String("tags", "", "comma-separated tags")
+	queryPolicy := fs.String("query-policy", "", "forward the short URL's query: ignore, merge or override")
+	utmFlag := fs.String("utm", "", "default UTM parameters, e.g. source=newsletter,medium=email")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *longURL == "" {
+		return usageErr(fs, "-url is required")
+	}
+	utm, err := parseUTM(*utmFlag)
+	if err != nil {
+		return usageErr(fs, err.Error())
+	}
+	if *code == "" && !a.hasRedis {
+		return fmt.Errorf("generating a short code needs Redis; pass -code or -redis")
+	}
+
+	resp, err := a.svc.ShortenURL(ctx, models.ShortenRequest{
+		URL:          *longURL,
+		Domain:       a.domain,
+		CustomCode:   *code,
+		Interstitial: *interstitial,
+		QueryPolicy:  *queryPolicy,
+		UTM:          utm,
+		Title:        *title,
+		Notes:        *notes,
+		Tags:         splitList(*tags),
+	})
+	if err != nil {
+		return err
+	}
+	return a.out.shortenResponse(resp)
+}
+
+func runInspect(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("inspect", "<code>")
+	code, err := parseWithCode(fs, args)
+	if err != nil {
+		return err
+	}
+
+	u, err := a.svc.GetURL(ctx, a.domain, code)
+	if err != nil {
+		return err
+	}
+	return a.out.urls([]*models.URL{u})
+}
+
+func runUpdate(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("update", "<code> -url <long url>")
+	longURL := fs.String("url", "", "new destination URL (required)")
+	code, err := parseWithCode(fs, args)
+	if err != nil {
+		return err
+	}
+	if *longURL == "" {
+		return usageErr(fs, "-url is required")
+	}
+
+	u, err := a.svc.UpdateLongURL(ctx, a.domain, code, *longURL)
+	if err != nil {
+		return err
+	}
+	return a.out.urls([]*models.URL{u})
+}
+
+func runDelete(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("delete", "<code>")
+	code, err := parseWithCode(fs, args)
+	if err != nil {
+		return err
+	}
+
+	// Resolve first so a missing code maps to the not-found exit code
+	if _, err := a.svc.GetURL(ctx, a.domain, code); err != nil {
+		return err
+	}
+	if err := a.svc.DeleteShortCode(ctx, a.domain, code); err != nil {
+		return err
+	}
+	return a.out.result("deleted", code)
+}
+
+func runSearch(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("search", "[-limit N] <query>")
+	limit := fs.Int("limit", 50, "maximum number of results (1-1000)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageErr(fs, "exactly one query is required")
+	}
+
+	urls, err := a.svc.SearchURLs(ctx, fs.Arg(0), *limit)
+	if err != nil {
+		return err
+	}
+	return a.out.urls(urls)
+}
+
+func runExpire(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("expire", "<code> [-at <RFC3339 time> | -in <duration> | -clear]")
+	at := fs.String("at", "", "expire at this time (RFC3339)")
+	in := fs.Duration("in", 0, "expire after this duration from now")
+	clear := fs.Bool("clear", false, "remove the expiry")
+	code, err := parseWithCode(fs, args)
+	if err != nil {
+		return err
+	}
+
+	set := 0
+	for _, b := range []bool{*at != "", *in != 0, *clear} {
+		if b {
+			set++
+		}
+	}
+	if set > 1 {
+		return usageErr(fs, "-at, -in and -clear are mutually exclusive")
+	}
+
+	// Default: expire immediately
+	var expiresAt *time.Time
+	switch {
+	case *clear:
+		expiresAt = nil
+	case *at != "":
+		t, err := time.Parse(time.RFC3339, *at)
+		if err != nil {
+			return usageErr(fs, fmt.Sprintf("invalid -at: %v", err))
+		}
+		t = t.UTC()
+		expiresAt = &t
+	default:
+		t := time.Now().UTC().Add(*in)
+		expiresAt = &t
+	}
+
+	u, err := a.svc.ExpireShortCode(ctx, a.domain, code, expiresAt)
+	if err != nil {
+		return err
+	}
+	return a.out.urls([]*models.URL{u})
+}
+
+func runStatus(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("status", "<code> -set active|disabled|quarantined [-reason text]")
+	status := fs.String("set", "", "new status (required)")
+	reason := fs.String("reason", "", `why the status changed; "legal" serves disabled links as 451`)
+	code, err := parseWithCode(fs, args)
+	if err != nil {
+		return err
+	}
+	if *status == "" {
+		return usageErr(fs, "-set is required")
+	}
+
+	u, err := a.svc.SetStatus(ctx, a.domain, code, *status, *reason)
+	if err != nil {
+		return err
+	}
+	return a.out.urls([]*models.URL{u})
+}
+
+func runPurge(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("purge", "<code>")
+	code, err := parseWithCode(fs, args)
+	if err != nil {
+		return err
+	}
+	if !a.hasRedis {
+		return fmt.Errorf("purging caches needs Redis; pass -redis")
+	}
+
+	if err := a.svc.PurgeCache(ctx, a.domain, code); err != nil {
+		return err
+	}
+	return a.out.result("purged", code)
+}
+
+func runStats(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("stats", "<code> [-days N]")
+	days := fs.Int("days", 30, "number of days in the daily breakdown")
+	code, err := parseWithCode(fs, args)
+	if err != nil {
+		return err
+	}
+
+	stats, err := a.svc.GetClickStats(ctx, a.domain, code, *days)
+	if err != nil {
+		return err
+	}
+	return a.out.clickStats(stats)
+}
+
+func newFlagSet(name, synopsis string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: urlctl %s %s\n", name, synopsis)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses a command's flags. The flag set has already reported a bad
+// flag, so errors other than -h are returned as usage errors.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	return err
+}
+
+// parseWithCode parses a command that takes exactly one short code. The code may
+// appear before or after the flags (`urlctl update abc -url x` or `urlctl update -url x abc`).
+func parseWithCode(fs *flag.FlagSet, args []string) (string, error) {
+	var code string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		code, args = args[0], args[1:]
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return "", err
+	}
+	if code == "" && fs.NArg() == 1 {
+		code = fs.Arg(0)
+	} else if fs.NArg() != 0 {
+		return "", usageErr(fs, "unexpected arguments: "+strings.Join(fs.Args(), " "))
+	}
+	if code == "" {
+		return "", usageErr(fs, "a short code is required")
+	}
+	return code, nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func runSweep(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("sweep", "[-grace 24h] [-delete]")
+	grace := fs.Duration("grace", 24*time.Hour, "only sweep links expired for longer than this")
+	del := fs.Bool("delete", false, "delete instead of archiving to urls_archive")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	sweeper := service.NewExpirySweeper(a.svc)
+	sweeper.Grace = *grace
+	sweeper.Archive = !*del
+	n, err := sweeper.SweepOnce(ctx)
+	if err != nil {
+		return err
+	}
+	return a.out.count("swept", n)
+}
+
+func runAPIKey(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("apikey", "[-tier name] create|revoke <name>")
+	tier := fs.String("tier", "", "custom code length tier of a new key (default tier if empty)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return usageErr(fs, "expected create or revoke and a key name")
+	}
+	action, name := fs.Arg(0), fs.Arg(1)
+
+	switch action {
+	case "create":
+		raw, _, err := a.svc.CreateAPIKey(ctx, name, *tier)
+		if err != nil {
+			return err
+		}
+		// The key is only stored hashed; this is the one chance to copy it
+		fmt.Fprintf(os.Stderr, "API key for %s (shown once):\n", name)
+		_, err = fmt.Fprintln(os.Stdout, raw)
+		return err
+	case "revoke":
+		if err := a.svc.RevokeAPIKey(ctx, name); err != nil {
+			return err
+		}
+		return a.out.result("revoked", name)
+	}
+	return usageErr(fs, "expected create or revoke")
+}
+
+func runCollisions(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("collisions", "")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	collisions, err := a.svc.CaseCollisions(ctx)
+	if err != nil {
+		return err
+	}
+	if err := a.out.caseCollisions(collisions); err != nil {
+		return err
+	}
+	if len(collisions) > 0 {
+		// Non-zero exit so migration scripts stop here
+		return fmt.Errorf("%d codes collide when case is ignored; rename or delete all but one of each", len(collisions))
+	}
+	return nil
+}
+
+func runReserved(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("reserved", "list | [-blocked] [-note text] add <word> | remove <word>")
+	blocked := fs.Bool("blocked", false, "block the word anywhere in a code (profanity, trademarks) instead of reserving it exactly")
+	note := fs.String("note", "", "why the word is reserved")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	switch {
+	case fs.NArg() == 1 && fs.Arg(0) == "list":
+		words, err := a.svc.ListReservedWords(ctx)
+		if err != nil {
+			return err
+		}
+		return a.out.reservedWords(words)
+	case fs.NArg() == 2 && fs.Arg(0) == "add":
+		kind := models.ReservedExact
+		if *blocked {
+			kind = models.ReservedBlocked
+		}
+		w, err := a.svc.AddReservedWord(ctx, fs.Arg(1), kind, *note)
+		if err != nil {
+			return err
+		}
+		return a.out.result(kind, w.Word)
+	case fs.NArg() == 2 && fs.Arg(0) == "remove":
+		if err := a.svc.RemoveReservedWord(ctx, fs.Arg(1)); err != nil {
+			return err
+		}
+		return a.out.result("removed", fs.Arg(1))
+	}
+	return usageErr(fs, "expected list, add <word> or remove <word>")
+}
+
+// parseUTM parses "source=x,medium=y" into UTM parameters (nil when empty).
+func parseUTM(s string) (*models.UTM, error) {
+	items := splitList(s)
+	if len(items) == 0 {
+		return nil, nil
+	}
+	utm := &models.UTM{}
+	fields := map[string]*string{
+		"source":   &utm.Source,
+		"medium":   &utm.Medium,
+		"campaign": &utm.Campaign,
+		"term":     &utm.Term,
+		"content":  &utm.Content,
+	}
+	for _, item := range items {
+		name, value, _ := strings.Cut(item, "=")
+		field, ok := fields[strings.TrimPrefix(strings.TrimSpace(name), "utm_")]
+		if !ok {
+			return nil, fmt.Errorf("unknown UTM parameter %q", name)
+		}
+		*field = strings.TrimSpace(value)
+	}
+	return utm, nil
+}
+
+func usageErr(fs *flag.FlagSet, msg string) error {
+	fmt.Fprintf(os.Stderr, "urlctl %s: %s\n", fs.Name(), msg)
+	fs.Usage()
+	return errUsage
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+	"github.com/Siddarth2230/url-shortener/internal/repository"
+	"github.com/Siddarth2230/url-shortener/internal/service"
+)
+
+// urlRow has the columns FindAnyByShortCode selects.
+var urlRow = []string{"id", "domain", "short_code", "long_url", "created_at", "expires_at", "owner", "password_hash",
+	"max_clicks", "clicks_remaining", "rules", "status", "status_reason", "interstitial", "title", "notes",
+	"query_policy", "utm", "variants"}
+
+// newTestApp runs commands against the real service with sqlmock in place of
+// PostgreSQL and no Redis, printing to the returned buffer.
+func newTestApp(t *testing.T, format string) (*app, *bytes.Buffer, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.MatchExpectationsInOrder(false)
+	t.Cleanup(func() { db.Close() })
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := service.NewURLService(repository.NewURLRepository(db, logger), nil, "https://sho.rt", 1, logger)
+	var buf bytes.Buffer
+	out, err := newPrinter(&buf, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &app{svc: svc, out: out}, &buf, mock
+}
+
+func expectFind(mock sqlmock.Sqlmock, code string, found bool) {
+	rows := sqlmock.NewRows(urlRow)
+	if found {
+		rows.AddRow(1, "", code, "https://example.com/"+code, time.Now(), nil, nil, nil, nil, nil, nil, "active", nil, false, nil, nil, "ignore", nil, []byte("[]"))
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("FROM urls")).WithArgs("", code).WillReturnRows(rows)
+}
+
+func TestInspectJSON(t *testing.T) {
+	a, out, mock := newTestApp(t, "json")
+	expectFind(mock, "promo", true)
+
+	if err := runInspect(context.Background(), a, []string{"promo"}); err != nil {
+		t.Fatal(err)
+	}
+	var urls []models.URL
+	if err := json.Unmarshal(out.Bytes(), &urls); err != nil {
+		t.Fatalf("output %q: %v", out, err)
+	}
+	if len(urls) != 1 || urls[0].ShortCode != "promo" || urls[0].LongURL != "https://example.com/promo" {
+		t.Errorf("inspect = %+v", urls)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestInspectNotFound(t *testing.T) {
+	a, out, mock := newTestApp(t, "table")
+	expectFind(mock, "missing", false)
+
+	err := runInspect(context.Background(), a, []string{"missing"})
+	if code := exitCode(err); code != exitNotFound {
+		t.Errorf("exit code = %d (%v), want %d", code, err, exitNotFound)
+	}
+	if out.Len() != 0 {
+		t.Errorf("printed %q", out)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDeleteCommand(t *testing.T) {
+	a, out, mock := newTestApp(t, "table")
+	expectFind(mock, "promo", true)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("DELETE FROM urls")).WithArgs("", "promo").WillReturnRows(sqlmock.NewRows(urlRow[:18]).
+		AddRow(1, "", "promo", "https://example.com/promo", time.Now(), nil, nil, nil, nil, nil, nil, "active", nil, false, nil, nil, "ignore", nil))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO code_tombstones")).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := runDelete(context.Background(), a, []string{"promo"}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "deleted promo\n" {
+		t.Errorf("output = %q", out)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// Bad input is a usage error and never reaches the database.
+func TestCommandUsageErrors(t *testing.T) {
+	a, _, mock := newTestApp(t, "table")
+	tests := []struct {
+		name string
+		run  func(context.Context, *app, []string) error
+		args []string
+	}{
+		{"update without -url", runUpdate, []string{"promo"}},
+		{"inspect with two codes", runInspect, []string{"a", "b"}},
+		{"create without -url", runCreate, []string{"-code", "promo"}},
+		{"unknown flag", runDelete, []string{"-force", "promo"}},
+	}
+	for _, tt := range tests {
+		if code := exitCode(tt.run(context.Background(), a, tt.args)); code != exitUsage {
+			t.Errorf("%s: exit code = %d, want %d", tt.name, code, exitUsage)
+		}
+	}
+
+	// Generated codes need the Redis counter
+	err := runCreate(context.Background(), a, []string{"-url", "https://example.com"})
+	if err == nil || !strings.Contains(err.Error(), "Redis") || exitCode(err) != exitErr {
+		t.Errorf("create without Redis = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Siddarth2230/url-shortener/internal/bulk"
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+// printer renders command results as JSON (for scripts) or aligned tables (for humans).
+type printer struct {
+	w    io.Writer
+	json bool
+}
+
+func newPrinter(w io.Writer, format string) (*printer, error) {
+	switch format {
+	case "json":
+		return &printer{w: w, json: true}, nil
+	case "table":
+		return &printer{w: w}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q (want table or json)", format)
+	}
+}
+
+func (p *printer) encode(v interface{}) error {
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (p *printer) shortenResponse(resp *models.ShortenResponse) error {
+	if p.json {
+		return p.encode(resp)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CODE\tSHORT URL\tLONG URL")
+	fmt.Fprintf(tw, "%s\t%s\t%s\n", resp.ShortCode, resp.ShortURL, resp.LongURL)
+	return tw.Flush()
+}
+
+func (p *printer) urls(urls []*models.URL) error {
+	if p.json {
+		if urls == nil {
+			urls = []*models.URL{}
+		}
+		return p.encode(urls)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "DOMAIN\tCODE\tLONG URL\tSTATUS\tCREATED\tEXPIRES")
+	for _, u := range urls {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", formatDomain(u.Domain), u.ShortCode, u.LongURL, u.Status, formatTime(&u.CreatedAt), formatTime(u.ExpiresAt))
+	}
+	return tw.Flush()
+}
+
+func (p *printer) clickStats(stats *models.ClickStats) error {
+	if p.json {
+		return p.encode(stats)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Code:\t%s\n", stats.ShortCode)
+	fmt.Fprintf(tw, "Total clicks:\t%d\n", stats.TotalClicks)
+	fmt.Fprintf(tw, "Unique visitors:\t%d\n", stats.UniqueVisitors)
+	fmt.Fprintf(tw, "First click:\t%s\n", formatTime(stats.FirstClickAt))
+	fmt.Fprintf(tw, "Last click:\t%s\n", formatTime(stats.LastClickAt))
+	if len(stats.Variants) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "VARIANT\tWEIGHT\tCLICKS\tUNIQUE\tSHARE\tDESTINATION")
+		for _, v := range stats.Variants {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.1f%%\t%s\n", v.Name, v.Weight, v.Clicks, v.UniqueVisitors, v.Share*100, v.Destination)
+		}
+	}
+	if len(stats.Daily) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "DAY\tCLICKS")
+		for _, d := range stats.Daily {
+			fmt.Fprintf(tw, "%s\t%d\n", d.Day.Format("2006-01-02"), d.Clicks)
+		}
+	}
+	return tw.Flush()
+}
+
+func (p *printer) importSummary(cp *bulk.Checkpoint) error {
+	if p.json {
+		return p.encode(cp)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Source:\t%s\n", cp.Source)
+	fmt.Fprintf(tw, "Lines read:\t%d\n", cp.Line)
+	fmt.Fprintf(tw, "Imported:\t%d\n", cp.Imported)
+	fmt.Fprintf(tw, "Unchanged:\t%d\n", cp.Unchanged)
+	fmt.Fprintf(tw, "Rejected:\t%d\n", cp.Rejected)
+	return tw.Flush()
+}
+
+func (p *printer) caseCollisions(collisions []*models.CaseCollision) error {
+	if p.json {
+		if collisions == nil {
+			collisions = []*models.CaseCollision{}
+		}
+		return p.encode(collisions)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "DOMAIN\tFOLDED\tCODE\tLONG URL")
+	for _, c := range collisions {
+		for i, code := range c.Codes {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", formatDomain(c.Domain), c.Folded, code, c.LongURLs[i])
+		}
+	}
+	return tw.Flush()
+}
+
+func (p *printer) reservedWords(words []*models.ReservedWord) error {
+	if p.json {
+		if words == nil {
+			words = []*models.ReservedWord{}
+		}
+		return p.encode(words)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "WORD\tKIND\tADDED\tNOTE")
+	for _, w := range words {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", w.Word, w.Kind, formatTime(&w.CreatedAt), w.Note)
+	}
+	return tw.Flush()
+}
+
+// result prints the outcome of commands that have no other output (delete, purge).
+func (p *printer) result(action, code string) error {
+	if p.json {
+		return p.encode(map[string]string{"short_code": code, "result": action})
+	}
+	_, err := fmt.Fprintf(p.w, "%s %s\n", action, code)
+	return err
+}
+
+// count prints how many items a batch command affected.
+func (p *printer) count(action string, n int) error {
+	if p.json {
+		return p.encode(map[string]int{action: n})
+	}
+	_, err := fmt.Fprintf(p.w, "%s %d\n", action, n)
+	return err
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatDomain(domain string) string {
+	if domain == "" {
+		return "(default)"
+	}
+	return domain
+}
@@ -0,0 +1,45 @@
+// Package bulk streams links in and out of the database as CSV or JSONL files.
+//
+// Both formats use the same fields: short_code, long_url, created_at, expires_at,
+// owner, tags, domain (empty for the default domain), title and notes. Timestamps are RFC 3339; in CSV, tags are separated by "|".
+package bulk
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Format is a bulk file format.
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+)
+
+// csvColumns is the header written by exports and the set of columns imports understand.
+var csvColumns = []string{"short_code", "long_url", "created_at", "expires_at", "owner", "tags", "domain", "title", "notes"}
+
+// tagSeparator joins multiple tags inside a single CSV field.
+const tagSeparator = "|"
+
+// ParseFormat parses a format name. An empty name is resolved from the file extension.
+func ParseFormat(name, path string) (Format, error) {
+	if name == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".csv":
+			return FormatCSV, nil
+		case ".jsonl", ".ndjson":
+			return FormatJSONL, nil
+		}
+		return "", fmt.Errorf("can't infer format from %q; pass csv or jsonl explicitly", path)
+	}
+	switch Format(strings.ToLower(name)) {
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatJSONL, "ndjson":
+		return FormatJSONL, nil
+	}
+	return "", fmt.Errorf("unknown format %q (want csv or jsonl)", name)
+}
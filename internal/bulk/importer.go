@@ -0,0 +1,294 @@
+package bulk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+	"github.com/Siddarth2230/url-shortener/internal/service"
+)
+
+// ImportOptions configures an Import run.
+type ImportOptions struct {
+	Path   string // input file; must be seekable for checkpoints to work
+	Format Format
+
+	// RejectPath receives one JSON object per rejected row (invalid rows and
+	// conflicting codes). Defaults to Path + ".rejects.jsonl".
+	RejectPath string
+
+	// CheckpointPath records progress after every committed batch so an
+	// interrupted import can resume. Defaults to Path + ".checkpoint".
+	CheckpointPath string
+
+	BatchSize int // rows per COPY batch, default 5000
+
+	// Progress, if set, is called after every committed batch.
+	Progress func(Checkpoint)
+}
+
+// Checkpoint is the persisted progress of an import.
+type Checkpoint struct {
+	Source    string `json:"source"`
+	Format    Format `json:"format"`
+	Offset    int64  `json:"offset"` // byte offset of the next unread row
+	Line      int    `json:"line"`   // last line consumed
+	Imported  int    `json:"imported"`
+	Unchanged int    `json:"unchanged"`
+	Rejected  int    `json:"rejected"`
+	// RejectOffset is the size of the reject file after the last checkpointed
+	// batch. Resuming truncates the file to it, so a replayed batch doesn't
+	// write its rejects twice.
+	RejectOffset int64     `json:"reject_offset"`
+	Done         bool      `json:"done"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Rejection is one line of the reject file.
+type Rejection struct {
+	Line      int    `json:"line"`
+	Domain    string `json:"domain,omitempty"`
+	ShortCode string `json:"short_code,omitempty"`
+	LongURL   string `json:"long_url,omitempty"`
+	Reason    string `json:"reason"`
+	Error     string `json:"error"`
+}
+
+// Import streams opts.Path into the database in batches through svc.ImportURLs.
+//
+// After each batch commits, rejected rows are appended to the reject file and
+// the checkpoint is rewritten. If a checkpoint for the same file exists, the
+// import resumes from it. A batch that committed but whose checkpoint wasn't
+// written is simply replayed: its rows are counted as unchanged, not conflicts,
+// and its rejects replace any it wrote before the crash.
+func Import(ctx context.Context, svc *service.URLService, opts ImportOptions) (*Checkpoint, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 5000
+	}
+	if opts.RejectPath == "" {
+		opts.RejectPath = opts.Path + ".rejects.jsonl"
+	}
+	if opts.CheckpointPath == "" {
+		opts.CheckpointPath = opts.Path + ".checkpoint"
+	}
+
+	source, err := filepath.Abs(opts.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	cp, err := loadCheckpoint(opts.CheckpointPath)
+	if err != nil {
+		return nil, err
+	}
+	resuming := cp != nil
+	if resuming {
+		if cp.Source != source || cp.Format != opts.Format {
+			return nil, fmt.Errorf("checkpoint %s belongs to %s (%s); remove it to start over", opts.CheckpointPath, cp.Source, cp.Format)
+		}
+		if cp.Done {
+			return cp, nil
+		}
+	} else {
+		cp = &Checkpoint{Source: source, Format: opts.Format}
+	}
+
+	f, err := os.Open(opts.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader, err := openReader(f, opts.Format, cp)
+	if err != nil {
+		return nil, err
+	}
+
+	rejectFile, err := openRejects(opts.RejectPath, cp.RejectOffset)
+	if err != nil {
+		return nil, err
+	}
+	defer rejectFile.Close()
+	rejectEnc := json.NewEncoder(rejectFile)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return cp, err
+		}
+
+		records, eof, err := readBatch(reader, opts.BatchSize)
+		if err != nil {
+			return cp, err
+		}
+
+		var urls []*models.URL
+		var urlRecords []*Record
+		var rejects []Rejection
+		for _, rec := range records {
+			if rec.Err != nil {
+				rejects = append(rejects, Rejection{Line: rec.Line, Reason: service.RejectInvalid, Error: rec.Err.Error()})
+				continue
+			}
+			urls = append(urls, rec.URL)
+			urlRecords = append(urlRecords, rec)
+		}
+
+		if len(urls) > 0 {
+			res, err := svc.ImportURLs(ctx, urls)
+			if err != nil {
+				return cp, fmt.Errorf("import batch ending at line %d: %w", reader.Line(), err)
+			}
+			cp.Imported += res.Imported
+			cp.Unchanged += res.Unchanged
+			for _, rj := range res.Rejected {
+				rec := urlRecords[rj.Index]
+				rejects = append(rejects, Rejection{
+					Line:      rec.Line,
+					Domain:    rec.URL.Domain,
+					ShortCode: rec.URL.ShortCode,
+					LongURL:   rec.URL.LongURL,
+					Reason:    rj.Reason,
+					Error:     rj.Detail,
+				})
+			}
+		}
+
+		sort.Slice(rejects, func(i, j int) bool { return rejects[i].Line < rejects[j].Line })
+		for _, rj := range rejects {
+			if err := rejectEnc.Encode(rj); err != nil {
+				return cp, fmt.Errorf("write reject file: %w", err)
+			}
+		}
+		if err := rejectFile.Sync(); err != nil {
+			return cp, fmt.Errorf("sync reject file: %w", err)
+		}
+		cp.Rejected += len(rejects)
+		if cp.RejectOffset, err = rejectFile.Seek(0, io.SeekCurrent); err != nil {
+			return cp, err
+		}
+
+		cp.Offset = reader.Offset()
+		cp.Line = reader.Line()
+		cp.Done = eof
+		if err := saveCheckpoint(opts.CheckpointPath, cp); err != nil {
+			return cp, err
+		}
+		if opts.Progress != nil {
+			opts.Progress(*cp)
+		}
+		if eof {
+			return cp, nil
+		}
+	}
+}
+
+// openRejects opens the reject file for writing at offset, dropping anything
+// written after it by a batch that never reached the checkpoint.
+func openRejects(path string, offset int64) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Truncate(offset); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("truncate reject file: %w", err)
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("seek reject file: %w", err)
+	}
+	return f, nil
+}
+
+func openReader(f *os.File, format Format, cp *Checkpoint) (RecordReader, error) {
+	switch format {
+	case FormatCSV:
+		header, headerEnd, err := ReadCSVHeader(f)
+		if err != nil {
+			return nil, err
+		}
+		offset, line := headerEnd, 1
+		if cp.Offset > 0 {
+			offset, line = cp.Offset, cp.Line
+		}
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("seek to offset %d: %w", offset, err)
+		}
+		return NewCSVReader(f, header, offset, line)
+	case FormatJSONL:
+		if _, err := f.Seek(cp.Offset, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("seek to offset %d: %w", cp.Offset, err)
+		}
+		return NewJSONLReader(f, cp.Offset, cp.Line), nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// readBatch reads up to n records. eof reports whether the input is exhausted.
+func readBatch(r RecordReader, n int) (records []*Record, eof bool, err error) {
+	for len(records) < n {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return records, true, nil
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("read line %d: %w", r.Line()+1, err)
+		}
+		records = append(records, rec)
+	}
+	return records, false, nil
+}
+
+func loadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %w", path, err)
+	}
+	return &cp, nil
+}
+
+// saveCheckpoint writes the checkpoint atomically (write to temp file, then rename).
+func saveCheckpoint(path string, cp *Checkpoint) error {
+	cp.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Export streams every link matching filter to w in the given format.
+// It returns the number of links written.
+func Export(ctx context.Context, svc *service.URLService, w io.Writer, format Format, filter models.URLFilter) (int, error) {
+	out, err := NewWriter(w, format)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	err = svc.ExportURLs(ctx, filter, func(u *models.URL) error {
+		n++
+		return out.Write(u)
+	})
+	if err != nil {
+		return n, err
+	}
+	return n, out.Flush()
+}
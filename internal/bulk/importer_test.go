@@ -0,0 +1,72 @@
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/Siddarth2230/url-shortener/internal/repository"
+	"github.com/Siddarth2230/url-shortener/internal/service"
+)
+
+// A batch that was written before a crash but never checkpointed is replayed
+// on resume; its rejects must end up in the reject file only once.
+func TestImportReplayWritesRejectsOnce(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := service.NewURLService(repository.NewURLRepository(db, logger), nil, "https://sho.rt", 1, logger)
+
+	dir := t.TempDir()
+	opts := ImportOptions{Path: filepath.Join(dir, "links.jsonl"), Format: FormatJSONL, BatchSize: 1}
+	if err := os.WriteFile(opts.Path, []byte("{bad\n{also bad\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// First run: checkpoint after batch one, then "crash" after writing the
+	// second batch's reject but before its checkpoint
+	var afterFirst Checkpoint
+	opts.Progress = func(cp Checkpoint) {
+		if cp.Line == 1 {
+			afterFirst = cp
+		}
+	}
+	if _, err := Import(context.Background(), svc, opts); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveCheckpoint(opts.Path+".checkpoint", &afterFirst); err != nil {
+		t.Fatal(err)
+	}
+
+	opts.Progress = nil
+	cp, err := Import(context.Background(), svc, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cp.Done || cp.Rejected != 2 {
+		t.Errorf("checkpoint = %+v, want done with 2 rejected", cp)
+	}
+
+	data, err := os.ReadFile(opts.Path + ".rejects.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := bytes.Count(data, []byte("\n")); n != 2 {
+		t.Errorf("reject file has %d lines, want 2:\n%s", n, data)
+	}
+	if int64(len(data)) != cp.RejectOffset {
+		t.Errorf("reject offset = %d, file size %d", cp.RejectOffset, len(data))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,193 @@
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+// Record is one parsed input row.
+type Record struct {
+	Line int         // 1-based line the row starts on
+	URL  *models.URL // nil when Err is set
+	Err  error       // row-level parse error; the row should be rejected
+}
+
+// RecordReader yields records one at a time. Offset reports the byte offset just
+// past the last record returned, which is where a resumed import should seek to.
+type RecordReader interface {
+	Next() (*Record, error)
+	Offset() int64
+	Line() int
+}
+
+// ReadCSVHeader reads the header row of a CSV file and returns the column names
+// along with the byte offset of the first data row.
+func ReadCSVHeader(r io.Reader) ([]string, int64, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, 0, fmt.Errorf("read csv header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+	return header, cr.InputOffset(), nil
+}
+
+type csvReader struct {
+	r        *csv.Reader
+	cols     map[string]int
+	baseOff  int64
+	baseLine int
+	lastLine int
+}
+
+// NewCSVReader reads CSV rows from r, which must be positioned at byte offset
+// `offset` of the file, right after `line` lines. header comes from ReadCSVHeader.
+func NewCSVReader(r io.Reader, header []string, offset int64, line int) (RecordReader, error) {
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		cols[name] = i
+	}
+	for _, required := range []string{"short_code", "long_url"} {
+		if _, ok := cols[required]; !ok {
+			return nil, fmt.Errorf("csv header is missing required column %q", required)
+		}
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(header)
+	return &csvReader{r: cr, cols: cols, baseOff: offset, baseLine: line, lastLine: line}, nil
+}
+
+func (c *csvReader) Next() (*Record, error) {
+	fields, err := c.r.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		c.lastLine = c.baseLine + parseErr.StartLine
+		return &Record{Line: c.lastLine, Err: parseErr.Err}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	line, _ := c.r.FieldPos(0)
+	c.lastLine = c.baseLine + line
+	rec := &Record{Line: c.lastLine}
+	rec.URL, rec.Err = c.parse(fields)
+	return rec, nil
+}
+
+func (c *csvReader) field(fields []string, name string) string {
+	if i, ok := c.cols[name]; ok {
+		return strings.TrimSpace(fields[i])
+	}
+	return ""
+}
+
+func (c *csvReader) parse(fields []string) (*models.URL, error) {
+	u := &models.URL{
+		ShortCode: c.field(fields, "short_code"),
+		LongURL:   c.field(fields, "long_url"),
+		Owner:     c.field(fields, "owner"),
+		Domain:    c.field(fields, "domain"),
+		Title:     c.field(fields, "title"),
+		Notes:     c.field(fields, "notes"),
+	}
+	if v := c.field(fields, "created_at"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid created_at: %w", err)
+		}
+		u.CreatedAt = t.UTC()
+	}
+	if v := c.field(fields, "expires_at"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expires_at: %w", err)
+		}
+		t = t.UTC()
+		u.ExpiresAt = &t
+	}
+	if v := c.field(fields, "tags"); v != "" {
+		u.Tags = splitTags(v)
+	}
+	return u, nil
+}
+
+func (c *csvReader) Offset() int64 { return c.baseOff + c.r.InputOffset() }
+
+func (c *csvReader) Line() int { return c.lastLine }
+
+type jsonlReader struct {
+	r      *bufio.Reader
+	offset int64
+	line   int
+}
+
+// NewJSONLReader reads one JSON object per line from r, which must be positioned
+// at byte offset `offset` of the file, right after `line` lines.
+func NewJSONLReader(r io.Reader, offset int64, line int) RecordReader {
+	return &jsonlReader{r: bufio.NewReaderSize(r, 64*1024), offset: offset, line: line}
+}
+
+func (j *jsonlReader) Next() (*Record, error) {
+	for {
+		raw, err := j.r.ReadBytes('\n')
+		if len(raw) == 0 && err == io.EOF {
+			return nil, io.EOF
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		j.offset += int64(len(raw))
+		j.line++
+
+		raw = bytes.TrimSpace(raw)
+		if len(raw) == 0 {
+			continue // tolerate blank lines
+		}
+
+		rec := &Record{Line: j.line}
+		var u models.URL
+		if err := json.Unmarshal(raw, &u); err != nil {
+			rec.Err = fmt.Errorf("invalid json: %w", err)
+			return rec, nil
+		}
+		u.ID = 0
+		if u.ExpiresAt != nil {
+			t := u.ExpiresAt.UTC()
+			u.ExpiresAt = &t
+		}
+		u.CreatedAt = u.CreatedAt.UTC()
+		rec.URL = &u
+		return rec, nil
+	}
+}
+
+func (j *jsonlReader) Offset() int64 { return j.offset }
+
+func (j *jsonlReader) Line() int { return j.line }
+
+func splitTags(s string) []string {
+	var tags []string
+	for _, t := range strings.Split(s, tagSeparator) {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,131 @@
+package bulk
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+func readAll(t *testing.T, r RecordReader) []*Record {
+	t.Helper()
+	var recs []*Record
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return recs
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		recs = append(recs, rec)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	input := "short_code,long_url,created_at,tags\n" +
+		"abc123,https://example.com/a,2024-01-02T03:04:05Z,x|y\n" +
+		"bad1,https://example.com/b,not-a-time,\n" +
+		"def456,https://example.com/c,,\n"
+
+	header, off, err := ReadCSVHeader(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewCSVReader(strings.NewReader(input[off:]), header, off, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recs := readAll(t, r)
+	if len(recs) != 3 {
+		t.Fatalf("got %d records, want 3", len(recs))
+	}
+
+	if recs[0].Line != 2 || recs[0].URL.ShortCode != "abc123" || len(recs[0].URL.Tags) != 2 {
+		t.Errorf("unexpected first record: line=%d url=%+v", recs[0].Line, recs[0].URL)
+	}
+	if recs[1].Err == nil || recs[1].Line != 3 {
+		t.Errorf("expected parse error on line 3, got %+v", recs[1])
+	}
+	if recs[2].URL == nil || !recs[2].URL.CreatedAt.IsZero() {
+		t.Errorf("expected zero created_at, got %+v", recs[2])
+	}
+	if r.Offset() != int64(len(input)) {
+		t.Errorf("Offset() = %d, want %d", r.Offset(), len(input))
+	}
+}
+
+func TestCSVReaderMissingColumn(t *testing.T) {
+	if _, err := NewCSVReader(strings.NewReader(""), []string{"short_code"}, 0, 1); err == nil {
+		t.Error("expected error for header without long_url")
+	}
+}
+
+func TestJSONLReaderResume(t *testing.T) {
+	lines := []string{
+		`{"short_code":"aaaa1","long_url":"https://example.com/1"}`,
+		``,
+		`{"short_code":"aaaa2","long_url":"https://example.com/2"}`,
+		`{not json}`,
+		`{"short_code":"aaaa3","long_url":"https://example.com/3"}`,
+	}
+	input := strings.Join(lines, "\n") + "\n"
+
+	// Read the first record, then resume from its offset like a checkpoint would
+	first := NewJSONLReader(strings.NewReader(input), 0, 0)
+	rec, err := first.Next()
+	if err != nil || rec.URL.ShortCode != "aaaa1" {
+		t.Fatalf("first record = %+v, %v", rec, err)
+	}
+
+	off, line := first.Offset(), first.Line()
+	rest := readAll(t, NewJSONLReader(strings.NewReader(input[off:]), off, line))
+	if len(rest) != 3 {
+		t.Fatalf("got %d records after resume, want 3", len(rest))
+	}
+	if rest[0].URL.ShortCode != "aaaa2" || rest[0].Line != 3 {
+		t.Errorf("unexpected record after resume: %+v", rest[0])
+	}
+	if rest[1].Err == nil || rest[1].Line != 4 {
+		t.Errorf("expected json error on line 4, got %+v", rest[1])
+	}
+	if rest[2].URL.ShortCode != "aaaa3" || rest[2].Line != 5 {
+		t.Errorf("unexpected last record: %+v", rest[2])
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	u := &models.URL{ShortCode: "abc123", LongURL: "https://example.com", Owner: "team-a", Tags: []string{"x", "y"}}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(u); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := buf.String()
+	header, off, err := ReadCSVHeader(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewCSVReader(strings.NewReader(data[off:]), header, off, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recs := readAll(t, r)
+	if len(recs) != 1 || recs[0].Err != nil {
+		t.Fatalf("unexpected records: %+v", recs)
+	}
+	got := recs[0].URL
+	if got.ShortCode != u.ShortCode || got.LongURL != u.LongURL || got.Owner != u.Owner || len(got.Tags) != 2 {
+		t.Errorf("round trip mismatch: got %+v, want %+v", got, u)
+	}
+}
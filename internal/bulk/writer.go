@@ -0,0 +1,76 @@
+package bulk
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+// Writer serializes exported links.
+type Writer interface {
+	Write(u *models.URL) error
+	// Flush writes any buffered data; call it once after the last Write.
+	Flush() error
+}
+
+// NewWriter returns a Writer for format f. CSV output starts with a header row.
+func NewWriter(w io.Writer, f Format) (Writer, error) {
+	switch f {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvColumns); err != nil {
+			return nil, err
+		}
+		return &csvWriter{w: cw}, nil
+	case FormatJSONL:
+		bw := bufio.NewWriter(w)
+		return &jsonlWriter{w: bw, enc: json.NewEncoder(bw)}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", f)
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) Write(u *models.URL) error {
+	expires := ""
+	if u.ExpiresAt != nil {
+		expires = u.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	return c.w.Write([]string{
+		u.ShortCode,
+		u.LongURL,
+		u.CreatedAt.UTC().Format(time.RFC3339),
+		expires,
+		u.Owner,
+		strings.Join(u.Tags, tagSeparator),
+		u.Domain,
+		u.Title,
+		u.Notes,
+	})
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type jsonlWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (j *jsonlWriter) Write(u *models.URL) error {
+	return j.enc.Encode(u)
+}
+
+func (j *jsonlWriter) Flush() error {
+	return j.w.Flush()
+}
@@ -0,0 +1,262 @@
+// Package codepolicy decides which custom short codes may be claimed.
+//
+// A code must use the allowed characters, fit the length limits of the
+// requester's tier, must not be a reserved word (such as the first segment of
+// a registered route) and must not contain a blocked term (profanity,
+// trademarks). Blocked terms are matched after folding common leetspeak
+// substitutions, so "sh1t" and "s-h-i-t" match "shit".
+package codepolicy
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Hard bounds on any tier's limits; MaxLength is the width of urls.short_code.
+const (
+	MinLength = 1
+	MaxLength = 32
+)
+
+// DefaultPunctuation is allowed in codes besides ASCII letters and digits.
+const DefaultPunctuation = "-_."
+
+var (
+	ErrInvalid  = errors.New("custom code is not allowed")
+	ErrReserved = errors.New("custom code is reserved")
+	ErrBlocked  = errors.New("custom code contains a blocked term")
+)
+
+// DefaultReserved are reserved whatever routes are registered, so tools that
+// don't see the API's router (urlctl) can't claim them either.
+var DefaultReserved = []string{"admin", "api", "health", "www", "root", "login", "status", "metrics", "shorten"}
+
+// DefaultLength is the length limit of tiers without their own.
+var DefaultLength = Length{Min: 4, Max: 10}
+
+// Length is an inclusive range of code lengths.
+type Length struct {
+	Min, Max int
+}
+
+// Policy holds the rules. Configure it at startup; Check is safe for
+// concurrent use as long as the policy isn't modified.
+type Policy struct {
+	Punctuation string            // allowed besides ASCII letters and digits
+	Default     Length            // limit of the default tier ("") and unlisted tiers
+	Lengths     map[string]Length // per-tier limits
+	reserved    map[string]struct{}
+	blocked     []string // folded
+}
+
+// New returns a policy with the default character set, length and reserved words.
+func New() *Policy {
+	p := &Policy{Punctuation: DefaultPunctuation, Default: DefaultLength}
+	p.Reserve(DefaultReserved...)
+	return p
+}
+
+// Reserve adds exact words (case-insensitive) that can't be claimed.
+func (p *Policy) Reserve(words ...string) {
+	if p.reserved == nil {
+		p.reserved = make(map[string]struct{})
+	}
+	for _, w := range words {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			p.reserved[w] = struct{}{}
+		}
+	}
+}
+
+// Block adds terms that can't appear anywhere in a code.
+func (p *Policy) Block(terms ...string) {
+	for _, t := range terms {
+		if t = Fold(t); t != "" {
+			p.blocked = append(p.blocked, t)
+		}
+	}
+}
+
+// LengthFor returns the length limit of a tier.
+func (p *Policy) LengthFor(tier string) Length {
+	if l, ok := p.Lengths[tier]; ok {
+		return l
+	}
+	return p.Default
+}
+
+// Check reports whether code may be claimed by a requester of the given tier.
+// Errors wrap ErrInvalid, ErrReserved or ErrBlocked.
+func (p *Policy) Check(code, tier string) error {
+	l := p.LengthFor(tier)
+	if len(code) < l.Min || len(code) > l.Max {
+		return fmt.Errorf("%w: must be %d-%d characters", ErrInvalid, l.Min, l.Max)
+	}
+	allDigits := true
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		switch {
+		case '0' <= c && c <= '9':
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+			allDigits = false
+		case strings.IndexByte(p.Punctuation, c) >= 0:
+			allDigits = false
+		default:
+			return fmt.Errorf("%w: may contain letters, numbers and %q", ErrInvalid, p.Punctuation)
+		}
+	}
+	// Purely numeric codes are confused with ID-based systems
+	if allDigits {
+		return fmt.Errorf("%w: must not be purely numeric", ErrInvalid)
+	}
+
+	if _, ok := p.reserved[strings.ToLower(code)]; ok {
+		return fmt.Errorf("%w: %q", ErrReserved, code)
+	}
+	for _, v := range Variants(code) {
+		for _, t := range p.blocked {
+			if strings.Contains(v, t) {
+				return ErrBlocked
+			}
+		}
+	}
+	return nil
+}
+
+// leet maps digits and symbols to the letters they usually stand in for.
+// "1" is ambiguous and handled by Variants.
+var leet = map[rune]rune{
+	'0': 'o', '1': 'i', '2': 'z', '3': 'e', '4': 'a', '5': 's',
+	'6': 'g', '7': 't', '8': 'b', '9': 'g', '@': 'a', '$': 's', '!': 'i', '|': 'l',
+}
+
+// Fold lowercases s, replaces leetspeak substitutions with letters and drops
+// everything else that isn't a letter, so separators can't split a term.
+func Fold(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if l, ok := leet[r]; ok {
+			r = l
+		}
+		if 'a' <= r && r <= 'z' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Variants returns the folded forms of a code: "1" is read as "i" and, if the
+// code contains one, also as "l".
+func Variants(code string) []string {
+	v := []string{Fold(code)}
+	if strings.Contains(code, "1") {
+		v = append(v, Fold(strings.ReplaceAll(code, "1", "l")))
+	}
+	return v
+}
+
+// ReservedFromRoutes returns the literal first path segment of each route
+// template, e.g. "api" for "/api/links/{shortCode}/stats". Templates whose
+// first segment is a variable ("/{shortCode}") reserve nothing.
+func ReservedFromRoutes(templates []string) []string {
+	seen := make(map[string]bool)
+	var words []string
+	for _, t := range templates {
+		seg, _, _ := strings.Cut(strings.TrimPrefix(t, "/"), "/")
+		if seg == "" || strings.Contains(seg, "{") || seen[seg] {
+			continue
+		}
+		seen[seg] = true
+		words = append(words, strings.ToLower(seg))
+	}
+	return words
+}
+
+// Config is the deployment-specific part of a policy, usually read from the environment.
+type Config struct {
+	Lengths      string // per-tier limits for ParseLengths; empty keeps DefaultLength
+	Punctuation  string // allowed punctuation; empty keeps DefaultPunctuation
+	BlockedWords string // path of a file of blocked terms for ParseTerms (optional)
+}
+
+// Load builds a policy from the defaults and cfg.
+func Load(cfg Config) (*Policy, error) {
+	p := New()
+	if cfg.Lengths != "" {
+		lengths, err := ParseLengths(cfg.Lengths)
+		if err != nil {
+			return nil, err
+		}
+		if l, ok := lengths[""]; ok {
+			p.Default = l
+			delete(lengths, "")
+		}
+		p.Lengths = lengths
+	}
+	if cfg.Punctuation != "" {
+		// Only characters that need no escaping in a URL path
+		if strings.Trim(cfg.Punctuation, "-_.~") != "" {
+			return nil, fmt.Errorf("invalid punctuation %q (allowed: -_.~)", cfg.Punctuation)
+		}
+		p.Punctuation = cfg.Punctuation
+	}
+	if cfg.BlockedWords != "" {
+		f, err := os.Open(cfg.BlockedWords)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		terms, err := ParseTerms(f)
+		if err != nil {
+			return nil, err
+		}
+		p.Block(terms...)
+	}
+	return p, nil
+}
+
+// ParseLengths parses per-tier limits such as "default=4-10,pro=3-16". The
+// "default" entry sets the default limit; it is returned with the key "".
+func ParseLengths(s string) (map[string]Length, error) {
+	out := make(map[string]Length)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		tier, rng, ok := strings.Cut(item, "=")
+		lo, hi, ok2 := strings.Cut(rng, "-")
+		if !ok || !ok2 {
+			return nil, fmt.Errorf("invalid tier length %q (want tier=min-max)", item)
+		}
+		min, err1 := strconv.Atoi(strings.TrimSpace(lo))
+		max, err2 := strconv.Atoi(strings.TrimSpace(hi))
+		if err1 != nil || err2 != nil || min < MinLength || max > MaxLength || min > max {
+			return nil, fmt.Errorf("invalid tier length %q (lengths must be within %d-%d)", item, MinLength, MaxLength)
+		}
+		tier = strings.TrimSpace(tier)
+		if tier == "default" {
+			tier = ""
+		}
+		out[tier] = Length{Min: min, Max: max}
+	}
+	return out, nil
+}
+
+// ParseTerms reads one term per line, skipping blank lines and "#" comments.
+func ParseTerms(r io.Reader) ([]string, error) {
+	var terms []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		terms = append(terms, line)
+	}
+	return terms, sc.Err()
+}
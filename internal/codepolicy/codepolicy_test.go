@@ -0,0 +1,130 @@
+package codepolicy
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	p := New()
+	p.Reserve("shorten")
+	p.Block("darn", "acme")
+	p.Lengths = map[string]Length{"pro": {Min: 2, Max: 16}}
+
+	tests := []struct {
+		code, tier string
+		want       error
+	}{
+		{"my-link", "", nil},
+		{"My.Link_2", "", nil},
+		{"abc", "", ErrInvalid},
+		{"abcdefghijk", "", ErrInvalid},
+		{"abcdefghijk", "pro", nil},
+		{"ab", "pro", nil},
+		{"abcdefghijklmnopq", "pro", ErrInvalid},
+		{"abc/def", "", ErrInvalid},
+		{"abc def", "", ErrInvalid},
+		{"héllo", "", ErrInvalid},
+		{"123456", "", ErrInvalid},
+		{"ADMIN", "", ErrReserved},
+		{"shorten", "", ErrReserved},
+		{"admins", "", nil},
+		{"darnit", "", ErrBlocked},
+		{"D4RN-it", "", ErrBlocked},
+		{"d-a-r-n", "", ErrBlocked},
+		{"4cm3shop", "", ErrBlocked},
+		{"darling", "", nil},
+	}
+	for _, tt := range tests {
+		err := p.Check(tt.code, tt.tier)
+		if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+			t.Errorf("Check(%q, %q) = %v, want %v", tt.code, tt.tier, err, tt.want)
+		}
+	}
+}
+
+func TestCheckAmbiguousOne(t *testing.T) {
+	p := New()
+	p.Block("kill")
+	for _, code := range []string{"k1ll", "ki11"} {
+		if err := p.Check(code, ""); !errors.Is(err, ErrBlocked) {
+			t.Errorf("Check(%q) = %v, want ErrBlocked", code, err)
+		}
+	}
+}
+
+func TestFold(t *testing.T) {
+	tests := map[string]string{
+		"Sh1t":    "shit",
+		"$-h-!-t": "shit",
+		"L33T":    "leet",
+		"a_b.c":   "abc",
+		"":        "",
+	}
+	for in, want := range tests {
+		if got := Fold(in); got != want {
+			t.Errorf("Fold(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestReservedFromRoutes(t *testing.T) {
+	got := ReservedFromRoutes([]string{
+		"/shorten",
+		"/api/links",
+		"/api/links/{shortCode}/stats",
+		"/{shortCode}+",
+		"/{shortCode}",
+		"/Health",
+		"/",
+	})
+	want := []string{"shorten", "api", "health"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReservedFromRoutes = %v, want %v", got, want)
+	}
+}
+
+func TestParseLengths(t *testing.T) {
+	got, err := ParseLengths("default=5-10, pro=3-16")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Length{"": {5, 10}, "pro": {3, 16}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseLengths = %v, want %v", got, want)
+	}
+
+	for _, bad := range []string{"pro", "pro=3", "pro=a-b", "pro=0-10", "pro=5-40", "pro=9-4"} {
+		if _, err := ParseLengths(bad); err == nil {
+			t.Errorf("ParseLengths(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestParseTerms(t *testing.T) {
+	got, err := ParseTerms(strings.NewReader("# trademarks\nacme\n\n  Globex  \n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"acme", "Globex"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseTerms = %v, want %v", got, want)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	p, err := Load(Config{Lengths: "default=5-12,pro=3-20", Punctuation: "-"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Default != (Length{5, 12}) || p.LengthFor("pro") != (Length{3, 20}) || p.LengthFor("free") != (Length{5, 12}) {
+		t.Errorf("lengths = %v %v", p.Default, p.Lengths)
+	}
+	if err := p.Check("my.link", ""); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Check with disallowed punctuation = %v, want ErrInvalid", err)
+	}
+	if _, err := Load(Config{Punctuation: "/"}); err == nil {
+		t.Error("Load accepted \"/\" as punctuation")
+	}
+}
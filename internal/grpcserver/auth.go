@@ -0,0 +1,80 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	shortenerv1 "github.com/Siddarth2230/url-shortener/api/proto/shortener/v1"
+	"github.com/Siddarth2230/url-shortener/internal/models"
+	"github.com/Siddarth2230/url-shortener/internal/service"
+)
+
+// keyRequired lists the methods that can't be called without an API key.
+// Get and Stats reveal destinations, notes and owners, so like Update and
+// Delete they only work on the key's own links.
+var keyRequired = map[string]bool{
+	shortenerv1.Shortener_Get_FullMethodName:    true,
+	shortenerv1.Shortener_Stats_FullMethodName:  true,
+	shortenerv1.Shortener_Update_FullMethodName: true,
+	shortenerv1.Shortener_Delete_FullMethodName: true,
+}
+
+type apiKeyCtxKey struct{}
+
+// apiKeyFromContext returns the key set by authenticate, or nil.
+func apiKeyFromContext(ctx context.Context) *models.APIKey {
+	key, _ := ctx.Value(apiKeyCtxKey{}).(*models.APIKey)
+	return key
+}
+
+// authenticate checks the API key sent as "authorization: Bearer <key>" or
+// "x-api-key: <key>" metadata and puts it into the context. A key that is
+// sent must be valid; methods in keyRequired need one. Health checks and
+// reflection are never authenticated.
+func (s *Server) authenticate(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if !strings.HasPrefix(info.FullMethod, "/"+shortenerv1.Shortener_ServiceDesc.ServiceName+"/") {
+		return handler(ctx, req)
+	}
+
+	key, err := s.apiKey(ctx)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAPIKey) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		s.log(ctx).Error("API key lookup failed", "method", info.FullMethod, "err", err)
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+	if key == nil && keyRequired[info.FullMethod] {
+		return nil, status.Error(codes.Unauthenticated, "a valid API key is required")
+	}
+	if key != nil {
+		ctx = context.WithValue(ctx, apiKeyCtxKey{}, key)
+	}
+	return handler(ctx, req)
+}
+
+// apiKey authenticates the call's API key. It returns nil without an error
+// when no key was sent.
+func (s *Server) apiKey(ctx context.Context) (*models.APIKey, error) {
+	var raw string
+	if v := metadata.ValueFromIncomingContext(ctx, "x-api-key"); len(v) > 0 {
+		raw = v[0]
+	}
+	if v := metadata.ValueFromIncomingContext(ctx, "authorization"); raw == "" && len(v) > 0 {
+		token, ok := strings.CutPrefix(v[0], "Bearer ")
+		if !ok {
+			return nil, service.ErrInvalidAPIKey
+		}
+		raw = strings.TrimSpace(token)
+	}
+	if raw == "" {
+		return nil, nil
+	}
+	return s.service.AuthenticateAPIKey(ctx, raw)
+}
@@ -0,0 +1,126 @@
+package grpcserver
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	shortenerv1 "github.com/Siddarth2230/url-shortener/api/proto/shortener/v1"
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+func shortenRequestFromProto(req *shortenerv1.ShortenRequest) models.ShortenRequest {
+	r := models.ShortenRequest{
+		URL:          req.GetUrl(),
+		Domain:       req.GetDomain(),
+		CustomCode:   req.GetCustomCode(),
+		Password:     req.GetPassword(),
+		Interstitial: req.GetInterstitial(),
+		QueryPolicy:  req.GetQueryPolicy(),
+		Title:        req.GetTitle(),
+		Notes:        req.GetNotes(),
+		Tags:         req.GetTags(),
+	}
+	if req.MaxClicks != nil {
+		n := int(req.GetMaxClicks())
+		r.MaxClicks = &n
+	}
+	if utm := req.GetUtm(); utm != nil {
+		r.UTM = &models.UTM{
+			Source:   utm.GetSource(),
+			Medium:   utm.GetMedium(),
+			Campaign: utm.GetCampaign(),
+			Term:     utm.GetTerm(),
+			Content:  utm.GetContent(),
+		}
+	}
+	return r
+}
+
+func shortenResponseToProto(resp *models.ShortenResponse) *shortenerv1.ShortenResponse {
+	return &shortenerv1.ShortenResponse{
+		Domain:    resp.Domain,
+		ShortCode: resp.ShortCode,
+		ShortUrl:  resp.ShortURL,
+		LongUrl:   resp.LongURL,
+		Protected: resp.Protected,
+		MaxClicks: int32Ptr(resp.MaxClicks),
+		Existing:  resp.Existing,
+	}
+}
+
+// linkToProto converts a stored link. The password hash never leaves the server.
+func (s *Server) linkToProto(u *models.URL) *shortenerv1.Link {
+	l := &shortenerv1.Link{
+		Domain:          u.Domain,
+		ShortCode:       u.ShortCode,
+		ShortUrl:        s.service.ShortURL(u.Domain, u.ShortCode),
+		LongUrl:         u.LongURL,
+		CreatedAt:       timestamp(&u.CreatedAt),
+		ExpiresAt:       timestamp(u.ExpiresAt),
+		Owner:           u.Owner,
+		Title:           u.Title,
+		Notes:           u.Notes,
+		Tags:            u.Tags,
+		Protected:       u.IsProtected(),
+		MaxClicks:       int32Ptr(u.MaxClicks),
+		ClicksRemaining: int32Ptr(u.ClicksRemaining),
+		Status:          u.Status,
+		StatusReason:    u.StatusReason,
+		Interstitial:    u.Interstitial,
+		QueryPolicy:     u.QueryPolicy,
+	}
+	if l.Status == "" {
+		l.Status = models.StatusActive
+	}
+	if u.UTM != nil {
+		l.Utm = &shortenerv1.UTM{
+			Source:   u.UTM.Source,
+			Medium:   u.UTM.Medium,
+			Campaign: u.UTM.Campaign,
+			Term:     u.UTM.Term,
+			Content:  u.UTM.Content,
+		}
+	}
+	return l
+}
+
+func statsToProto(stats *models.ClickStats) *shortenerv1.StatsResponse {
+	resp := &shortenerv1.StatsResponse{
+		ShortCode:      stats.ShortCode,
+		TotalClicks:    stats.TotalClicks,
+		UniqueVisitors: stats.UniqueVisitors,
+		FirstClickAt:   timestamp(stats.FirstClickAt),
+		LastClickAt:    timestamp(stats.LastClickAt),
+	}
+	for _, d := range stats.Daily {
+		resp.Daily = append(resp.Daily, &shortenerv1.DailyClicks{Day: timestamppb.New(d.Day), Clicks: d.Clicks})
+	}
+	for _, v := range stats.Variants {
+		resp.Variants = append(resp.Variants, &shortenerv1.VariantStats{
+			VariantId:      v.VariantID,
+			Name:           v.Name,
+			Destination:    v.Destination,
+			Weight:         int32(v.Weight),
+			Clicks:         v.Clicks,
+			UniqueVisitors: v.UniqueVisitors,
+			Share:          v.Share,
+		})
+	}
+	return resp
+}
+
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func int32Ptr(n *int) *int32 {
+	if n == nil {
+		return nil
+	}
+	v := int32(*n)
+	return &v
+}
@@ -0,0 +1,69 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	"github.com/Siddarth2230/url-shortener/internal/service"
+)
+
+// errorDomain identifies this API in ErrorInfo details.
+const errorDomain = "url-shortener"
+
+// errNotOwner hides links of other API keys from Update and Delete.
+var errNotOwner = &service.Error{Kind: service.KindForbidden, Code: "not_owner", Message: "link is not owned by this API key"}
+
+// codeByKind maps service errors to status codes, like the HTTP handlers'
+// statusByKind.
+var codeByKind = map[service.Kind]codes.Code{
+	service.KindInvalid:         codes.InvalidArgument,
+	service.KindUnauthenticated: codes.Unauthenticated,
+	service.KindForbidden:       codes.PermissionDenied,
+	service.KindNotFound:        codes.NotFound,
+	service.KindConflict:        codes.AlreadyExists,
+	service.KindGone:            codes.FailedPrecondition,
+	service.KindRejected:        codes.FailedPrecondition,
+	service.KindRateLimited:     codes.ResourceExhausted,
+	service.KindLegal:           codes.FailedPrecondition,
+	service.KindUnavailable:     codes.Unavailable,
+}
+
+// toStatus turns a service error into a status error. Its ErrorInfo detail
+// carries the error code as reason and the field, if any, as metadata;
+// invalid fields are also reported in a BadRequest detail. Unknown errors
+// are logged and answered with a generic Internal status.
+func (s *Server) toStatus(ctx context.Context, op string, err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	var se *service.Error
+	code, ok := codes.Internal, false
+	if errors.As(err, &se) {
+		code, ok = codeByKind[se.Kind]
+	}
+	if !ok {
+		s.log(ctx).Error("request failed", "op", op, "err", err)
+		return status.Error(codes.Internal, "internal server error")
+	}
+
+	info := &errdetails.ErrorInfo{Reason: se.Code, Domain: errorDomain}
+	details := []protoadapt.MessageV1{info}
+	if se.Field != "" {
+		info.Metadata = map[string]string{"field": se.Field}
+		if se.Kind == service.KindInvalid {
+			details = append(details, &errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: se.Field, Description: err.Error()},
+			}})
+		}
+	}
+	st := status.New(code, err.Error())
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
@@ -0,0 +1,277 @@
+// Package grpcserver serves the shortener.v1.Shortener gRPC API on top of the
+// same URLService as the HTTP handlers, with health checking and reflection.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/url"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	shortenerv1 "github.com/Siddarth2230/url-shortener/api/proto/shortener/v1"
+	"github.com/Siddarth2230/url-shortener/internal/middleware"
+	"github.com/Siddarth2230/url-shortener/internal/models"
+	"github.com/Siddarth2230/url-shortener/internal/service"
+	"github.com/Siddarth2230/url-shortener/pkg/logging"
+)
+
+// maxBatch bounds BatchShorten requests.
+const maxBatch = 100
+
+// Server implements shortenerv1.ShortenerServer.
+type Server struct {
+	shortenerv1.UnimplementedShortenerServer
+
+	service *service.URLService
+	logger  *slog.Logger
+}
+
+func New(svc *service.URLService, logger *slog.Logger) *Server {
+	return &Server{service: svc, logger: logger}
+}
+
+// NewGRPCServer returns a gRPC server with the Shortener service, the standard
+// health service (reporting SERVING) and reflection registered. Calls pass
+// through the request ID, access log, tracing, metrics and API key
+// interceptors, in that order.
+func NewGRPCServer(svc *service.URLService, logger *slog.Logger, opts ...grpc.ServerOption) (*grpc.Server, *health.Server) {
+	s := New(svc, logger)
+	opts = append(opts, grpc.ChainUnaryInterceptor(
+		middleware.UnaryRequestID(logger),
+		middleware.UnaryAccessLog,
+		middleware.UnaryTracing,
+		middleware.UnaryMetrics,
+		s.authenticate,
+	))
+	gs := grpc.NewServer(opts...)
+	shortenerv1.RegisterShortenerServer(gs, s)
+
+	hs := health.NewServer()
+	hs.SetServingStatus(shortenerv1.Shortener_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(gs, hs)
+	reflection.Register(gs)
+	return gs, hs
+}
+
+// log returns the request logger carried by ctx, or the server's.
+func (s *Server) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, s.logger)
+}
+
+func (s *Server) Shorten(ctx context.Context, req *shortenerv1.ShortenRequest) (*shortenerv1.ShortenResponse, error) {
+	resp, err := s.shorten(ctx, req)
+	if err != nil {
+		return nil, s.toStatus(ctx, "Shorten", err)
+	}
+	return resp, nil
+}
+
+func (s *Server) BatchShorten(ctx context.Context, req *shortenerv1.BatchShortenRequest) (*shortenerv1.BatchShortenResponse, error) {
+	if len(req.GetRequests()) == 0 || len(req.GetRequests()) > maxBatch {
+		return nil, status.Errorf(codes.InvalidArgument, "a batch holds 1 to %d requests", maxBatch)
+	}
+	results := make([]*shortenerv1.BatchShortenResult, len(req.GetRequests()))
+	for i, r := range req.GetRequests() {
+		resp, err := s.shorten(ctx, r)
+		if err != nil {
+			st := status.Convert(s.toStatus(ctx, "BatchShorten", err))
+			e := &shortenerv1.Error{Code: st.Code().String(), Message: st.Message()}
+			var se *service.Error
+			if errors.As(err, &se) && st.Code() != codes.Internal {
+				e.Reason, e.Field = se.Code, se.Field
+			}
+			results[i] = &shortenerv1.BatchShortenResult{Result: &shortenerv1.BatchShortenResult_Error{Error: e}}
+			continue
+		}
+		results[i] = &shortenerv1.BatchShortenResult{Result: &shortenerv1.BatchShortenResult_Link{Link: resp}}
+	}
+	return &shortenerv1.BatchShortenResponse{Results: results}, nil
+}
+
+// shorten creates one link, owned by the caller's API key if there is one.
+func (s *Server) shorten(ctx context.Context, req *shortenerv1.ShortenRequest) (*shortenerv1.ShortenResponse, error) {
+	r := shortenRequestFromProto(req)
+	if key := apiKeyFromContext(ctx); key != nil {
+		r.Owner = key.Name
+		r.Tier = key.Tier
+	}
+	resp, err := s.service.ShortenURL(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return shortenResponseToProto(resp), nil
+}
+
+func (s *Server) Resolve(ctx context.Context, req *shortenerv1.ResolveRequest) (*shortenerv1.ResolveResponse, error) {
+	query, err := url.ParseQuery(req.GetQuery())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid query")
+	}
+	domain, err := s.service.ResolveDomain(req.GetDomain())
+	if err != nil {
+		return nil, s.toStatus(ctx, "Resolve", err)
+	}
+	u, err := s.service.ResolveURL(ctx, domain, req.GetShortCode())
+	if err == nil && u.IsProtected() {
+		err = service.ErrPasswordRequired
+	}
+	if err == nil {
+		err = s.service.ConsumeClick(ctx, u)
+	}
+	if err != nil {
+		return nil, s.toStatus(ctx, "Resolve", err)
+	}
+
+	v := visitorFromContext(ctx)
+	v.Query = query
+	v.VariantID = req.GetVariantId()
+	dest := s.service.Destination(u, v)
+
+	s.recordClick(ctx, u, v, dest.VariantID)
+	return &shortenerv1.ResolveResponse{
+		LongUrl:      dest.URL,
+		Quarantined:  u.IsQuarantined(),
+		Interstitial: u.Interstitial,
+		VariantId:    derefID(dest.VariantID),
+	}, nil
+}
+
+// visitorFromContext describes the caller from its metadata and peer address.
+func visitorFromContext(ctx context.Context) service.Visitor {
+	var v service.Visitor
+	if ua := metadata.ValueFromIncomingContext(ctx, "user-agent"); len(ua) > 0 {
+		v.UserAgent = ua[0]
+	}
+	if al := metadata.ValueFromIncomingContext(ctx, "accept-language"); len(al) > 0 {
+		v.AcceptLanguage = al[0]
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil && net.ParseIP(host) != nil {
+			v.IP = host
+		}
+	}
+	return v
+}
+
+func derefID(id *int64) int64 {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
+
+// recordClick stores the click asynchronously, like the HTTP redirect does.
+func (s *Server) recordClick(ctx context.Context, u *models.URL, v service.Visitor, variantID *int64) {
+	click := &models.Click{
+		URLID:     u.ID,
+		ShortCode: u.ShortCode,
+		VariantID: variantID,
+		ClickedAt: time.Now().UTC(),
+		IPAddress: v.IP,
+		UserAgent: v.UserAgent,
+	}
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		if err := s.service.RecordClick(ctx, click); err != nil {
+			s.log(ctx).Error("record click failed", "code", u.ShortCode, "err", err)
+		}
+	}()
+}
+
+func (s *Server) Get(ctx context.Context, req *shortenerv1.GetRequest) (*shortenerv1.GetResponse, error) {
+	u, err := s.ownedURL(ctx, req.GetDomain(), req.GetShortCode())
+	if err != nil {
+		return nil, s.toStatus(ctx, "Get", err)
+	}
+	return &shortenerv1.GetResponse{Link: s.linkToProto(u)}, nil
+}
+
+// Update applies the long URL, then the expiry. A failure leaves the earlier
+// change in place.
+func (s *Server) Update(ctx context.Context, req *shortenerv1.UpdateRequest) (*shortenerv1.UpdateResponse, error) {
+	if req.LongUrl == nil && req.GetExpiresAt() == nil && !req.GetClearExpiry() {
+		return nil, status.Error(codes.InvalidArgument, "nothing to update")
+	}
+	if req.GetExpiresAt() != nil && req.GetClearExpiry() {
+		return nil, status.Error(codes.InvalidArgument, "expires_at and clear_expiry are mutually exclusive")
+	}
+
+	u, err := s.ownedURL(ctx, req.GetDomain(), req.GetShortCode())
+	if err != nil {
+		return nil, s.toStatus(ctx, "Update", err)
+	}
+	if req.LongUrl != nil {
+		if u, err = s.service.UpdateLongURL(ctx, u.Domain, u.ShortCode, req.GetLongUrl()); err != nil {
+			return nil, s.toStatus(ctx, "Update", err)
+		}
+	}
+	if req.GetExpiresAt() != nil || req.GetClearExpiry() {
+		var expiresAt *time.Time
+		if req.GetExpiresAt() != nil {
+			t := req.GetExpiresAt().AsTime()
+			expiresAt = &t
+		}
+		if u, err = s.service.ExpireShortCode(ctx, u.Domain, u.ShortCode, expiresAt); err != nil {
+			return nil, s.toStatus(ctx, "Update", err)
+		}
+	}
+	return &shortenerv1.UpdateResponse{Link: s.linkToProto(u)}, nil
+}
+
+func (s *Server) Delete(ctx context.Context, req *shortenerv1.DeleteRequest) (*shortenerv1.DeleteResponse, error) {
+	u, err := s.ownedURL(ctx, req.GetDomain(), req.GetShortCode())
+	if err == nil {
+		err = s.service.DeleteShortCode(ctx, u.Domain, u.ShortCode)
+	}
+	if err != nil {
+		return nil, s.toStatus(ctx, "Delete", err)
+	}
+	return &shortenerv1.DeleteResponse{}, nil
+}
+
+func (s *Server) Stats(ctx context.Context, req *shortenerv1.StatsRequest) (*shortenerv1.StatsResponse, error) {
+	if req.GetDays() < 0 || req.GetDays() > 365 {
+		return nil, status.Error(codes.InvalidArgument, "days must be between 1 and 365, or 0 for the default")
+	}
+	u, err := s.ownedURL(ctx, req.GetDomain(), req.GetShortCode())
+	if err != nil {
+		return nil, s.toStatus(ctx, "Stats", err)
+	}
+	stats, err := s.service.GetClickStats(ctx, u.Domain, u.ShortCode, int(req.GetDays()))
+	if err != nil {
+		return nil, s.toStatus(ctx, "Stats", err)
+	}
+	return statsToProto(stats), nil
+}
+
+func (s *Server) getURL(ctx context.Context, domain, shortCode string) (*models.URL, error) {
+	domain, err := s.service.ResolveDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	return s.service.GetURL(ctx, domain, shortCode)
+}
+
+// ownedURL returns a link the caller's API key may read and change. The
+// authenticate interceptor has made sure there is a key.
+func (s *Server) ownedURL(ctx context.Context, domain, shortCode string) (*models.URL, error) {
+	u, err := s.getURL(ctx, domain, shortCode)
+	if err != nil {
+		return nil, err
+	}
+	if key := apiKeyFromContext(ctx); key == nil || u.Owner != key.Name {
+		return nil, errNotOwner
+	}
+	return u, nil
+}
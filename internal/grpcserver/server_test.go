@@ -0,0 +1,410 @@
+package grpcserver_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	shortenerv1 "github.com/Siddarth2230/url-shortener/api/proto/shortener/v1"
+	"github.com/Siddarth2230/url-shortener/internal/grpcserver"
+	"github.com/Siddarth2230/url-shortener/internal/repository"
+	"github.com/Siddarth2230/url-shortener/internal/service"
+)
+
+// urlRow has the columns FindByShortCode and FindAnyByShortCode select.
+var urlRow = []string{"id", "domain", "short_code", "long_url", "created_at", "expires_at", "owner", "password_hash",
+	"max_clicks", "clicks_remaining", "rules", "status", "status_reason", "interstitial", "title", "notes",
+	"query_policy", "utm", "variants"}
+
+// newClient serves the gRPC server over an in-memory connection, with the real
+// service and repository and sqlmock in place of PostgreSQL.
+func newClient(t *testing.T) (*grpc.ClientConn, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.MatchExpectationsInOrder(false)
+	t.Cleanup(func() { db.Close() })
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repo := repository.NewURLRepository(db, logger)
+	svc := service.NewURLService(repo, nil, "https://sho.rt", 100, logger)
+
+	lis := bufconn.Listen(1 << 20)
+	gs, _ := grpcserver.NewGRPCServer(svc, logger)
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, mock
+}
+
+func withAPIKey(ctx context.Context, key string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+key)
+}
+
+func expectAPIKey(mock sqlmock.Sqlmock, name string) {
+	mock.ExpectQuery(regexp.QuoteMeta("FROM api_keys")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "tier", "created_at"}).AddRow(1, name, "", time.Now()))
+}
+
+func expectLink(mock sqlmock.Sqlmock, code, owner string) {
+	mock.ExpectQuery(regexp.QuoteMeta("FROM urls")).WithArgs("", code).WillReturnRows(sqlmock.NewRows(urlRow).
+		AddRow(3, "", code, "https://example.com/docs", time.Now(), nil, owner, nil, nil, nil, nil, "active", nil, false, nil, nil, "ignore", nil, []byte("[]")))
+}
+
+func wantCode(t *testing.T, name string, err error, want codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != want {
+		t.Errorf("%s: code = %v (%v), want %v", name, got, err, want)
+	}
+}
+
+func TestShortenOwnedByAPIKey(t *testing.T) {
+	conn, mock := newClient(t)
+	c := shortenerv1.NewShortenerClient(conn)
+
+	expectAPIKey(mock, "team")
+	mock.ExpectQuery(regexp.QuoteMeta("FROM reserved_codes")).WillReturnRows(sqlmock.NewRows([]string{"word", "kind"}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS")).WithArgs("", "launch").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	// Owned links are stored with their link.created event
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO urls")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(7, "active"))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO webhook_outbox")).WithArgs("team", sqlmock.AnyArg(), "link.created", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	var header metadata.MD
+	resp, err := c.Shorten(withAPIKey(context.Background(), "secret"),
+		&shortenerv1.ShortenRequest{Url: "https://example.com/launch", CustomCode: "launch"}, grpc.Header(&header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetShortCode() != "launch" || resp.GetShortUrl() != "https://sho.rt/launch" {
+		t.Errorf("Shorten = %v", resp)
+	}
+	if len(header.Get("x-request-id")) != 1 {
+		t.Errorf("response header lacks x-request-id: %v", header)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// wantReason checks the ErrorInfo detail, and for a field the BadRequest
+// detail of invalid arguments.
+func wantReason(t *testing.T, name string, err error, reason, field string) {
+	t.Helper()
+	var info *errdetails.ErrorInfo
+	var violation string
+	for _, d := range status.Convert(err).Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			info = d
+		case *errdetails.BadRequest:
+			violation = d.GetFieldViolations()[0].GetField()
+		}
+	}
+	if info.GetReason() != reason || info.GetDomain() != "url-shortener" || info.GetMetadata()["field"] != field {
+		t.Errorf("%s: ErrorInfo = %v, want reason %s, field %q", name, info, reason, field)
+	}
+	if status.Code(err) == codes.InvalidArgument && violation != field {
+		t.Errorf("%s: BadRequest field = %q, want %q", name, violation, field)
+	}
+}
+
+func TestBatchShorten(t *testing.T) {
+	conn, mock := newClient(t)
+	c := shortenerv1.NewShortenerClient(conn)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM reserved_codes")).WillReturnRows(sqlmock.NewRows([]string{"word", "kind"}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS")).WithArgs("", "batch1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO urls")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(8, "active"))
+
+	resp, err := c.BatchShorten(context.Background(), &shortenerv1.BatchShortenRequest{Requests: []*shortenerv1.ShortenRequest{
+		{Url: "not a url"},
+		{Url: "https://example.com/one", CustomCode: "batch1"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.GetResults()) != 2 {
+		t.Fatalf("results = %v", resp.GetResults())
+	}
+	if e := resp.GetResults()[0].GetError(); e.GetCode() != codes.InvalidArgument.String() || e.GetMessage() == "" {
+		t.Errorf("results[0] = %v, want an InvalidArgument error", resp.GetResults()[0])
+	}
+	if e := resp.GetResults()[0].GetError(); e.GetReason() != "invalid_url" || e.GetField() != "url" {
+		t.Errorf("results[0] = %v, want reason invalid_url on url", resp.GetResults()[0])
+	}
+	if l := resp.GetResults()[1].GetLink(); l.GetShortCode() != "batch1" {
+		t.Errorf("results[1] = %v, want link batch1", resp.GetResults()[1])
+	}
+
+	_, err = c.BatchShorten(context.Background(), &shortenerv1.BatchShortenRequest{})
+	wantCode(t, "empty batch", err, codes.InvalidArgument)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	conn, mock := newClient(t)
+	c := shortenerv1.NewShortenerClient(conn)
+
+	expectLink(mock, "docs", "")
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO clicks")).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	resp, err := c.Resolve(context.Background(), &shortenerv1.ResolveRequest{ShortCode: "docs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetLongUrl() != "https://example.com/docs" {
+		t.Errorf("Resolve = %v", resp)
+	}
+
+	// The click is recorded after the answer
+	deadline := time.Now().Add(2 * time.Second)
+	for mock.ExpectationsWereMet() != nil && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// Resolve picks destinations like a redirect: split variants stay sticky, UTM
+// defaults and passthrough apply, and the click is attributed to the variant.
+func TestResolveSplitLink(t *testing.T) {
+	conn, mock := newClient(t)
+	c := shortenerv1.NewShortenerClient(conn)
+
+	variants := []byte(`[{"id":11,"name":"a","destination":"https://example.com/a","weight":1},{"id":12,"name":"b","destination":"https://example.com/b","weight":1}]`)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM urls")).WithArgs("", "split").WillReturnRows(sqlmock.NewRows(urlRow).
+		AddRow(3, "", "split", "https://example.com", time.Now(), nil, nil, nil, nil, nil, nil, "active", nil, false, nil, nil, "merge", []byte(`{"source":"grpc"}`), variants))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO clicks")).
+		WithArgs(int64(3), "split", int64(12), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx := context.Background()
+	resp, err := c.Resolve(ctx, &shortenerv1.ResolveRequest{ShortCode: "split", Query: "ref=news", VariantId: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dest, err := url.Parse(resp.GetLongUrl())
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := dest.Query()
+	if dest.Path != "/b" || q.Get("utm_source") != "grpc" || q.Get("ref") != "news" || resp.GetVariantId() != 12 {
+		t.Errorf("Resolve = %v", resp)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for mock.ExpectationsWereMet() != nil && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	_, err = c.Resolve(ctx, &shortenerv1.ResolveRequest{ShortCode: "split", Query: "%zz"})
+	wantCode(t, "bad query", err, codes.InvalidArgument)
+}
+
+// Get and Stats reveal destinations and notes, so they need the owning key.
+func TestGetAndStatsRequireOwner(t *testing.T) {
+	conn, mock := newClient(t)
+	c := shortenerv1.NewShortenerClient(conn)
+	ctx := context.Background()
+	team := withAPIKey(ctx, "secret")
+
+	_, err := c.Get(ctx, &shortenerv1.GetRequest{ShortCode: "docs"})
+	wantCode(t, "Get without a key", err, codes.Unauthenticated)
+	_, err = c.Stats(ctx, &shortenerv1.StatsRequest{ShortCode: "docs"})
+	wantCode(t, "Stats without a key", err, codes.Unauthenticated)
+
+	expectAPIKey(mock, "team")
+	expectLink(mock, "docs", "team")
+	resp, err := c.Get(team, &shortenerv1.GetRequest{ShortCode: "docs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := resp.GetLink(); l.GetOwner() != "team" || l.GetShortUrl() != "https://sho.rt/docs" || l.GetStatus() != "active" {
+		t.Errorf("Get = %v", l)
+	}
+
+	expectAPIKey(mock, "other")
+	expectLink(mock, "docs", "team")
+	_, err = c.Get(withAPIKey(ctx, "other"), &shortenerv1.GetRequest{ShortCode: "docs"})
+	wantCode(t, "Get of another key's link", err, codes.PermissionDenied)
+	wantReason(t, "Get of another key's link", err, "not_owner", "")
+
+	expectAPIKey(mock, "other")
+	expectLink(mock, "docs", "team")
+	_, err = c.Stats(withAPIKey(ctx, "other"), &shortenerv1.StatsRequest{ShortCode: "docs"})
+	wantCode(t, "Stats of another key's link", err, codes.PermissionDenied)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetAndStatsNotFound(t *testing.T) {
+	conn, mock := newClient(t)
+	c := shortenerv1.NewShortenerClient(conn)
+	ctx := withAPIKey(context.Background(), "secret")
+
+	expectAPIKey(mock, "team")
+	mock.ExpectQuery(regexp.QuoteMeta("FROM urls")).WithArgs("", "missing").WillReturnRows(sqlmock.NewRows(urlRow))
+	_, err := c.Get(ctx, &shortenerv1.GetRequest{ShortCode: "missing"})
+	wantCode(t, "Get missing", err, codes.NotFound)
+	wantReason(t, "Get missing", err, "not_found", "")
+
+	expectAPIKey(mock, "team")
+	mock.ExpectQuery(regexp.QuoteMeta("FROM urls")).WithArgs("", "missing").WillReturnRows(sqlmock.NewRows(urlRow))
+	_, err = c.Stats(ctx, &shortenerv1.StatsRequest{ShortCode: "missing"})
+	wantCode(t, "Stats missing", err, codes.NotFound)
+
+	expectAPIKey(mock, "team")
+	_, err = c.Stats(ctx, &shortenerv1.StatsRequest{ShortCode: "docs", Days: 400})
+	wantCode(t, "Stats 400 days", err, codes.InvalidArgument)
+
+	expectAPIKey(mock, "team")
+	_, err = c.Get(ctx, &shortenerv1.GetRequest{Domain: "unknown.example", ShortCode: "docs"})
+	wantCode(t, "Get on an unknown domain", err, codes.InvalidArgument)
+	wantReason(t, "Get on an unknown domain", err, "unknown_domain", "domain")
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpdateAndDeleteRequireOwner(t *testing.T) {
+	conn, mock := newClient(t)
+	c := shortenerv1.NewShortenerClient(conn)
+	ctx := context.Background()
+	dest := "https://example.com/new"
+
+	_, err := c.Update(ctx, &shortenerv1.UpdateRequest{ShortCode: "docs", LongUrl: &dest})
+	wantCode(t, "Update without a key", err, codes.Unauthenticated)
+	_, err = c.Delete(ctx, &shortenerv1.DeleteRequest{ShortCode: "docs"})
+	wantCode(t, "Delete without a key", err, codes.Unauthenticated)
+
+	bad := metadata.AppendToOutgoingContext(ctx, "authorization", "Basic dXNlcg==")
+	_, err = c.Shorten(bad, &shortenerv1.ShortenRequest{Url: "https://example.com"})
+	wantCode(t, "Shorten with a malformed key", err, codes.Unauthenticated)
+
+	expectAPIKey(mock, "other")
+	_, err = c.Update(withAPIKey(ctx, "secret"), &shortenerv1.UpdateRequest{ShortCode: "docs"})
+	wantCode(t, "Update without changes", err, codes.InvalidArgument)
+
+	expectAPIKey(mock, "other")
+	expectLink(mock, "docs", "team")
+	_, err = c.Delete(withAPIKey(ctx, "secret"), &shortenerv1.DeleteRequest{ShortCode: "docs"})
+	wantCode(t, "Delete of another key's link", err, codes.PermissionDenied)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// Owners can't change the status, so they can't lift a quarantine or takedown.
+// Clients built against the old proto still send status as field 6.
+func TestUpdateCantChangeStatus(t *testing.T) {
+	conn, mock := newClient(t)
+	c := shortenerv1.NewShortenerClient(conn)
+
+	req := &shortenerv1.UpdateRequest{ShortCode: "docs"}
+	old := protowire.AppendTag(nil, 6, protowire.BytesType)
+	old = protowire.AppendString(old, "active")
+	req.ProtoReflect().SetUnknown(old)
+
+	expectAPIKey(mock, "team")
+	_, err := c.Update(withAPIKey(context.Background(), "secret"), req)
+	wantCode(t, "Update of the status", err, codes.InvalidArgument)
+	// Nothing was looked up or written
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// A link deleted between the ownership check and the delete is NotFound, not
+// Internal.
+func TestDeleteVanishedLink(t *testing.T) {
+	conn, mock := newClient(t)
+	c := shortenerv1.NewShortenerClient(conn)
+
+	expectAPIKey(mock, "team")
+	expectLink(mock, "docs", "team")
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("DELETE FROM urls")).WithArgs("", "docs").WillReturnRows(sqlmock.NewRows(urlRow[:18]))
+	mock.ExpectRollback()
+
+	_, err := c.Delete(withAPIKey(context.Background(), "secret"), &shortenerv1.DeleteRequest{ShortCode: "docs"})
+	wantCode(t, "Delete of a vanished link", err, codes.NotFound)
+	wantReason(t, "Delete of a vanished link", err, "not_found", "")
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHealthAndReflection(t *testing.T) {
+	conn, _ := newClient(t)
+	ctx := context.Background()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: "shortener.v1.Shortener"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("health = %v", resp.GetStatus())
+	}
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	r, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, s := range r.GetListServicesResponse().GetService() {
+		found = found || s.GetName() == "shortener.v1.Shortener"
+	}
+	if !found {
+		t.Errorf("reflection lists %v, want shortener.v1.Shortener", r.GetListServicesResponse().GetService())
+	}
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+	"github.com/Siddarth2230/url-shortener/internal/service"
+)
+
+// variantCookieMaxAge keeps a visitor on the same split variant for 30 days.
+const variantCookieMaxAge = 30 * 24 * 60 * 60
+
+// destination asks the service where this visitor goes. The split variant
+// assignment is kept in a cookie; variantID is set when a variant was chosen so
+// the click can be attributed to it.
+func (h *URLHandler) destination(w http.ResponseWriter, r *http.Request, link *models.URL) (dest string, variantID *int64) {
+	v := service.Visitor{
+		IP:             clientIP(r),
+		UserAgent:      r.UserAgent(),
+		AcceptLanguage: r.Header.Get("Accept-Language"),
+		Query:          r.URL.Query(),
+	}
+	name := variantCookieName(link.ShortCode)
+	if c, err := r.Cookie(name); err == nil {
+		v.VariantID, _ = strconv.ParseInt(c.Value, 10, 64)
+	}
+
+	d := h.service.Destination(link, v)
+	if d.Personalized {
+		// The answer depends on who is asking, so shared caches must not reuse it
+		w.Header().Set("Vary", "User-Agent, Accept-Language, Cookie")
+		w.Header().Set("Cache-Control", "private, no-store")
+	}
+	if d.VariantID != nil && *d.VariantID != v.VariantID {
+		http.SetCookie(w, &http.Cookie{
+			Name:     name,
+			Value:    strconv.FormatInt(*d.VariantID, 10),
+			Path:     "/" + link.ShortCode,
+			MaxAge:   variantCookieMaxAge,
+			HttpOnly: true,
+			Secure:   r.TLS != nil,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+	return d.URL, d.VariantID
+}
+
+// confirmURL links back to the short URL with confirm=1 set, keeping the
+// visitor's other query parameters so they can still be forwarded.
+func confirmURL(r *http.Request, shortCode string) string {
+	q := r.URL.Query()
+	q.Set("confirm", "1")
+	return "/" + shortCode + "?" + q.Encode()
+}
+
+func variantCookieName(shortCode string) string {
+	return "sv_" + shortCode
+}
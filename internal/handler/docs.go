@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/Siddarth2230/url-shortener/api"
+)
+
+// GET /api/openapi.json - the OpenAPI document describing this API
+func (h *URLHandler) OpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Write(api.Spec)
+}
+
+// GET /api/docs - API reference page rendered from the OpenAPI document
+func (h *URLHandler) APIDocs(w http.ResponseWriter, r *http.Request) {
+	page, err := loadDocsPage()
+	if err != nil {
+		h.log(r.Context()).Error("load OpenAPI document failed", "err", err)
+		writeInternalError(w)
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	renderHTML(w, http.StatusOK, "docs.html", page)
+}
+
+type docsPage struct {
+	Title, Version, Description string
+	Operations                  []docOperation
+}
+
+type docOperation struct {
+	Method, Path, Summary, Description string
+	Auth                               string // "required", "optional" or ""
+	Params                             []docParam
+	Body                               string // schema name of the request body
+	Responses                          []docResponse
+}
+
+type docParam struct {
+	Name, In, Type, Description string
+	Required                    bool
+}
+
+type docResponse struct {
+	Status, Description string
+}
+
+// loadDocsPage flattens the document into what docs.html shows, once.
+var loadDocsPage = sync.OnceValues(func() (*docsPage, error) {
+	doc, err := api.Load()
+	if err != nil {
+		return nil, err
+	}
+	page := &docsPage{Title: doc.Info.Title, Version: doc.Info.Version, Description: doc.Info.Description}
+
+	for _, path := range doc.Paths.InMatchingOrder() {
+		item := doc.Paths.Value(path)
+		for method, op := range item.Operations() {
+			d := docOperation{Method: method, Path: path, Summary: op.Summary, Description: op.Description}
+			if op.Security != nil {
+				d.Auth = "required"
+				for _, req := range *op.Security {
+					if len(req) == 0 {
+						d.Auth = "optional"
+					}
+				}
+			}
+			for _, p := range append(item.Parameters, op.Parameters...) {
+				if p.Value == nil {
+					continue
+				}
+				d.Params = append(d.Params, docParam{
+					Name: p.Value.Name, In: p.Value.In, Required: p.Value.Required,
+					Type: schemaName(p.Value.Schema), Description: p.Value.Description,
+				})
+			}
+			if op.RequestBody != nil && op.RequestBody.Value != nil {
+				for _, mt := range op.RequestBody.Value.Content {
+					d.Body = schemaName(mt.Schema)
+				}
+			}
+			for status, resp := range op.Responses.Map() {
+				desc := ""
+				if resp.Value != nil && resp.Value.Description != nil {
+					desc = *resp.Value.Description
+				}
+				d.Responses = append(d.Responses, docResponse{Status: status, Description: desc})
+			}
+			sort.Slice(d.Responses, func(i, j int) bool { return d.Responses[i].Status < d.Responses[j].Status })
+			page.Operations = append(page.Operations, d)
+		}
+	}
+	sort.SliceStable(page.Operations, func(i, j int) bool {
+		a, b := page.Operations[i], page.Operations[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Method < b.Method
+	})
+	return page, nil
+})
+
+// schemaName names a schema for the docs: the component it refers to, or its type.
+func schemaName(s *openapi3.SchemaRef) string {
+	if s == nil {
+		return ""
+	}
+	if s.Ref != "" {
+		return s.Ref[strings.LastIndex(s.Ref, "/")+1:]
+	}
+	if s.Value == nil || s.Value.Type == nil {
+		return ""
+	}
+	if s.Value.Type.Is("array") && s.Value.Items != nil {
+		return schemaName(s.Value.Items) + "[]"
+	}
+	return strings.Join(s.Value.Type.Slice(), "|")
+}
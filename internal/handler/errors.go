@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Siddarth2230/url-shortener/internal/service"
+	"github.com/Siddarth2230/url-shortener/pkg/metrics"
+	"github.com/Siddarth2230/url-shortener/pkg/problem"
+)
+
+// statusByKind is the one table that maps errors to HTTP statuses.
+var statusByKind = map[service.Kind]int{
+	service.KindInvalid:         http.StatusBadRequest,
+	service.KindUnauthenticated: http.StatusUnauthorized,
+	service.KindForbidden:       http.StatusForbidden,
+	service.KindNotFound:        http.StatusNotFound,
+	service.KindConflict:        http.StatusConflict,
+	service.KindGone:            http.StatusGone,
+	service.KindRejected:        http.StatusUnprocessableEntity,
+	service.KindRateLimited:     http.StatusTooManyRequests,
+	service.KindLegal:           http.StatusUnavailableForLegalReasons,
+	service.KindUnavailable:     http.StatusServiceUnavailable,
+}
+
+var (
+	errAPIKeyRequired = &service.Error{Kind: service.KindUnauthenticated, Code: "api_key_required", Message: "a valid API key is required"}
+	errNotOwner       = &service.Error{Kind: service.KindForbidden, Code: "not_owner", Message: "link is not owned by this API key"}
+)
+
+// invalidRequest is a malformed request caught by a handler, e.g. an
+// unparseable body or query parameter.
+func invalidRequest(field, msg string) error {
+	return &service.Error{Kind: service.KindInvalid, Code: "invalid_request", Field: field, Message: msg}
+}
+
+// writeError answers err as problem details. A *service.Error, however
+// wrapped, gets the status of its kind, its code and field, and the full
+// message as detail. Anything else is logged, recorded on the request span
+// and answered 500 without details.
+func (h *URLHandler) writeError(w http.ResponseWriter, r *http.Request, op string, err error) {
+	var se *service.Error
+	status, ok := 0, false
+	if errors.As(err, &se) {
+		status, ok = statusByKind[se.Kind]
+	}
+	if !ok {
+		trace.SpanFromContext(r.Context()).RecordError(err)
+		h.log(r.Context()).Error("request failed", "op", op, "err", err)
+		writeInternalError(w)
+		return
+	}
+
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		metrics.LookupFailures.WithLabelValues("not_found").Inc()
+	case errors.Is(err, service.ErrExpired):
+		metrics.LookupFailures.WithLabelValues("expired").Inc()
+	}
+
+	p := problem.New(status, se.Code, err.Error())
+	p.Field = se.Field
+	p.Instance = r.URL.Path
+	problem.Write(w, p)
+}
+
+// writeInternalError answers 500 without revealing the cause.
+func writeInternalError(w http.ResponseWriter) {
+	problem.Write(w, problem.New(http.StatusInternalServerError, "internal_error", "internal server error"))
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/Siddarth2230/url-shortener/internal/service"
+	"github.com/Siddarth2230/url-shortener/pkg/problem"
+)
+
+func writeErrorFor(t *testing.T, err error) (*httptest.ResponseRecorder, problem.Details) {
+	t.Helper()
+	h := NewURLHandler(nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	rec := httptest.NewRecorder()
+	h.writeError(rec, httptest.NewRequest(http.MethodGet, "/api/links/abc/stats", nil), "test", err)
+
+	if ct := rec.Header().Get("Content-Type"); ct != problem.ContentType {
+		t.Errorf("%v: Content-Type = %q", err, ct)
+	}
+	var p problem.Details
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatalf("%v: body %q: %v", err, rec.Body, err)
+	}
+	if p.Status != rec.Code || p.Type != "about:blank" || p.Title != http.StatusText(rec.Code) {
+		t.Errorf("%v: problem %+v doesn't match status %d", err, p, rec.Code)
+	}
+	return rec, p
+}
+
+func TestWriteErrorServiceErrors(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+		field  string
+	}{
+		{service.ErrInvalidURL, 400, "invalid_url", "url"},
+		{service.ErrInvalidCustomCode, 400, "invalid_custom_code", "custom_code"},
+		{service.ErrInvalidReservedWord, 400, "invalid_reserved_word", "word"},
+		{service.ErrUnknownDomain, 400, "unknown_domain", "domain"},
+		{service.ErrInvalidMetadata, 400, "invalid_metadata", ""},
+		{service.ErrInvalidListing, 400, "invalid_listing", ""},
+		{service.ErrInvalidPassword, 400, "invalid_password", "password"},
+		{service.ErrInvalidMaxClicks, 400, "invalid_max_clicks", "max_clicks"},
+		{service.ErrInvalidRules, 400, "invalid_rules", "rules"},
+		{service.ErrInvalidVariants, 400, "invalid_variants", "variants"},
+		{service.ErrInvalidQuery, 400, "invalid_query", ""},
+		{service.ErrInvalidStatus, 400, "invalid_status", "status"},
+		{service.ErrInvalidReport, 400, "invalid_report", "reason"},
+		{service.ErrInvalidKeyName, 400, "invalid_key_name", "name"},
+		{service.ErrInvalidWebhook, 400, "invalid_webhook", ""},
+		{service.ErrInvalidAPIKey, 401, "invalid_api_key", ""},
+		{service.ErrPasswordRequired, 403, "password_required", "password"},
+		{service.ErrWrongPassword, 403, "wrong_password", "password"},
+		{service.ErrNotFound, 404, "not_found", ""},
+		{service.ErrReservedWordMissing, 404, "reserved_word_not_found", "word"},
+		{service.ErrWebhookNotFound, 404, "webhook_not_found", ""},
+		{service.ErrDeliveryNotFound, 404, "delivery_not_found", ""},
+		{service.ErrCustomCodeTaken, 409, "custom_code_taken", "custom_code"},
+		{service.ErrCodeCoolingDown, 409, "code_cooling_down", "custom_code"},
+		{service.ErrExpired, 410, "expired", ""},
+		{service.ErrClicksExhausted, 410, "clicks_exhausted", ""},
+		{service.ErrDisabled, 410, "disabled", ""},
+		{service.ErrUnsafeURL, 422, "unsafe_url", ""},
+		{service.ErrTooManyAttempts, 429, "too_many_attempts", ""},
+		{service.ErrLegalTakedown, 451, "legal_takedown", ""},
+		{service.ErrGenExhausted, 503, "generator_exhausted", ""},
+		{service.ErrScreenUnavailable, 503, "screening_unavailable", ""},
+		{errAPIKeyRequired, 401, "api_key_required", ""},
+		{invalidRequest("days", "days must be between 1 and 365"), 400, "invalid_request", "days"},
+	}
+	for _, tt := range tests {
+		// Wrapped the way the service wraps them
+		err := fmt.Errorf("%w: some detail", tt.err)
+		rec, p := writeErrorFor(t, err)
+		if rec.Code != tt.status || p.Code != tt.code || p.Field != tt.field {
+			t.Errorf("%v: %d %s %q, want %d %s %q", tt.err, rec.Code, p.Code, p.Field, tt.status, tt.code, tt.field)
+		}
+		if p.Detail != err.Error() || p.Instance != "/api/links/abc/stats" {
+			t.Errorf("%v: detail %q, instance %q", tt.err, p.Detail, p.Instance)
+		}
+	}
+}
+
+func TestWriteErrorField(t *testing.T) {
+	err := fmt.Errorf("%w: at most 20 tags", service.ErrInvalidMetadata.WithField("tags"))
+	if !errors.Is(err, service.ErrInvalidMetadata) {
+		t.Fatal("errors.Is doesn't match a copy made by WithField")
+	}
+	rec, p := writeErrorFor(t, err)
+	if rec.Code != http.StatusBadRequest || p.Code != "invalid_metadata" || p.Field != "tags" {
+		t.Errorf("got %d %+v", rec.Code, p)
+	}
+}
+
+func TestWriteErrorInternal(t *testing.T) {
+	rec, p := writeErrorFor(t, errors.New("pq: connection refused"))
+	if rec.Code != http.StatusInternalServerError || p.Code != "internal_error" {
+		t.Errorf("got %d %+v", rec.Code, p)
+	}
+	if strings.Contains(rec.Body.String(), "pq:") {
+		t.Errorf("internal error leaked: %s", rec.Body)
+	}
+}
+
+// A missing link goes through the real service and repository and comes out
+// as a 404 problem.
+func TestWriteErrorEndToEnd(t *testing.T) {
+	r, mock := newRouter(t)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM urls")).WithArgs("", "missing").WillReturnRows(sqlmock.NewRows(urlRow))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/links/missing/stats", nil))
+	var p problem.Details
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusNotFound || rec.Header().Get("Content-Type") != problem.ContentType ||
+		p.Code != "not_found" || p.Instance != "/api/links/missing/stats" {
+		t.Errorf("got %d %+v", rec.Code, p)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// Every kind has a status, so no service error falls through to 500.
+func TestStatusByKindComplete(t *testing.T) {
+	for k := service.KindInvalid; k <= service.KindUnavailable; k++ {
+		if _, ok := statusByKind[k]; !ok {
+			t.Errorf("kind %d has no status", k)
+		}
+	}
+}
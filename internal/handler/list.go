@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+// GET /api/links?q=&tag=&domain=&created_after=&created_before=&expiry=&sort=&cursor=&limit=
+// - list the API key's links, newest first by default. Pass next_cursor back as cursor for the next page.
+func (h *URLHandler) ListLinks(w http.ResponseWriter, r *http.Request) {
+	key := h.requireAPIKey(w, r)
+	if key == nil {
+		return
+	}
+	q := r.URL.Query()
+
+	opts := models.ListOptions{
+		Query:  q.Get("q"),
+		Sort:   q.Get("sort"),
+		Cursor: q.Get("cursor"),
+		Filter: models.URLFilter{
+			Owner:  key.Name,
+			Tag:    q.Get("tag"),
+			Expiry: q.Get("expiry"),
+		},
+	}
+	if q.Has("domain") {
+		domain, err := h.service.ResolveDomain(q.Get("domain"))
+		if err != nil {
+			h.writeError(w, r, "ListLinks", err)
+			return
+		}
+		opts.Filter.Domain = &domain
+	}
+	for name, dst := range map[string]**time.Time{
+		"created_after":  &opts.Filter.CreatedAfter,
+		"created_before": &opts.Filter.CreatedBefore,
+	} {
+		if v := q.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				h.writeError(w, r, "ListLinks", invalidRequest(name, name+" must be an RFC 3339 timestamp"))
+				return
+			}
+			*dst = &t
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			h.writeError(w, r, "ListLinks", invalidRequest("limit", "limit must be a positive integer"))
+			return
+		}
+		opts.Limit = n
+	}
+
+	page, err := h.service.ListURLs(r.Context(), opts)
+	if err != nil {
+		h.writeError(w, r, "ListLinks", err)
+		return
+	}
+	for _, u := range page.Links {
+		redactLink(u)
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// redactLink clears what a listing mustn't reveal: password hashes stay
+// server-side, and protected links hide their destinations like previews do.
+func redactLink(u *models.URL) {
+	if u.IsProtected() {
+		u.LongURL = ""
+		u.Rules = nil
+		for i := range u.Variants {
+			u.Variants[i].Destination = ""
+		}
+	}
+	u.PasswordHash = ""
+}
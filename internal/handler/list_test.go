@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gorilla/mux"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+	"github.com/Siddarth2230/url-shortener/internal/repository"
+	"github.com/Siddarth2230/url-shortener/internal/service"
+)
+
+// newRouter serves the handlers with the real service and repository and
+// sqlmock in place of PostgreSQL.
+func newRouter(t *testing.T) (*mux.Router, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.MatchExpectationsInOrder(false)
+	t.Cleanup(func() { db.Close() })
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := service.NewURLService(repository.NewURLRepository(db, logger), nil, "https://sho.rt", 100, logger)
+	r := mux.NewRouter()
+	NewURLHandler(svc, logger).RegisterRoutes(r)
+	return r, mock
+}
+
+// urlRow has the columns FindByShortCode and FindAnyByShortCode select.
+var urlRow = []string{"id", "domain", "short_code", "long_url", "created_at", "expires_at", "owner", "password_hash",
+	"max_clicks", "clicks_remaining", "rules", "status", "status_reason", "interstitial", "title", "notes",
+	"query_policy", "utm", "variants"}
+
+func expectAPIKey(mock sqlmock.Sqlmock, name string) {
+	mock.ExpectQuery(regexp.QuoteMeta("FROM api_keys")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "tier", "created_at"}).AddRow(1, name, "", time.Now()))
+}
+
+func TestListLinksRequiresAPIKey(t *testing.T) {
+	r, mock := newRouter(t)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/links?owner=team", nil))
+
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+		t.Fatalf("status = %d, want 401 with a challenge", rec.Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestListLinksOwnLinksRedacted(t *testing.T) {
+	r, mock := newRouter(t)
+	expectAPIKey(mock, "team")
+
+	cols := []string{"id", "domain", "short_code", "long_url", "created_at", "expires_at", "owner", "password_hash",
+		"max_clicks", "clicks_remaining", "rules", "status", "status_reason", "interstitial", "title", "notes",
+		"query_policy", "utm", "tags"}
+	rules := []byte(`[{"destination":"https://example.com/mobile","devices":["mobile"]}]`)
+	// The owner filter comes from the key, not from ?owner=
+	mock.ExpectQuery(regexp.QuoteMeta("FROM urls")).WithArgs("team", 51).WillReturnRows(sqlmock.NewRows(cols).
+		AddRow(2, "", "secret", "https://example.com/secret", time.Now(), nil, "team", "$2a$10$hash", nil, nil, rules, "active", nil, false, nil, "private", "ignore", nil, "{}").
+		AddRow(1, "", "public", "https://example.com/public", time.Now(), nil, "team", nil, nil, nil, rules, "active", nil, false, nil, nil, "ignore", nil, "{}"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/links?owner=someone-else", nil)
+	req.Header.Set("Authorization", "Bearer sk_test")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+
+	var page models.URLPage
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Links) != 2 {
+		t.Fatalf("links = %v", page.Links)
+	}
+	if secret := page.Links[0]; secret.LongURL != "" || secret.Rules != nil || secret.PasswordHash != "" {
+		t.Errorf("protected link = %+v, want destinations and hash left out", secret)
+	}
+	if public := page.Links[1]; public.LongURL != "https://example.com/public" || len(public.Rules) != 1 {
+		t.Errorf("public link = %+v, want destinations", public)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRedactLinkVariants(t *testing.T) {
+	u := &models.URL{LongURL: "https://example.com", PasswordHash: "hash",
+		Variants: []models.Variant{{Name: "a", Destination: "https://example.com/a", Weight: 1}}}
+	redactLink(u)
+	if u.LongURL != "" || u.PasswordHash != "" || u.Variants[0].Destination != "" || u.Variants[0].Name != "a" {
+		t.Errorf("redactLink = %+v", u)
+	}
+}
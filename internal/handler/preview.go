@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+// GET /{shortCode}+ - show where a link goes without following it
+func (h *URLHandler) PreviewURL(w http.ResponseWriter, r *http.Request) {
+	shortCode := mux.Vars(r)["shortCode"]
+	preview, err := h.service.PreviewURL(r.Context(), h.service.DomainForHost(r.Host), shortCode)
+	if err != nil {
+		h.writeError(w, r, "PreviewURL", err)
+		return
+	}
+	renderPreview(w, r, preview, false)
+}
+
+type previewPage struct {
+	Preview      *models.LinkPreview
+	Interstitial bool // opt-in page shown before redirecting rather than an explicit preview
+	ContinueURL  string
+}
+
+// renderPreview writes the preview as JSON for API clients or as an HTML page.
+// Continuing goes through /{code}?confirm=1, which skips the interstitial and
+// quarantine warning but still asks for the password of protected links.
+func renderPreview(w http.ResponseWriter, r *http.Request, p *models.LinkPreview, interstitial bool) {
+	w.Header().Set("Cache-Control", "no-store")
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, p)
+		return
+	}
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	renderHTML(w, http.StatusOK, "preview.html", previewPage{
+		Preview:      p,
+		Interstitial: interstitial,
+		ContinueURL:  confirmURL(r, p.ShortCode),
+	})
+}
+
+// wantsJSON reports whether the client asked for JSON via ?format=json or Accept.
+func wantsJSON(r *http.Request) bool {
+	if f := r.URL.Query().Get("format"); f != "" {
+		return f == "json"
+	}
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+// expectPreviewLink answers the lookup of code, which later requests find in
+// the L1 cache, and the click count each of the previews reads.
+func expectPreviewLink(mock sqlmock.Sqlmock, code string, passwordHash interface{}, interstitial bool, previews int) {
+	mock.ExpectQuery(regexp.QuoteMeta("FROM urls")).WithArgs("", code).WillReturnRows(sqlmock.NewRows(urlRow).
+		AddRow(1, "", code, "https://example.com/"+code, time.Now(), nil, nil, passwordHash, nil, nil, nil, "active", nil, interstitial, nil, nil, "ignore", nil, []byte("[]")))
+	for i := 0; i < previews; i++ {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM clicks")).WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+	}
+}
+
+func serve(r http.Handler, target, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestPreviewNegotiation(t *testing.T) {
+	r, mock := newRouter(t)
+	expectPreviewLink(mock, "promo", nil, false, 5)
+
+	tests := []struct {
+		target, accept string
+		json           bool
+	}{
+		{"/promo+", "", false},
+		{"/promo+", "text/html,application/xhtml+xml", false},
+		{"/promo+", "application/json", true},
+		{"/promo+?format=json", "text/html", true},
+		{"/promo+?format=html", "application/json", false},
+	}
+	for _, tt := range tests {
+		rec := serve(r, tt.target, tt.accept)
+		if rec.Code != http.StatusOK || rec.Header().Get("Cache-Control") != "no-store" {
+			t.Fatalf("%s (%s): status = %d, Cache-Control %q", tt.target, tt.accept, rec.Code, rec.Header().Get("Cache-Control"))
+		}
+		ct := rec.Header().Get("Content-Type")
+		if tt.json {
+			var p models.LinkPreview
+			if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil || !strings.HasPrefix(ct, "application/json") {
+				t.Errorf("%s (%s): Content-Type %q, body %s", tt.target, tt.accept, ct, rec.Body)
+			} else if p.Destination != "https://example.com/promo" || p.Clicks != 5 || p.ShortCode != "promo" {
+				t.Errorf("%s (%s): preview = %+v", tt.target, tt.accept, p)
+			}
+			continue
+		}
+		if !strings.HasPrefix(ct, "text/html") || !strings.Contains(rec.Body.String(), "https://example.com/promo") {
+			t.Errorf("%s (%s): Content-Type %q, body %s", tt.target, tt.accept, ct, rec.Body)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPreviewHidesProtectedDestination(t *testing.T) {
+	r, mock := newRouter(t)
+	expectPreviewLink(mock, "secret", "$2a$10$hash", false, 2)
+
+	rec := serve(r, "/secret+", "application/json")
+	var p models.LinkPreview
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Protected || p.Destination != "" || strings.Contains(rec.Body.String(), "example.com/secret") {
+		t.Errorf("JSON preview = %s", rec.Body)
+	}
+	rec = serve(r, "/secret+", "")
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "example.com/secret") {
+		t.Errorf("HTML preview: status = %d, body %s", rec.Code, rec.Body)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// Interstitial links show the preview first; ?confirm=1 skips it.
+func TestInterstitialConfirm(t *testing.T) {
+	r, mock := newRouter(t)
+	expectPreviewLink(mock, "leave", nil, true, 1)
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO clicks")).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	rec := serve(r, "/leave?ref=news", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "You are about to leave") ||
+		!strings.Contains(rec.Body.String(), `href="/leave?confirm=1&amp;ref=news"`) {
+		t.Fatalf("interstitial: status = %d, body %s", rec.Code, rec.Body)
+	}
+	rec = serve(r, "/leave?confirm=1&ref=news", "")
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "https://example.com/leave" {
+		t.Errorf("confirmed: status = %d, Location %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	// The click is recorded after the redirect
+	deadline := time.Now().Add(2 * time.Second)
+	for mock.ExpectationsWereMet() != nil && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// Confirming only skips the interstitial; protected links still want the password.
+func TestInterstitialConfirmProtected(t *testing.T) {
+	r, mock := newRouter(t)
+	expectPreviewLink(mock, "vault", "$2a$10$hash", true, 1)
+
+	rec := serve(r, "/vault", "")
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "example.com/vault") {
+		t.Fatalf("interstitial: status = %d, body %s", rec.Code, rec.Body)
+	}
+	rec = serve(r, "/vault?confirm=1", "")
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("Location") != "" || !strings.Contains(rec.Body.String(), "password") {
+		t.Errorf("confirmed: status = %d, body %s", rec.Code, rec.Body)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Siddarth2230/url-shortener/pkg/qrcode"
+)
+
+// GET /api/links/{shortCode}/qr?domain=&format=png|svg&size=&margin=&level=L|M|Q|H&fg=&bg=
+// - QR code of the short URL
+func (h *URLHandler) GetQRCode(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	q := r.URL.Query()
+
+	shortCode := mux.Vars(r)["shortCode"]
+	domain, err := h.service.ResolveDomain(q.Get("domain"))
+	if err != nil {
+		h.writeError(w, r, "GetQRCode", err)
+		return
+	}
+
+	format := qrcode.Format(q.Get("format"))
+	switch format {
+	case "":
+		format = qrcode.PNG
+	case qrcode.PNG, qrcode.SVG:
+	default:
+		h.writeError(w, r, "GetQRCode", invalidRequest("format", "format must be png or svg"))
+		return
+	}
+
+	opts, err := qrOptions(q.Get("size"), q.Get("margin"), q.Get("level"), q.Get("fg"), q.Get("bg"))
+	if err != nil {
+		h.writeError(w, r, "GetQRCode", invalidRequest("", err.Error()))
+		return
+	}
+
+	link, err := h.service.GetURL(ctx, domain, shortCode)
+	if err != nil {
+		h.writeError(w, r, "GetQRCode", err)
+		return
+	}
+	content := h.service.ShortURL(link.Domain, link.ShortCode)
+
+	// The image only depends on the short URL and the options, so it can be
+	// revalidated without rendering
+	etag := qrcode.ETag(content, format, opts)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	if etagMatches(r.Header.Values("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	img, err := qrcode.Render(content, format, opts)
+	if err != nil {
+		h.writeError(w, r, "GetQRCode", err)
+		return
+	}
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Length", strconv.Itoa(len(img)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(img)
+}
+
+// etagMatches reports whether an If-None-Match header (a list of entity tags,
+// possibly over several lines) matches etag. The comparison is weak, as
+// RFC 9110 asks for If-None-Match, and "*" matches any tag.
+func etagMatches(header []string, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, line := range header {
+		for _, tag := range strings.Split(line, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// qrOptions parses and validates the QR query parameters.
+func qrOptions(size, margin, level, fg, bg string) (qrcode.Options, error) {
+	var opts qrcode.Options
+	var err error
+	if size != "" {
+		if opts.Size, err = strconv.Atoi(size); err != nil {
+			return opts, errors.New("size must be an integer")
+		}
+	}
+	if margin != "" {
+		m, err := strconv.Atoi(margin)
+		if err != nil {
+			return opts, errors.New("margin must be an integer")
+		}
+		opts.Margin = &m
+	}
+	opts.Level = level
+	if fg != "" {
+		if opts.Foreground, err = qrcode.ParseColor(fg); err != nil {
+			return opts, err
+		}
+	}
+	if bg != "" {
+		if opts.Background, err = qrcode.ParseColor(bg); err != nil {
+			return opts, err
+		}
+	}
+	return opts.Normalize()
+}
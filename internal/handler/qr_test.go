@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/Siddarth2230/url-shortener/pkg/problem"
+)
+
+func expectQRLink(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta("FROM urls")).WithArgs("", "promo").WillReturnRows(sqlmock.NewRows(urlRow).
+		AddRow(1, "", "promo", "https://example.com", time.Now(), nil, nil, nil, nil, nil, nil, "active", nil, false, nil, nil, "ignore", nil, []byte("[]")))
+}
+
+func getQR(r http.Handler, query string, ifNoneMatch ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/links/promo/qr"+query, nil)
+	for _, v := range ifNoneMatch {
+		req.Header.Add("If-None-Match", v)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestQRCodeETag(t *testing.T) {
+	r, mock := newRouter(t)
+	expectQRLink(mock)
+	rec := getQR(r, "?format=svg")
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" || rec.Header().Get("Content-Type") != "image/svg+xml" {
+		t.Fatalf("status = %d, ETag %q, Content-Type %q", rec.Code, etag, rec.Header().Get("Content-Type"))
+	}
+
+	tests := []struct {
+		name   string
+		header []string
+		status int
+	}{
+		{"exact", []string{etag}, http.StatusNotModified},
+		{"list", []string{`"other", ` + etag}, http.StatusNotModified},
+		{"weak", []string{"W/" + etag}, http.StatusNotModified},
+		{"several headers", []string{`"other"`, etag}, http.StatusNotModified},
+		{"any", []string{"*"}, http.StatusNotModified},
+		{"other tag", []string{`"other", W/"another"`}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		expectQRLink(mock)
+		rec := getQR(r, "?format=svg", tt.header...)
+		if rec.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.status)
+		}
+		if tt.status == http.StatusNotModified && (rec.Body.Len() != 0 || rec.Header().Get("ETag") != etag) {
+			t.Errorf("%s: 304 with body %d bytes, ETag %q", tt.name, rec.Body.Len(), rec.Header().Get("ETag"))
+		}
+	}
+
+	// Other options render another image
+	expectQRLink(mock)
+	if rec := getQR(r, "?format=svg&level=H", etag); rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Errorf("other options: status = %d, ETag %q", rec.Code, rec.Header().Get("ETag"))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestQRCodeInvalidOptions(t *testing.T) {
+	r, mock := newRouter(t)
+	for _, query := range []string{"?size=abc", "?size=10", "?size=100000", "?level=X", "?fg=blue", "?bg=%23ggg", "?margin=-1", "?format=gif"} {
+		rec := getQR(r, query)
+		var p problem.Details
+		if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil || rec.Code != http.StatusBadRequest || p.Code != "invalid_request" {
+			t.Errorf("%s: status = %d, body %s", query, rec.Code, rec.Body)
+		}
+	}
+	// Options are checked before the link is looked up
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+// POST /api/report/{shortCode}?domain= - report a link as abusive
+func (h *URLHandler) ReportLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	shortCode := mux.Vars(r)["shortCode"]
+	domain, err := h.service.ResolveDomain(r.URL.Query().Get("domain"))
+	if err != nil {
+		h.writeError(w, r, "ReportLink", err)
+		return
+	}
+
+	var report models.AbuseReport
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 8192))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&report); err != nil {
+		h.writeError(w, r, "ReportLink", invalidRequest("", "invalid request payload"))
+		return
+	}
+	report.ReporterIP = clientIP(r)
+	report.CreatedAt = time.Now().UTC()
+	if report.ReporterIP == "" {
+		h.writeError(w, r, "ReportLink", invalidRequest("", "unable to determine client address"))
+		return
+	}
+
+	if err := h.service.ReportLink(ctx, domain, shortCode, &report); err != nil {
+		h.writeError(w, r, "ReportLink", err)
+		return
+	}
+	// Same answer whether or not the report changed anything, so reporters can't
+	// probe the threshold
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "received"})
+}
+
+type quarantinePage struct {
+	ContinueURL string
+	Destination string // empty for password-protected links
+}
+
+// renderQuarantineWarning shows the interstitial for a quarantined link. Visitors
+// continue through /{code}?confirm=1, which still asks for the password of
+// protected links, so their destination isn't shown here either.
+func renderQuarantineWarning(w http.ResponseWriter, r *http.Request, link *models.URL) {
+	page := quarantinePage{ContinueURL: confirmURL(r, link.ShortCode)}
+	if !link.IsProtected() {
+		page.Destination = link.LongURL
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	renderHTML(w, http.StatusOK, "quarantine.html", page)
+}
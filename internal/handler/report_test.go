@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// The quarantine warning shows where a link goes, except for protected links.
+func TestQuarantineWarningHidesProtectedDestination(t *testing.T) {
+	for _, tt := range []struct {
+		code, hash string
+		shown      bool
+	}{
+		{"public", "", true},
+		{"secret", "$2a$10$hash", false},
+	} {
+		r, mock := newRouter(t)
+		mock.ExpectQuery(regexp.QuoteMeta("FROM urls")).WithArgs("", tt.code).WillReturnRows(sqlmock.NewRows(urlRow).
+			AddRow(1, "", tt.code, "https://example.com/"+tt.code, time.Now(), nil, nil, tt.hash, nil, nil, nil, "quarantined", "abuse", false, nil, nil, "ignore", nil, []byte("[]")))
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/"+tt.code, nil))
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "has been reported") {
+			t.Fatalf("%s: status = %d, want the warning: %s", tt.code, rec.Code, rec.Body)
+		}
+		if shown := strings.Contains(rec.Body.String(), "https://example.com/"+tt.code); shown != tt.shown {
+			t.Errorf("%s: destination shown = %v, want %v", tt.code, shown, tt.shown)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterRoutes adds every route described by the OpenAPI document to r. The
+// short code routes match any single path segment, so other fixed routes
+// (/metrics) must be registered before calling it.
+func (h *URLHandler) RegisterRoutes(r *mux.Router) {
+	// API endpoints
+	r.HandleFunc("/shorten", h.ShortenURL).Methods("POST")
+	r.HandleFunc("/api/links", h.ListLinks).Methods("GET")
+	r.HandleFunc("/api/links/{shortCode}/stats", h.GetStats).Methods("GET")
+	r.HandleFunc("/api/links/{shortCode}/qr", h.GetQRCode).Methods("GET")
+	r.HandleFunc("/api/report/{shortCode}", h.ReportLink).Methods("POST")
+	r.HandleFunc("/api/webhooks", h.CreateWebhook).Methods("POST")
+	r.HandleFunc("/api/webhooks", h.ListWebhooks).Methods("GET")
+	r.HandleFunc("/api/webhooks/dead-letters", h.ListDeadLetters).Methods("GET")
+	r.HandleFunc("/api/webhooks/deliveries/{id}/replay", h.ReplayDelivery).Methods("POST")
+	r.HandleFunc("/api/webhooks/{id}", h.DeleteWebhook).Methods("DELETE")
+
+	// Documentation
+	r.HandleFunc("/api/openapi.json", h.OpenAPISpec).Methods("GET")
+	r.HandleFunc("/api/docs", h.APIDocs).Methods("GET")
+
+	// Health check endpoint
+	r.HandleFunc("/health", h.Health).Methods("GET")
+
+	// Short code routes match any single segment, so they go last
+	r.HandleFunc("/{shortCode}+", h.PreviewURL).Methods("GET") // before /{shortCode}, which would match the "+"
+	r.HandleFunc("/{shortCode}", h.RedirectURL).Methods("GET")
+	r.HandleFunc("/{shortCode}", h.UnlockURL).Methods("POST")
+}
+
+// GET /health - liveness check
+func (h *URLHandler) Health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "healthy"})
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+// expectStats expects the link lookup; withStats also expects the click queries,
+// which look the link up once more.
+func expectStats(mock sqlmock.Sqlmock, owner, passwordHash interface{}, withStats bool) {
+	expectLink := func() {
+		mock.ExpectQuery(regexp.QuoteMeta("FROM urls")).WithArgs("", "split").WillReturnRows(sqlmock.NewRows(urlRow).
+			AddRow(7, "", "split", "https://example.com", time.Now(), nil, owner, passwordHash, nil, nil, nil, "active", nil, false, nil, nil, "ignore", nil, []byte("[]")))
+	}
+	expectLink()
+	if !withStats {
+		return
+	}
+	expectLink()
+	mock.ExpectQuery(regexp.QuoteMeta("MIN(clicked_at)")).WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "unique", "min", "max"}).AddRow(3, 2, nil, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("date_trunc")).WithArgs(7, 30).
+		WillReturnRows(sqlmock.NewRows([]string{"day", "count"}))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM url_variants")).WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "destination", "weight", "clicks", "unique"}).
+			AddRow(1, "a", "https://example.com/a", 1, 2, 1).
+			AddRow(2, "b", "https://example.com/b", 1, 1, 1))
+}
+
+func getStats(r http.Handler, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/links/split/stats", nil)
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGetStatsRequiresOwner(t *testing.T) {
+	r, mock := newRouter(t)
+	expectStats(mock, "team", nil, false)
+	if rec := getStats(r, ""); rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("no key: status = %d, want 401 with a challenge", rec.Code)
+	}
+
+	expectAPIKey(mock, "someone-else")
+	expectStats(mock, "team", nil, false)
+	if rec := getStats(r, "sk_other"); rec.Code != http.StatusForbidden {
+		t.Errorf("other key: status = %d, want 403", rec.Code)
+	}
+
+	expectAPIKey(mock, "team")
+	expectStats(mock, "team", "$2a$10$hash", true)
+	rec := getStats(r, "sk_team")
+	var stats models.ClickStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("owner: status = %d: %s", rec.Code, rec.Body)
+	}
+	// The owner sees where a protected link's variants go
+	if len(stats.Variants) != 2 || stats.Variants[0].Destination != "https://example.com/a" {
+		t.Errorf("owner variants = %+v", stats.Variants)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetStatsUnownedProtectedRedacted(t *testing.T) {
+	r, mock := newRouter(t)
+	expectStats(mock, nil, "$2a$10$hash", true)
+	rec := getStats(r, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var stats models.ClickStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.Variants) != 2 || stats.TotalClicks != 3 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	for _, v := range stats.Variants {
+		if v.Destination != "" || v.Name == "" {
+			t.Errorf("variant = %+v, want the destination left out", v)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -1,89 +1,289 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"log/slog"
+	"net"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/Siddarth2230/url-shortener/internal/models"
 	"github.com/Siddarth2230/url-shortener/internal/service"
+	"github.com/Siddarth2230/url-shortener/pkg/logging"
+	"github.com/Siddarth2230/url-shortener/pkg/qrcode"
 )
 
 type URLHandler struct {
 	service *service.URLService
+	logger  *slog.Logger
 }
 
-func NewURLHandler(svc *service.URLService) *URLHandler {
-	return &URLHandler{service: svc}
+var tracer = otel.Tracer("github.com/Siddarth2230/url-shortener/internal/handler")
+
+func NewURLHandler(svc *service.URLService, logger *slog.Logger) *URLHandler {
+	return &URLHandler{service: svc, logger: logger}
+}
+
+// log returns the request logger carried by ctx, or the handler's.
+func (h *URLHandler) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, h.logger)
 }
 
 // POST /shorten
 func (h *URLHandler) ShortenURL(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	ctx, span := tracer.Start(r.Context(), "URLHandler.ShortenURL")
+	defer span.End()
+	r = r.WithContext(ctx)
 
 	// decode request
 	var req models.ShortenRequest
 	dec := json.NewDecoder(r.Body)
 	dec.DisallowUnknownFields()
 	if err := dec.Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid request payload")
+		h.writeError(w, r, "ShortenURL", invalidRequest("", "invalid request payload"))
+		return
+	}
+
+	// An API key is optional; links created with one are owned by it
+	key, err := h.apiKey(r)
+	if err != nil {
+		h.writeError(w, r, "ShortenURL", err)
 		return
 	}
+	if key != nil {
+		req.Owner = key.Name
+		req.Tier = key.Tier
+	}
 
 	// call service
 	resp, err := h.service.ShortenURL(ctx, req)
 	if err != nil {
-		// map service errors to HTTP responses
-		switch err {
-		case service.ErrInvalidURL:
-			writeError(w, http.StatusBadRequest, err.Error())
-			return
-		case service.ErrCustomCodeTaken:
-			writeError(w, http.StatusConflict, err.Error()) // 409 Conflict
-			return
-		default:
-			// unknown/internal error
-			log.Printf("ShortenURL error: %v", err)
-			writeError(w, http.StatusInternalServerError, "internal server error")
-			return
+		h.writeError(w, r, "ShortenURL", err)
+		return
+	}
+
+	if req.QR {
+		// The link exists at this point; a QR failure shouldn't fail the request
+		if uri, err := qrcode.DataURI(resp.ShortURL); err != nil {
+			h.log(ctx).Warn("QR code failed", "op", "ShortenURL", "err", err)
+		} else {
+			resp.QRCode = uri
 		}
 	}
 
-	// success: return 201 Created with JSON body
-	writeJSON(w, http.StatusCreated, resp)
+	// success: return 201 Created with JSON body, or 200 OK for an existing link
+	status := http.StatusCreated
+	if resp.Existing {
+		status = http.StatusOK
+	}
+	writeJSON(w, status, resp)
 }
 
 // GET /{shortCode} - redirect to long URL
 func (h *URLHandler) RedirectURL(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	ctx, span := tracer.Start(r.Context(), "URLHandler.RedirectURL")
+	defer span.End()
+	r = r.WithContext(ctx)
 
 	vars := mux.Vars(r)
 	shortCode, ok := vars["shortCode"]
 	if !ok || shortCode == "" {
-		writeError(w, http.StatusBadRequest, "missing short code")
+		h.writeError(w, r, "RedirectURL", invalidRequest("short_code", "missing short code"))
 		return
 	}
+	span.SetAttributes(attribute.String("url.short_code", shortCode))
 
-	longURL, err := h.service.GetLongURL(ctx, shortCode)
+	link, err := h.service.ResolveURL(ctx, h.service.DomainForHost(r.Host), shortCode)
 	if err != nil {
-		switch err {
-		case service.ErrNotFound:
-			writeError(w, http.StatusNotFound, "short code not found")
-			return
-		case service.ErrExpired:
-			writeError(w, http.StatusGone, "short URL expired")
+		h.writeError(w, r, "RedirectURL", err)
+		return
+	}
+
+	// Reported links warn before anything else; the warning links back with confirm=1
+	confirmed := r.URL.Query().Get("confirm") == "1"
+	if link.IsQuarantined() && !confirmed {
+		renderQuarantineWarning(w, r, link)
+		return
+	}
+	if link.Interstitial && !confirmed {
+		preview, err := h.service.PreviewURL(ctx, link.Domain, shortCode)
+		if err != nil {
+			h.writeError(w, r, "RedirectURL", err)
 			return
+		}
+		renderPreview(w, r, preview, true)
+		return
+	}
+
+	// Protected links never redirect straight from a cache hit; ask for the password first
+	if link.IsProtected() {
+		renderPasswordPrompt(w, http.StatusUnauthorized, shortCode, "", false)
+		return
+	}
+
+	if err := h.service.ConsumeClick(ctx, link); err != nil {
+		h.writeError(w, r, "RedirectURL", err)
+		return
+	}
+
+	dest, variantID := h.destination(w, r, link)
+	h.recordClick(r, link, variantID)
+
+	// Redirect (302 Found). Use 302 so browsers use it as a temporary redirect by default.
+	http.Redirect(w, r, dest, http.StatusFound)
+}
+
+// POST /{shortCode} - submit the password of a protected link
+func (h *URLHandler) UnlockURL(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	shortCode := mux.Vars(r)["shortCode"]
+	if shortCode == "" {
+		h.writeError(w, r, "UnlockURL", invalidRequest("short_code", "missing short code"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 4096)
+	if err := r.ParseForm(); err != nil {
+		h.writeError(w, r, "UnlockURL", invalidRequest("", "invalid form"))
+		return
+	}
+
+	domain := h.service.DomainForHost(r.Host)
+	link, err := h.service.UnlockURL(ctx, domain, shortCode, r.PostFormValue("password"))
+	if err != nil {
+		// Visitors get the prompt again rather than problem details
+		switch {
+		case errors.Is(err, service.ErrWrongPassword):
+			renderPasswordPrompt(w, http.StatusUnauthorized, shortCode, "Incorrect password.", false)
+		case errors.Is(err, service.ErrTooManyAttempts):
+			renderPasswordPrompt(w, http.StatusTooManyRequests, shortCode, "Too many attempts. Try again later.", true)
 		default:
-			log.Printf("RedirectURL error: %v", err)
-			writeError(w, http.StatusInternalServerError, "internal server error")
+			h.writeError(w, r, "UnlockURL", err)
+		}
+		return
+	}
+
+	if err := h.service.ConsumeClick(ctx, link); err != nil {
+		h.writeError(w, r, "UnlockURL", err)
+		return
+	}
+
+	dest, variantID := h.destination(w, r, link)
+	h.recordClick(r, link, variantID)
+
+	// 303 so the browser follows with a GET to the destination
+	http.Redirect(w, r, dest, http.StatusSeeOther)
+}
+
+// GET /api/links/{shortCode}/stats?domain= - click statistics, broken down per split variant.
+// Links with an owner need the owner's API key.
+func (h *URLHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	shortCode := mux.Vars(r)["shortCode"]
+	domain, err := h.service.ResolveDomain(r.URL.Query().Get("domain"))
+	if err != nil {
+		h.writeError(w, r, "GetStats", err)
+		return
+	}
+	days := 30
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 365 {
+			h.writeError(w, r, "GetStats", invalidRequest("days", "days must be between 1 and 365"))
 			return
 		}
+		days = n
 	}
 
-	// Redirect (302 Found). Use 302 so browsers use it as a temporary redirect by default.
-	http.Redirect(w, r, longURL, http.StatusFound)
+	key, err := h.apiKey(r)
+	if err != nil {
+		h.writeError(w, r, "GetStats", err)
+		return
+	}
+	link, err := h.service.GetURL(ctx, domain, shortCode)
+	if err != nil {
+		h.writeError(w, r, "GetStats", err)
+		return
+	}
+	if link.Owner != "" {
+		if key == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+			h.writeError(w, r, "GetStats", errAPIKeyRequired)
+			return
+		}
+		if key.Name != link.Owner {
+			h.writeError(w, r, "GetStats", errNotOwner)
+			return
+		}
+	}
+
+	stats, err := h.service.GetClickStats(ctx, link.Domain, link.ShortCode, days)
+	if err != nil {
+		h.writeError(w, r, "GetStats", err)
+		return
+	}
+	// Anyone may read the stats of unowned links; don't give away where protected ones go
+	if link.IsProtected() && link.Owner == "" {
+		for i := range stats.Variants {
+			stats.Variants[i].Destination = ""
+		}
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+type passwordPage struct {
+	ShortCode string
+	Error     string
+	Locked    bool
+}
+
+func renderPasswordPrompt(w http.ResponseWriter, status int, shortCode, msg string, locked bool) {
+	// The prompt must not be cached by browsers or proxies in place of the destination
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	renderHTML(w, status, "password.html", passwordPage{ShortCode: shortCode, Error: msg, Locked: locked})
+}
+
+// recordClick stores the click asynchronously so the redirect isn't delayed by the insert.
+func (h *URLHandler) recordClick(r *http.Request, link *models.URL, variantID *int64) {
+	click := &models.Click{
+		URLID:     link.ID,
+		ShortCode: link.ShortCode,
+		VariantID: variantID,
+		ClickedAt: time.Now().UTC(),
+		IPAddress: clientIP(r),
+		UserAgent: r.UserAgent(),
+		Referer:   r.Referer(),
+	}
+	// Keep the request's values (logger) but not its cancellation, so the
+	// insert isn't canceled when the request ends
+	ctx := context.WithoutCancel(r.Context())
+	go func() {
+		if err := h.service.RecordClick(ctx, click); err != nil {
+			h.log(ctx).Error("record click failed", "code", link.ShortCode, "err", err)
+		}
+	}()
+}
+
+// clientIP returns the remote IP of the request without the port.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if net.ParseIP(host) == nil {
+		return ""
+	}
+	return host
 }
 
 // helper: write JSON response
@@ -95,11 +295,6 @@ func writeJSON(w http.ResponseWriter, status int, v interface{}) {
 	}
 	if err := json.NewEncoder(w).Encode(v); err != nil {
 		// Log encoding error (can't write response now)
-		log.Printf("writeJSON encode error: %v", err)
+		slog.Error("writeJSON encode failed", "err", err)
 	}
 }
-
-// helper: write an error message in JSON form { "error": "msg" }
-func writeError(w http.ResponseWriter, status int, msg string) {
-	writeJSON(w, status, map[string]string{"error": msg})
-}
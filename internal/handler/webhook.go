@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+	"github.com/Siddarth2230/url-shortener/internal/service"
+)
+
+// apiKey authenticates the request's API key, sent as "Authorization: Bearer <key>"
+// or "X-API-Key: <key>". It returns nil without an error when no key was sent.
+func (h *URLHandler) apiKey(r *http.Request) (*models.APIKey, error) {
+	raw := r.Header.Get("X-API-Key")
+	if auth := r.Header.Get("Authorization"); raw == "" && auth != "" {
+		token, ok := strings.CutPrefix(auth, "Bearer ")
+		if !ok {
+			return nil, service.ErrInvalidAPIKey
+		}
+		raw = strings.TrimSpace(token)
+	}
+	if raw == "" {
+		return nil, nil
+	}
+	return h.service.AuthenticateAPIKey(r.Context(), raw)
+}
+
+// requireAPIKey is apiKey for endpoints that need one; it answers 401 itself.
+func (h *URLHandler) requireAPIKey(w http.ResponseWriter, r *http.Request) *models.APIKey {
+	key, err := h.apiKey(r)
+	if err != nil && !errors.Is(err, service.ErrInvalidAPIKey) {
+		h.writeError(w, r, "apiKey", err)
+		return nil
+	}
+	if key == nil {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+		h.writeError(w, r, "apiKey", errAPIKeyRequired)
+		return nil
+	}
+	return key
+}
+
+// POST /api/webhooks - subscribe a URL to the API key's link events
+func (h *URLHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	key := h.requireAPIKey(w, r)
+	if key == nil {
+		return
+	}
+
+	var req models.WebhookSubscriptionRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 8192))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		h.writeError(w, r, "CreateWebhook", invalidRequest("", "invalid request payload"))
+		return
+	}
+
+	sub, err := h.service.CreateWebhook(r.Context(), key, req)
+	if err != nil {
+		h.writeError(w, r, "CreateWebhook", err)
+		return
+	}
+	// The secret is only ever shown here
+	writeJSON(w, http.StatusCreated, sub)
+}
+
+// GET /api/webhooks - list the API key's subscriptions
+func (h *URLHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	key := h.requireAPIKey(w, r)
+	if key == nil {
+		return
+	}
+	subs, err := h.service.ListWebhooks(r.Context(), key)
+	if err != nil {
+		h.writeError(w, r, "ListWebhooks", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, subs)
+}
+
+// DELETE /api/webhooks/{id} - remove a subscription
+func (h *URLHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	key := h.requireAPIKey(w, r)
+	if key == nil {
+		return
+	}
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.writeError(w, r, "DeleteWebhook", service.ErrWebhookNotFound)
+		return
+	}
+	if err := h.service.DeleteWebhook(r.Context(), key, id); err != nil {
+		h.writeError(w, r, "DeleteWebhook", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /api/webhooks/dead-letters?limit= - deliveries that ran out of retries
+func (h *URLHandler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	key := h.requireAPIKey(w, r)
+	if key == nil {
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	deliveries, err := h.service.DeadLetters(r.Context(), key, limit)
+	if err != nil {
+		h.writeError(w, r, "ListDeadLetters", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+// POST /api/webhooks/deliveries/{id}/replay - queue a dead delivery again
+func (h *URLHandler) ReplayDelivery(w http.ResponseWriter, r *http.Request) {
+	key := h.requireAPIKey(w, r)
+	if key == nil {
+		return
+	}
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.writeError(w, r, "ReplayDelivery", service.ErrDeliveryNotFound)
+		return
+	}
+	if err := h.service.ReplayDelivery(r.Context(), key, id); err != nil {
+		h.writeError(w, r, "ReplayDelivery", err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "queued"})
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/Siddarth2230/url-shortener/pkg/logging"
+)
+
+// AccessLog writes one line per request with its status, size and latency.
+// It must run inside RequestID to pick up the request logger.
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+		next.ServeHTTP(ww, r)
+
+		level := slog.LevelInfo
+		if ww.statusCode >= 500 {
+			level = slog.LevelError
+		}
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		logging.FromContext(r.Context(), nil).Log(r.Context(), level, "request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", ww.statusCode,
+			"bytes", ww.bytes,
+			"latency_ms", float64(time.Since(start).Microseconds())/1000,
+			"ip", host, // truncated by the logger
+			"user_agent", r.UserAgent(),
+		)
+	})
+}
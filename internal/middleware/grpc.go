@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/Siddarth2230/url-shortener/pkg/logging"
+	"github.com/Siddarth2230/url-shortener/pkg/metrics"
+)
+
+// The gRPC interceptors mirror the HTTP middleware. Chain them in the same
+// order: UnaryRequestID, UnaryAccessLog, UnaryTracing, UnaryMetrics.
+
+// requestIDMetadata is RequestIDHeader as gRPC metadata keys are lowercase.
+var requestIDMetadata = strings.ToLower(RequestIDHeader)
+
+// UnaryRequestID is RequestID for gRPC: the ID travels in "x-request-id"
+// metadata and is sent back as a response header.
+func UnaryRequestID(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		id := firstMetadata(ctx, requestIDMetadata)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, id))
+
+		ctx = context.WithValue(ctx, requestIDKey{}, id)
+		ctx = logging.WithContext(ctx, logger.With("request_id", id))
+		return handler(ctx, req)
+	}
+}
+
+// UnaryAccessLog is AccessLog for gRPC: one line per call with its status
+// code and latency. It must run inside UnaryRequestID.
+func UnaryAccessLog(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+
+	code := status.Code(err)
+	level := slog.LevelInfo
+	if serverError(code) {
+		level = slog.LevelError
+	}
+	var host string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host = p.Addr.String()
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+	}
+	logging.FromContext(ctx, nil).Log(ctx, level, "rpc",
+		"method", info.FullMethod,
+		"code", code.String(),
+		"latency_ms", float64(time.Since(start).Microseconds())/1000,
+		"ip", host, // truncated by the logger
+		"user_agent", firstMetadata(ctx, "user-agent"),
+	)
+	return resp, err
+}
+
+// UnaryTracing is Tracing for gRPC: a server span per call, continuing the
+// caller's trace from W3C traceparent metadata.
+func UnaryTracing(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+
+	service, method, _ := strings.Cut(strings.TrimPrefix(info.FullMethod, "/"), "/")
+	ctx, span := tracer.Start(ctx, strings.TrimPrefix(info.FullMethod, "/"),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.service", service),
+			attribute.String("rpc.method", method),
+		),
+	)
+	defer span.End()
+
+	if sc := span.SpanContext(); sc.IsValid() {
+		ctx = logging.WithContext(ctx, logging.FromContext(ctx, nil).With("trace_id", sc.TraceID().String()))
+	}
+
+	resp, err := handler(ctx, req)
+
+	code := status.Code(err)
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
+	if serverError(code) {
+		span.SetStatus(otelcodes.Error, code.String())
+	}
+	return resp, err
+}
+
+// UnaryMetrics is MetricsMiddleware for gRPC, labeled by full method name.
+func UnaryMetrics(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	inFlight := metrics.GRPCRequestsInFlight.WithLabelValues(info.FullMethod)
+	inFlight.Inc()
+	defer inFlight.Dec()
+
+	resp, err := handler(ctx, req)
+
+	code := status.Code(err).String()
+	metrics.Observe(ctx, metrics.GRPCRequestDuration.WithLabelValues(info.FullMethod, code), time.Since(start).Seconds())
+	metrics.GRPCRequestTotal.WithLabelValues(info.FullMethod, code).Inc()
+	return resp, err
+}
+
+// serverError reports whether code is the server's fault, like a 5xx status.
+func serverError(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal, codes.Unavailable, codes.DataLoss:
+		return true
+	}
+	return false
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	if v := metadata.ValueFromIncomingContext(ctx, key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// metadataCarrier lets the propagator read trace context from gRPC metadata.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -8,10 +8,20 @@ import (
 	"github.com/Siddarth2230/url-shortener/pkg/metrics"
 )
 
-// MetricsMiddleware tracks HTTP request metrics
+// MetricsMiddleware tracks HTTP request metrics, labeled by the matched mux
+// route template (e.g. "/{shortCode}") so each endpoint gets its own series
+// without one per short code. Register it with the router's Use.
 func MetricsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
+		route := routeTemplate(r)
+		if route == "" {
+			route = "unmatched"
+		}
+
+		inFlight := metrics.RequestsInFlight.WithLabelValues(route)
+		inFlight.Inc()
+		defer inFlight.Dec()
 
 		// Wrap response writer to capture status code
 		ww := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
@@ -23,18 +33,26 @@ func MetricsMiddleware(next http.Handler) http.Handler {
 		duration := time.Since(start).Seconds()
 		status := strconv.Itoa(ww.statusCode)
 
-		metrics.RequestDuration.WithLabelValues(r.Method, status).Observe(duration)
-		metrics.RequestTotal.WithLabelValues(r.Method, status).Inc()
+		metrics.Observe(r.Context(), metrics.RequestDuration.WithLabelValues(r.Method, route, status), duration)
+		metrics.RequestTotal.WithLabelValues(r.Method, route, status).Inc()
+		metrics.ResponseSize.WithLabelValues(r.Method, route).Observe(float64(ww.bytes))
 	})
 }
 
-// responseWriter wraps http.ResponseWriter to capture status code
+// responseWriter wraps http.ResponseWriter to capture status code and body size
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
+	bytes      int
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += n
+	return n, err
+}
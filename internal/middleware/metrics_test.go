@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/Siddarth2230/url-shortener/pkg/metrics"
+)
+
+// Requests are labeled with the route template, never the raw path, so short
+// codes don't each get their own series.
+func TestMetricsRouteLabel(t *testing.T) {
+	r := mux.NewRouter()
+	r.Use(MetricsMiddleware)
+	r.HandleFunc("/api/links/{shortCode}/stats", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("{}"))
+	})
+	r.HandleFunc("/{shortCode}", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://example.com", http.StatusFound)
+	})
+
+	redirects := metrics.RequestTotal.WithLabelValues("GET", "/{shortCode}", "302")
+	stats := metrics.RequestTotal.WithLabelValues("GET", "/api/links/{shortCode}/stats", "200")
+	beforeRedirects, beforeStats := testutil.ToFloat64(redirects), testutil.ToFloat64(stats)
+
+	for _, path := range []string{"/abc123", "/xyz789", "/api/links/abc123/stats"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if got := testutil.ToFloat64(redirects) - beforeRedirects; got != 2 {
+		t.Errorf("/{shortCode} requests = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(stats) - beforeStats; got != 1 {
+		t.Errorf("stats requests = %v, want 1", got)
+	}
+	for _, raw := range [][]string{{"GET", "/abc123", "302"}, {"GET", "/xyz789", "302"}, {"GET", "/api/links/abc123/stats", "200"}} {
+		if metrics.RequestTotal.DeleteLabelValues(raw...) {
+			t.Errorf("series labeled with raw path %s", raw[1])
+		}
+	}
+}
+
+// Outside a mux route (e.g. wrapping the whole router) the route is "unmatched".
+func TestMetricsUnmatched(t *testing.T) {
+	h := MetricsMiddleware(http.NotFoundHandler())
+	unmatched := metrics.RequestTotal.WithLabelValues("GET", "unmatched", "404")
+	before := testutil.ToFloat64(unmatched)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/no-such-code", nil))
+
+	if got := testutil.ToFloat64(unmatched) - before; got != 1 {
+		t.Errorf("unmatched requests = %v, want 1", got)
+	}
+	if metrics.RequestTotal.DeleteLabelValues("GET", "/no-such-code", "404") {
+		t.Error("series labeled with the raw path")
+	}
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Kinds of admin-managed reserved words.
+const (
+	ReservedExact   = "reserved" // the word itself can't be claimed
+	ReservedBlocked = "blocked"  // no code may contain the word (profanity, trademarks)
+)
+
+// ReservedWord is an entry of the admin-managed reserved list.
+type ReservedWord struct {
+	Word      string    `json:"word"`
+	Kind      string    `json:"kind"`
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -3,20 +3,305 @@ package models
 import "time"
 
 type URL struct {
-	ID        int64      `json:"id" db:"id"`
+	ID int64 `json:"id" db:"id"`
+	// Domain is the branded host the link lives on; "" is the default domain.
+	// Short codes are unique per domain.
+	Domain    string     `json:"domain,omitempty" db:"domain"`
 	ShortCode string     `json:"short_code" db:"short_code"`
 	LongURL   string     `json:"long_url" db:"long_url"`
 	CreatedAt time.Time  `json:"created_at" db:"created_at"`
 	ExpiresAt *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	Owner     string     `json:"owner,omitempty" db:"owner"`
+	Tags      []string   `json:"tags,omitempty" db:"-"`
+	Title     string     `json:"title,omitempty" db:"title"`
+	Notes     string     `json:"notes,omitempty" db:"notes"`
+
+	// PasswordHash is the bcrypt hash of the link password, empty for public links.
+	// It is cached with the link so every cache layer can enforce the check.
+	PasswordHash string `json:"password_hash,omitempty" db:"password_hash"`
+
+	// MaxClicks limits how many redirects the link serves (nil = unlimited).
+	// ClicksRemaining is a snapshot from the DB; the authoritative count is
+	// decremented atomically in the database on every redirect.
+	MaxClicks       *int `json:"max_clicks,omitempty" db:"max_clicks"`
+	ClicksRemaining *int `json:"clicks_remaining,omitempty" db:"clicks_remaining"`
+
+	// Rules pick a different destination based on the visitor; the first match wins
+	// and LongURL is the fallback. They're cached with the link and evaluated in-process.
+	Rules []RedirectRule `json:"rules,omitempty" db:"rules"`
+
+	// Variants split traffic across weighted destinations (A/B tests). They are
+	// stored in url_variants and cached with the link.
+	Variants []Variant `json:"variants,omitempty" db:"-"`
+
+	// Status is one of the Status* constants ("" is treated as active).
+	// StatusReason explains a disabled or quarantined link, e.g. ReasonLegal.
+	Status       string `json:"status,omitempty" db:"status"`
+	StatusReason string `json:"status_reason,omitempty" db:"status_reason"`
+
+	// Interstitial shows a "you are leaving" page before every redirect.
+	Interstitial bool `json:"interstitial,omitempty" db:"interstitial"`
+
+	// QueryPolicy is one of the Query* constants and decides what happens to the
+	// short URL's query string on redirect. UTM parameters are added to the
+	// destination unless it already sets them.
+	QueryPolicy string `json:"query_policy,omitempty" db:"query_policy"`
+	UTM         *UTM   `json:"utm,omitempty" db:"utm"`
+}
+
+// Query passthrough policies.
+const (
+	QueryIgnore   = "ignore"   // drop the short URL's query string (default)
+	QueryMerge    = "merge"    // forward parameters the destination doesn't set
+	QueryOverride = "override" // forward parameters, replacing the destination's values
+)
+
+// UTM holds default campaign parameters (utm_source, utm_medium, ...).
+type UTM struct {
+	Source   string `json:"source,omitempty"`
+	Medium   string `json:"medium,omitempty"`
+	Campaign string `json:"campaign,omitempty"`
+	Term     string `json:"term,omitempty"`
+	Content  string `json:"content,omitempty"`
+}
+
+// Params returns the set parameters as name/value pairs in the conventional order.
+func (u *UTM) Params() [][2]string {
+	if u == nil {
+		return nil
+	}
+	var params [][2]string
+	for _, p := range [][2]string{
+		{"utm_source", u.Source},
+		{"utm_medium", u.Medium},
+		{"utm_campaign", u.Campaign},
+		{"utm_term", u.Term},
+		{"utm_content", u.Content},
+	} {
+		if p[1] != "" {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// Link statuses.
+const (
+	StatusActive      = "active"
+	StatusDisabled    = "disabled"    // never redirects
+	StatusQuarantined = "quarantined" // shows a warning before redirecting
+)
+
+// ReasonLegal marks links disabled because of a legal demand (served as 451).
+const ReasonLegal = "legal"
+
+// LinkKey identifies a link: a short code is only unique within its domain.
+type LinkKey struct {
+	Domain    string
+	ShortCode string
+}
+
+// Key returns the identity of the link.
+func (u *URL) Key() LinkKey {
+	return LinkKey{Domain: u.Domain, ShortCode: u.ShortCode}
+}
+
+// CaseCollision is a group of links on one domain whose codes differ only in
+// case. They must be resolved before codes become case-insensitive.
+type CaseCollision struct {
+	Domain   string   `json:"domain"`
+	Folded   string   `json:"folded"`    // the lowercased code they share
+	Codes    []string `json:"codes"`     // oldest first
+	LongURLs []string `json:"long_urls"` // in the order of Codes
+}
+
+// IsDisabled reports whether the link was taken down.
+func (u *URL) IsDisabled() bool {
+	return u.Status == StatusDisabled
+}
+
+// IsQuarantined reports whether visitors must confirm a warning before redirecting.
+func (u *URL) IsQuarantined() bool {
+	return u.Status == StatusQuarantined
+}
+
+// IsProtected reports whether the link requires a password before redirecting.
+func (u *URL) IsProtected() bool {
+	return u.PasswordHash != ""
+}
+
+// URLFilter narrows down bulk reads such as exports. Zero values mean "no filter".
+type URLFilter struct {
+	Domain        *string // nil = every domain, "" = default domain only
+	Owner         string
+	Tag           string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Expiry        string // one of the Expiry* constants, "" = any
+}
+
+// Expiry states for URLFilter.Expiry.
+const (
+	ExpiryActive  = "active"  // no expiry or expiring in the future
+	ExpiryExpired = "expired" // already expired
+)
+
+// ListOptions selects a page of links for GET /api/links.
+type ListOptions struct {
+	Filter URLFilter
+	Query  string // full-text search over title and destination
+	Sort   string // one of the Sort* constants
+	Cursor string // NextCursor of the previous page
+	Limit  int
+}
+
+// Sort orders for ListOptions.Sort.
+const (
+	SortNewest    = "-created_at"
+	SortOldest    = "created_at"
+	SortCode      = "short_code"
+	SortCodeDesc  = "-short_code"
+	SortTitle     = "title"
+	SortTitleDesc = "-title"
+)
+
+// URLPage is one page of a link listing. NextCursor is empty on the last page.
+type URLPage struct {
+	Links      []*URL `json:"links"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type ShortenRequest struct {
 	URL        string `json:"url" validate:"required,url"`
-	CustomCode string `json:"custom_code,omitempty" validate:"omitempty,alphanum,min=4,max=10"`
+	Domain     string `json:"domain,omitempty"`      // branded host to create the link on, default domain if empty
+	CustomCode string `json:"custom_code,omitempty"` // checked by the custom code policy
+	Password   string `json:"password,omitempty" validate:"omitempty,min=4,max=72"`
+	MaxClicks  *int   `json:"max_clicks,omitempty" validate:"omitempty,min=1"`
+
+	Rules    []RedirectRule `json:"rules,omitempty"`
+	Variants []Variant      `json:"variants,omitempty"`
+
+	Interstitial bool `json:"interstitial,omitempty"`
+
+	QueryPolicy string `json:"query_policy,omitempty"` // ignore (default), merge or override
+	UTM         *UTM   `json:"utm,omitempty"`
+
+	Title string   `json:"title,omitempty"`
+	Notes string   `json:"notes,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+
+	// QR asks for a PNG QR code of the short URL in the response, as a data URI.
+	QR bool `json:"qr,omitempty"`
+
+	// Owner and Tier are set from the caller's API key, never from the request body.
+	Owner string `json:"-"`
+	Tier  string `json:"-"`
 }
 
 type ShortenResponse struct {
+	Domain    string `json:"domain,omitempty"`
 	ShortCode string `json:"short_code"`
 	ShortURL  string `json:"short_url"`
 	LongURL   string `json:"long_url"`
+	Protected bool   `json:"protected,omitempty"`
+	MaxClicks *int   `json:"max_clicks,omitempty"`
+	QRCode    string `json:"qr_code,omitempty"`  // data:image/png;base64,... when requested
+	Existing  bool   `json:"existing,omitempty"` // an identical link was returned instead of a new one
+}
+
+// Variant is one weighted destination of a split link.
+type Variant struct {
+	ID          int64  `json:"id,omitempty" db:"id"`
+	Name        string `json:"name" db:"name"`
+	Destination string `json:"destination" db:"destination"`
+	Weight      int    `json:"weight" db:"weight"`
+}
+
+type Click struct {
+	URLID     int64     `json:"url_id" db:"url_id"`
+	ShortCode string    `json:"short_code" db:"short_code"`
+	VariantID *int64    `json:"variant_id,omitempty" db:"variant_id"`
+	ClickedAt time.Time `json:"clicked_at" db:"clicked_at"`
+	IPAddress string    `json:"ip_address,omitempty" db:"ip_address"`
+	UserAgent string    `json:"user_agent,omitempty" db:"user_agent"`
+	Referer   string    `json:"referer,omitempty" db:"referer"`
+}
+
+type ClickStats struct {
+	ShortCode      string         `json:"short_code"`
+	TotalClicks    int64          `json:"total_clicks"`
+	UniqueVisitors int64          `json:"unique_visitors"`
+	FirstClickAt   *time.Time     `json:"first_click_at,omitempty"`
+	LastClickAt    *time.Time     `json:"last_click_at,omitempty"`
+	Daily          []DailyClicks  `json:"daily,omitempty"`
+	Variants       []VariantStats `json:"variants,omitempty"`
+}
+
+// VariantStats reports how many click-throughs (conversions from the short link
+// to that destination) each variant of a split link received.
+type VariantStats struct {
+	VariantID      int64   `json:"variant_id"`
+	Name           string  `json:"name"`
+	Destination    string  `json:"destination"`
+	Weight         int     `json:"weight"`
+	Clicks         int64   `json:"clicks"`
+	UniqueVisitors int64   `json:"unique_visitors"`
+	Share          float64 `json:"share"` // fraction of all variant clicks
+}
+
+type DailyClicks struct {
+	Day    time.Time `json:"day"`
+	Clicks int64     `json:"clicks"`
+}
+
+// RedirectRule sends visitors matching every set condition to Destination.
+// Conditions left empty match everyone.
+type RedirectRule struct {
+	Devices     []string    `json:"devices,omitempty"`   // "ios", "android", "mobile", "desktop"
+	Languages   []string    `json:"languages,omitempty"` // e.g. "de", "pt-BR"; "de" also matches "de-AT"
+	Countries   []string    `json:"countries,omitempty"` // ISO 3166-1 alpha-2, resolved by GeoIP
+	TimeWindow  *TimeWindow `json:"time_window,omitempty"`
+	Destination string      `json:"destination"`
+}
+
+// TimeWindow matches requests inside an absolute range and/or a recurring daily window.
+type TimeWindow struct {
+	Start    *time.Time `json:"start,omitempty"`
+	End      *time.Time `json:"end,omitempty"`
+	Days     []string   `json:"days,omitempty"`     // "mon" ... "sun"
+	From     string     `json:"from,omitempty"`     // "HH:MM", inclusive
+	To       string     `json:"to,omitempty"`       // "HH:MM", exclusive; may wrap past midnight
+	Timezone string     `json:"timezone,omitempty"` // IANA name for Days/From/To, default UTC
+}
+
+// AbuseReport is a visitor's complaint about a link.
+type AbuseReport struct {
+	URLID      int64     `json:"-"`
+	Reason     string    `json:"reason"` // one of the Report* constants
+	Details    string    `json:"details,omitempty"`
+	ReporterIP string    `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Abuse report reasons.
+const (
+	ReportPhishing = "phishing"
+	ReportMalware  = "malware"
+	ReportSpam     = "spam"
+	ReportOther    = "other"
+)
+
+// LinkPreview describes a link without following it (GET /{code}+).
+// Destination is empty for password-protected links.
+type LinkPreview struct {
+	Domain       string     `json:"domain,omitempty"`
+	ShortCode    string     `json:"short_code"`
+	ShortURL     string     `json:"short_url"`
+	Destination  string     `json:"destination,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	Clicks       int64      `json:"clicks"`
+	Protected    bool       `json:"protected"`
+	Quarantined  bool       `json:"quarantined,omitempty"`
+	Interstitial bool       `json:"interstitial,omitempty"`
 }
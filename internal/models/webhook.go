@@ -0,0 +1,109 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// APIKey identifies an integrator. Links created with a key are owned by the
+// key's name, and the key's webhook subscriptions receive their events.
+type APIKey struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Tier      string    `json:"tier,omitempty"` // selects the custom code length limit
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Webhook event types.
+const (
+	EventLinkCreated = "link.created"
+	EventLinkUpdated = "link.updated"
+	EventLinkDeleted = "link.deleted"
+	EventLinkExpired = "link.expired"
+	EventLinkClicked = "link.clicked" // opt-in, one event per redirect
+)
+
+// LifecycleEvents are the events a subscription receives when it names none.
+var LifecycleEvents = []string{EventLinkCreated, EventLinkUpdated, EventLinkDeleted, EventLinkExpired}
+
+// WebhookSubscription sends an API key's link events to a URL.
+type WebhookSubscription struct {
+	ID        int64     `json:"id"`
+	APIKeyID  int64     `json:"-"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"` // only returned when the subscription is created
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookSubscriptionRequest is the body of POST /api/webhooks.
+type WebhookSubscriptionRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events,omitempty"`
+}
+
+// WebhookEvent is the JSON body of a webhook delivery.
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Link      *URL        `json:"link,omitempty"`
+	Click     *ClickEvent `json:"click,omitempty"`
+}
+
+// ClickEvent describes a redirect without the visitor's IP or user agent.
+type ClickEvent struct {
+	ShortCode string    `json:"short_code"`
+	VariantID *int64    `json:"variant_id,omitempty"`
+	ClickedAt time.Time `json:"clicked_at"`
+	Referer   string    `json:"referer,omitempty"`
+}
+
+// NewLinkEvent builds a lifecycle event for u. The password hash is left out.
+func NewLinkEvent(eventType string, u *URL) *WebhookEvent {
+	link := *u
+	link.PasswordHash = ""
+	return &WebhookEvent{ID: newEventID(), Type: eventType, CreatedAt: time.Now().UTC(), Link: &link}
+}
+
+// NewClickEvent builds a link.clicked event for c.
+func NewClickEvent(c *Click) *WebhookEvent {
+	return &WebhookEvent{
+		ID:        newEventID(),
+		Type:      EventLinkClicked,
+		CreatedAt: time.Now().UTC(),
+		Click:     &ClickEvent{ShortCode: c.ShortCode, VariantID: c.VariantID, ClickedAt: c.ClickedAt, Referer: c.Referer},
+	}
+}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return "evt_" + hex.EncodeToString(b)
+}
+
+// Webhook delivery statuses.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead" // gave up after the last retry; can be replayed
+)
+
+// WebhookDelivery is one event queued for one subscription (a webhook_outbox row).
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionID int64           `json:"subscription_id"`
+	URL            string          `json:"url"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	LastError      string          `json:"last_error,omitempty"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	Payload        json.RawMessage `json:"payload,omitempty"`
+	Secret         string          `json:"-"`
+}
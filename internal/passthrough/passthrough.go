@@ -0,0 +1,174 @@
+// Package passthrough rewrites the query string of a redirect destination: it
+// appends a link's default UTM parameters and forwards parameters from the short
+// URL according to the link's query policy.
+//
+// Only the query component is ever touched. The destination's scheme, host and
+// path come from the stored link, so no incoming parameter can send a visitor
+// somewhere the link owner didn't choose.
+package passthrough
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+	"github.com/Siddarth2230/url-shortener/internal/urlnorm"
+)
+
+// Limits on forwarded and default parameters.
+const (
+	MaxForwarded = 50  // incoming parameters forwarded per redirect
+	MaxUTMLength = 200 // characters per UTM value
+)
+
+// Reserved are short URL parameters the shortener consumes itself; they are never forwarded.
+var Reserved = map[string]bool{"confirm": true}
+
+// Validate checks a link's query policy and UTM parameters.
+func Validate(policy string, utm *models.UTM) error {
+	switch policy {
+	case "", models.QueryIgnore, models.QueryMerge, models.QueryOverride:
+	default:
+		return fmt.Errorf("query_policy must be %s, %s or %s", models.QueryIgnore, models.QueryMerge, models.QueryOverride)
+	}
+	for _, p := range utm.Params() {
+		if len(p[1]) > MaxUTMLength {
+			return fmt.Errorf("%s is longer than %d characters", p[0], MaxUTMLength)
+		}
+		if strings.IndexFunc(p[1], func(r rune) bool { return r < 0x20 || r == 0x7f }) >= 0 {
+			return fmt.Errorf("%s contains control characters", p[0])
+		}
+	}
+	return nil
+}
+
+// Apply returns dest with the UTM defaults and, per policy, the incoming
+// parameters added to its query. Parameters the destination already sets keep
+// their position; added ones are appended. A destination that can't be parsed,
+// or a result longer than urlnorm.MaxLength, falls back to the UTM-only rewrite
+// or dest itself.
+func Apply(dest string, incoming url.Values, policy string, utm *models.UTM) string {
+	u, err := url.Parse(dest)
+	if err != nil {
+		return dest
+	}
+	q := parseQuery(u.RawQuery)
+
+	for _, p := range utm.Params() {
+		if !q.has(p[0]) {
+			q.add(p[0], p[1])
+		}
+	}
+	withUTM := q.encode()
+
+	if policy == models.QueryMerge || policy == models.QueryOverride {
+		forwarded := 0
+		for _, name := range sortedKeys(incoming) {
+			if Reserved[name] || forwarded >= MaxForwarded {
+				continue
+			}
+			if policy == models.QueryMerge && q.has(name) {
+				continue
+			}
+			values := safeValues(incoming[name])
+			if len(values) == 0 {
+				continue
+			}
+			q.remove(name)
+			for _, v := range values {
+				q.add(name, v)
+			}
+			forwarded++
+		}
+	}
+
+	u.RawQuery = q.encode()
+	if out := u.String(); len(out) <= urlnorm.MaxLength {
+		return out
+	}
+	u.RawQuery = withUTM
+	if out := u.String(); len(out) <= urlnorm.MaxLength {
+		return out
+	}
+	return dest
+}
+
+// safeValues drops values that look like URLs. Destinations often treat
+// parameters such as next= or redirect= as a place to send the visitor, and
+// forwarding those would turn a trusted short link into an open redirect.
+func safeValues(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if !looksLikeURL(v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func looksLikeURL(v string) bool {
+	s := strings.ToLower(strings.TrimSpace(v))
+	s = strings.ReplaceAll(s, `\`, "/")
+	return strings.Contains(s, "://") || strings.HasPrefix(s, "//") ||
+		strings.HasPrefix(s, "javascript:") || strings.HasPrefix(s, "data:") || strings.HasPrefix(s, "vbscript:")
+}
+
+func sortedKeys(v url.Values) []string {
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// query is an ordered list of raw (still encoded) name=value pairs, so the
+// destination's own parameters keep their order and spelling.
+type query []string
+
+func parseQuery(raw string) query {
+	var q query
+	for _, p := range strings.Split(raw, "&") {
+		if p != "" {
+			q = append(q, p)
+		}
+	}
+	return q
+}
+
+func pairName(p string) string {
+	name, _, _ := strings.Cut(p, "=")
+	if n, err := url.QueryUnescape(name); err == nil {
+		return n
+	}
+	return name
+}
+
+func (q query) has(name string) bool {
+	for _, p := range q {
+		if pairName(p) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *query) add(name, value string) {
+	*q = append(*q, url.QueryEscape(name)+"="+url.QueryEscape(value))
+}
+
+func (q *query) remove(name string) {
+	kept := (*q)[:0]
+	for _, p := range *q {
+		if pairName(p) != name {
+			kept = append(kept, p)
+		}
+	}
+	*q = kept
+}
+
+func (q query) encode() string {
+	return strings.Join(q, "&")
+}
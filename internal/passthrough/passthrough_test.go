@@ -0,0 +1,99 @@
+package passthrough
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+func TestApply(t *testing.T) {
+	utm := &models.UTM{Source: "newsletter", Campaign: "spring sale"}
+	tests := []struct {
+		name     string
+		dest     string
+		incoming string
+		policy   string
+		utm      *models.UTM
+		want     string
+	}{
+		{"ignore drops incoming", "https://example.com/p?a=1", "b=2", models.QueryIgnore, nil, "https://example.com/p?a=1"},
+		{"default policy ignores", "https://example.com/p", "b=2", "", nil, "https://example.com/p"},
+		{"merge adds new", "https://example.com/p?a=1", "b=2", models.QueryMerge, nil, "https://example.com/p?a=1&b=2"},
+		{"merge keeps destination", "https://example.com/p?a=1", "a=9&b=2", models.QueryMerge, nil, "https://example.com/p?a=1&b=2"},
+		{"override replaces", "https://example.com/p?a=1&c=3", "a=9&a=8", models.QueryOverride, nil, "https://example.com/p?c=3&a=9&a=8"},
+		{"confirm is reserved", "https://example.com/", "confirm=1&x=y", models.QueryMerge, nil, "https://example.com/?x=y"},
+		{"fragment kept", "https://example.com/p#top", "b=2", models.QueryMerge, nil, "https://example.com/p?b=2#top"},
+		{"encoding", "https://example.com/", "q=a b&&x", models.QueryMerge, nil, "https://example.com/?q=a+b&x="},
+
+		{"utm appended", "https://example.com/p?a=1", "", models.QueryIgnore, utm, "https://example.com/p?a=1&utm_source=newsletter&utm_campaign=spring+sale"},
+		{"utm yields to destination", "https://example.com/?utm_source=site", "", "", utm, "https://example.com/?utm_source=site&utm_campaign=spring+sale"},
+		{"merge yields to utm", "https://example.com/", "utm_source=x", models.QueryMerge, &models.UTM{Source: "mail"}, "https://example.com/?utm_source=mail"},
+		{"override beats utm", "https://example.com/", "utm_source=x", models.QueryOverride, &models.UTM{Source: "mail"}, "https://example.com/?utm_source=x"},
+
+		{"url values dropped", "https://example.com/login", "next=https://evil.example&ok=1", models.QueryOverride, nil, "https://example.com/login?ok=1"},
+		{"scheme-relative dropped", "https://example.com/", "r=//evil.example&r2=%5C%5Cevil.example", models.QueryMerge, nil, "https://example.com/"},
+		{"javascript dropped", "https://example.com/", "u=JavaScript:alert(1)", models.QueryMerge, nil, "https://example.com/"},
+	}
+	for _, tt := range tests {
+		incoming, err := url.ParseQuery(tt.incoming)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := Apply(tt.dest, incoming, tt.policy, tt.utm); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestApplyKeepsHost(t *testing.T) {
+	// Nothing in the query can reach the authority or path of the destination
+	incoming := url.Values{"@evil.example": {"1"}, "#": {"x"}, "/../admin": {"1"}, "host": {"evil.example"}}
+	got := Apply("https://example.com/p", incoming, models.QueryOverride, nil)
+	u, err := url.Parse(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Host != "example.com" || u.Path != "/p" || u.Fragment != "" {
+		t.Errorf("Apply = %q: host %q path %q fragment %q", got, u.Host, u.Path, u.Fragment)
+	}
+	if u.Query().Get("@evil.example") != "1" {
+		t.Errorf("Apply = %q: parameter not forwarded as a parameter", got)
+	}
+}
+
+func TestApplyLimits(t *testing.T) {
+	incoming := url.Values{}
+	for i := 0; i < MaxForwarded+10; i++ {
+		incoming.Set("p"+strings.Repeat("x", i), "1")
+	}
+	got, _ := url.Parse(Apply("https://example.com/", incoming, models.QueryMerge, nil))
+	if n := len(got.Query()); n != MaxForwarded {
+		t.Errorf("forwarded %d parameters, want %d", n, MaxForwarded)
+	}
+
+	// Too long with the forwarded parameters: keep only the UTM defaults
+	long := url.Values{"big": {strings.Repeat("a", 3000)}}
+	if got := Apply("https://example.com/", long, models.QueryMerge, &models.UTM{Medium: "qr"}); got != "https://example.com/?utm_medium=qr" {
+		t.Errorf("oversized: got %.60q", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate("", nil); err != nil {
+		t.Errorf("defaults: %v", err)
+	}
+	if err := Validate(models.QueryOverride, &models.UTM{Source: "x"}); err != nil {
+		t.Errorf("override: %v", err)
+	}
+	if err := Validate("append", nil); err == nil {
+		t.Error("unknown policy: want error")
+	}
+	if err := Validate("", &models.UTM{Term: strings.Repeat("a", MaxUTMLength+1)}); err == nil {
+		t.Error("long UTM value: want error")
+	}
+	if err := Validate("", &models.UTM{Content: "a\nb"}); err == nil {
+		t.Error("control character: want error")
+	}
+}
@@ -0,0 +1,279 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+// SkippedLink explains why BulkInsert didn't insert a link.
+type SkippedLink struct {
+	// LongURL is what the code already points at, empty if the row appeared
+	// concurrently or the code is cooling down.
+	LongURL string
+	// AvailableAt is set for codes cooling down after a delete or sweep.
+	AvailableAt *time.Time
+}
+
+// BulkInsert loads a batch of URLs with COPY, preserving their short codes.
+//
+// Rows are copied into a temporary staging table first and then moved into urls
+// with ON CONFLICT DO NOTHING, so one taken code doesn't abort the whole batch.
+// The returned map holds every link that was NOT inserted.
+func (r *URLRepository) BulkInsert(ctx context.Context, urls []*models.URL) (map[models.LinkKey]SkippedLink, error) {
+	ctx, span := r.span(ctx, "BulkInsert")
+	defer span.End()
+
+	if len(urls) == 0 {
+		return nil, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+        CREATE TEMP TABLE import_staging (
+            domain TEXT,
+            short_code VARCHAR(32),
+            long_url TEXT,
+            created_at TIMESTAMP WITH TIME ZONE,
+            expires_at TIMESTAMP WITH TIME ZONE,
+            owner TEXT,
+            password_hash TEXT,
+            title TEXT,
+            notes TEXT,
+            tags TEXT[]
+        ) ON COMMIT DROP
+	`); err != nil {
+		return nil, fmt.Errorf("create staging table: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("import_staging",
+		"domain", "short_code", "long_url", "created_at", "expires_at", "owner", "password_hash", "title", "notes", "tags"))
+	if err != nil {
+		return nil, fmt.Errorf("prepare copy: %w", err)
+	}
+	for _, u := range urls {
+		var expires_at sql.NullTime
+		if u.ExpiresAt != nil {
+			expires_at = sql.NullTime{Time: *u.ExpiresAt, Valid: true}
+		}
+		tags := u.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		if _, err := stmt.ExecContext(ctx, u.Domain, u.ShortCode, u.LongURL, u.CreatedAt, expires_at, nullString(u.Owner), nullString(u.PasswordHash), nullString(u.Title), nullString(u.Notes), pq.Array(tags)); err != nil {
+			stmt.Close()
+			return nil, fmt.Errorf("copy row %q: %w", u.ShortCode, err)
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		stmt.Close()
+		return nil, fmt.Errorf("flush copy: %w", err)
+	}
+	if err := stmt.Close(); err != nil {
+		return nil, err
+	}
+
+	// Codes that already exist can't be imported; report what they point at.
+	// Codes still cooling down after a delete or sweep report when they're free.
+	skipped := make(map[models.LinkKey]SkippedLink)
+	existing := `
+        SELECT s.domain, s.short_code, u.long_url, NULL::timestamptz
+        FROM import_staging s
+        JOIN urls u ON u.domain = s.domain AND ` + r.codeEq("u.short_code", "s.short_code") + `
+        UNION ALL
+        SELECT s.domain, s.short_code, '', t.available_at
+        FROM import_staging s
+        JOIN code_tombstones t ON t.domain = s.domain AND ` + r.codeEq("t.short_code", "s.short_code") + `
+        WHERE t.available_at > NOW()
+	`
+	rows, err := tx.QueryContext(ctx, existing)
+	if err != nil {
+		return nil, fmt.Errorf("find existing codes: %w", err)
+	}
+	for rows.Next() {
+		var key models.LinkKey
+		var s SkippedLink
+		var availableAt sql.NullTime
+		if err := rows.Scan(&key.Domain, &key.ShortCode, &s.LongURL, &availableAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if availableAt.Valid {
+			s.AvailableAt = &availableAt.Time
+		}
+		skipped[key] = s
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Move the rest into urls. Codes claimed concurrently are silently skipped
+	// by ON CONFLICT (on any unique index, including lower(short_code)) and show
+	// up as missing from RETURNING.
+	insert := `
+        INSERT INTO urls (domain, short_code, long_url, created_at, expires_at, owner, password_hash, title, notes)
+        SELECT s.domain, s.short_code, s.long_url, s.created_at, s.expires_at, s.owner, s.password_hash, s.title, s.notes
+        FROM import_staging s
+        WHERE NOT EXISTS (SELECT 1 FROM urls u WHERE u.domain = s.domain AND ` + r.codeEq("u.short_code", "s.short_code") + `)
+          AND NOT EXISTS (
+              SELECT 1 FROM code_tombstones t
+              WHERE t.domain = s.domain AND ` + r.codeEq("t.short_code", "s.short_code") + ` AND t.available_at > NOW()
+          )
+        ON CONFLICT DO NOTHING
+        RETURNING id, domain, short_code, status
+	`
+	rows, err = tx.QueryContext(ctx, insert)
+	if err != nil {
+		return nil, fmt.Errorf("insert from staging: %w", err)
+	}
+	inserted := make([]int64, 0, len(urls))
+	created := make(map[models.LinkKey]models.URL, len(urls))
+	for rows.Next() {
+		var key models.LinkKey
+		var u models.URL
+		if err := rows.Scan(&u.ID, &key.Domain, &key.ShortCode, &u.Status); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		inserted = append(inserted, u.ID)
+		created[key] = u
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Keep only the rows we actually inserted so tags attach to the right links
+	if _, err := tx.ExecContext(ctx, `
+        DELETE FROM import_staging s
+        WHERE NOT EXISTS (
+            SELECT 1 FROM urls u
+            WHERE u.id = ANY($1) AND u.domain = s.domain AND u.short_code = s.short_code
+        )
+	`, pq.Array(inserted)); err != nil {
+		return nil, fmt.Errorf("prune staging: %w", err)
+	}
+
+	if len(inserted) < len(urls)-len(skipped) {
+		// Some codes were claimed concurrently: whatever is missing from staging now
+		remaining := make(map[models.LinkKey]struct{}, len(inserted))
+		rows, err := tx.QueryContext(ctx, `SELECT domain, short_code FROM import_staging`)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var key models.LinkKey
+			if err := rows.Scan(&key.Domain, &key.ShortCode); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			remaining[key] = struct{}{}
+		}
+		rows.Close()
+		for _, u := range urls {
+			if _, ok := skipped[u.Key()]; ok {
+				continue
+			}
+			if _, ok := remaining[u.Key()]; !ok {
+				skipped[u.Key()] = SkippedLink{}
+			}
+		}
+	}
+
+	if err := insertTagsFromStaging(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	// Owned links get their link.created event in the same transaction, as
+	// they do when created one at a time
+	for _, u := range urls {
+		c, ok := created[u.Key()]
+		if !ok || u.Owner == "" {
+			continue
+		}
+		u.ID, u.Status = c.ID, c.Status
+		if err := insertEvent(ctx, tx, u.Owner, models.NewLinkEvent(models.EventLinkCreated, u)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return skipped, nil
+}
+
+func insertTagsFromStaging(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, `
+        INSERT INTO tags (name)
+        SELECT DISTINCT unnest(tags) FROM import_staging
+        ON CONFLICT (name) DO NOTHING
+	`); err != nil {
+		return fmt.Errorf("insert tags: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+        INSERT INTO url_tags (url_id, tag_id)
+        SELECT u.id, t.id
+        FROM import_staging s
+        JOIN urls u ON u.domain = s.domain AND u.short_code = s.short_code
+        CROSS JOIN LATERAL unnest(s.tags) AS tag(name)
+        JOIN tags t ON t.name = tag.name
+        ON CONFLICT DO NOTHING
+	`); err != nil {
+		return fmt.Errorf("insert url tags: %w", err)
+	}
+	return nil
+}
+
+// ExportURLs streams every URL matching filter to fn, oldest first.
+// Rows are read with a single cursor so memory use stays flat for large tables.
+func (r *URLRepository) ExportURLs(ctx context.Context, filter models.URLFilter, fn func(*models.URL) error) error {
+	ctx, span := r.span(ctx, "ExportURLs")
+	defer span.End()
+
+	var args []interface{}
+	where := filterClauses(filter, func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	})
+
+	query := `
+        SELECT ` + urlColumns + `, ` + tagsColumn + `
+        FROM urls`
+	if len(where) > 0 {
+		query += "\n        WHERE " + strings.Join(where, " AND ")
+	}
+	query += "\n        ORDER BY urls.id"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.log(ctx).Error("export URLs failed", "err", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tags []string
+		url, err := scanURL(rows, pq.Array(&tags))
+		if err != nil {
+			return err
+		}
+		url.Tags = tags
+		if err := fn(url); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
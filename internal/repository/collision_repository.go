@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+// FindCaseCollisions returns the codes that would clash under case-insensitive
+// matching, i.e. what keeps the unique lower(short_code) index from being built.
+func (r *URLRepository) FindCaseCollisions(ctx context.Context) ([]*models.CaseCollision, error) {
+	ctx, span := r.span(ctx, "FindCaseCollisions")
+	defer span.End()
+
+	query := `
+        SELECT domain, lower(short_code),
+               array_agg(short_code ORDER BY created_at, id),
+               array_agg(long_url ORDER BY created_at, id)
+        FROM urls
+        GROUP BY domain, lower(short_code)
+        HAVING COUNT(*) > 1
+        ORDER BY domain, lower(short_code)
+	`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.log(ctx).Error("find case collisions failed", "err", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var collisions []*models.CaseCollision
+	for rows.Next() {
+		var c models.CaseCollision
+		if err := rows.Scan(&c.Domain, &c.Folded, pq.Array(&c.Codes), pq.Array(&c.LongURLs)); err != nil {
+			return nil, err
+		}
+		collisions = append(collisions, &c)
+	}
+	return collisions, rows.Err()
+}
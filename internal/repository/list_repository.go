@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+// ErrInvalidCursor is returned for cursors that weren't produced by ListURLs
+// with the same sort order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// tagsColumn aggregates a link's tag names into a sorted array. Scan it with pq.Array.
+const tagsColumn = `COALESCE((
+            SELECT array_agg(t.name ORDER BY t.name)
+            FROM url_tags ut JOIN tags t ON t.id = ut.tag_id
+            WHERE ut.url_id = urls.id
+        ), '{}')`
+
+// sortKeys maps each sort order to the column it pages on. Every order breaks
+// ties on id so keyset pagination is stable.
+var sortKeys = map[string]struct {
+	expr string
+	desc bool
+}{
+	models.SortNewest:    {"urls.created_at", true},
+	models.SortOldest:    {"urls.created_at", false},
+	models.SortCode:      {"urls.short_code", false},
+	models.SortCodeDesc:  {"urls.short_code", true},
+	models.SortTitle:     {"COALESCE(urls.title, '')", false},
+	models.SortTitleDesc: {"COALESCE(urls.title, '')", true},
+}
+
+// ValidSort reports whether sort is a supported ListOptions.Sort value.
+func ValidSort(sort string) bool {
+	_, ok := sortKeys[sort]
+	return ok
+}
+
+// cursor is the position after the last row of a page. It is opaque to clients.
+type cursor struct {
+	Sort string `json:"s"`
+	Key  string `json:"k"`
+	ID   int64  `json:"i"`
+}
+
+// ListURLs returns one page of links matching opts, using keyset pagination so
+// deep pages stay as cheap as the first one.
+func (r *URLRepository) ListURLs(ctx context.Context, opts models.ListOptions) (*models.URLPage, error) {
+	ctx, span := r.span(ctx, "ListURLs")
+	defer span.End()
+
+	sortKey, ok := sortKeys[opts.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort %q", opts.Sort)
+	}
+
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	where := filterClauses(opts.Filter, arg)
+
+	if opts.Query != "" {
+		// Stemmed match on titles, literal words in destinations
+		q := arg(opts.Query)
+		where = append(where, fmt.Sprintf(
+			"urls.search_vector @@ (websearch_to_tsquery('english', %s) || websearch_to_tsquery('simple', %s))", q, q))
+	}
+
+	if opts.Cursor != "" {
+		c, err := decodeCursor(opts.Cursor)
+		if err != nil || c.Sort != opts.Sort {
+			return nil, ErrInvalidCursor
+		}
+		var key interface{} = c.Key
+		if sortKey.expr == "urls.created_at" {
+			t, err := time.Parse(time.RFC3339Nano, c.Key)
+			if err != nil {
+				return nil, ErrInvalidCursor
+			}
+			key = t
+		}
+		op := ">"
+		if sortKey.desc {
+			op = "<"
+		}
+		where = append(where, fmt.Sprintf("(%s, urls.id) %s (%s, %s)", sortKey.expr, op, arg(key), arg(c.ID)))
+	}
+
+	dir := "ASC"
+	if sortKey.desc {
+		dir = "DESC"
+	}
+	query := `
+        SELECT ` + urlColumns + `, ` + tagsColumn + `
+        FROM urls`
+	if len(where) > 0 {
+		query += "\n        WHERE " + strings.Join(where, " AND ")
+	}
+	// Fetch one extra row to learn whether there is a next page
+	query += fmt.Sprintf("\n        ORDER BY %s %s, urls.id %s\n        LIMIT %s", sortKey.expr, dir, dir, arg(opts.Limit+1))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.log(ctx).Error("list URLs failed", "err", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &models.URLPage{Links: []*models.URL{}}
+	for rows.Next() {
+		var tags []string
+		url, err := scanURL(rows, pq.Array(&tags))
+		if err != nil {
+			return nil, err
+		}
+		url.Tags = tags
+		page.Links = append(page.Links, url)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Links) > opts.Limit {
+		page.Links = page.Links[:opts.Limit]
+		last := page.Links[len(page.Links)-1]
+		c := cursor{Sort: opts.Sort, ID: last.ID}
+		switch sortKey.expr {
+		case "urls.created_at":
+			c.Key = last.CreatedAt.UTC().Format(time.RFC3339Nano)
+		case "urls.short_code":
+			c.Key = last.ShortCode
+		default:
+			c.Key = last.Title
+		}
+		page.NextCursor = encodeCursor(c)
+	}
+	return page, nil
+}
+
+// filterClauses turns a URLFilter into WHERE conditions on urls, registering
+// parameters through arg.
+func filterClauses(filter models.URLFilter, arg func(interface{}) string) []string {
+	var where []string
+	if filter.Domain != nil {
+		where = append(where, "urls.domain = "+arg(*filter.Domain))
+	}
+	if filter.Owner != "" {
+		where = append(where, "urls.owner = "+arg(filter.Owner))
+	}
+	if filter.Tag != "" {
+		where = append(where, `EXISTS (
+            SELECT 1 FROM url_tags ut JOIN tags t ON t.id = ut.tag_id
+            WHERE ut.url_id = urls.id AND t.name = `+arg(filter.Tag)+`)`)
+	}
+	if filter.CreatedAfter != nil {
+		where = append(where, "urls.created_at >= "+arg(*filter.CreatedAfter))
+	}
+	if filter.CreatedBefore != nil {
+		where = append(where, "urls.created_at < "+arg(*filter.CreatedBefore))
+	}
+	switch filter.Expiry {
+	case models.ExpiryActive:
+		where = append(where, "(urls.expires_at IS NULL OR urls.expires_at > NOW())")
+	case models.ExpiryExpired:
+		where = append(where, "urls.expires_at <= NOW()")
+	}
+	return where
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(data, &c)
+	return c, err
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+// SaveReport stores an abuse report and returns how many distinct reporters
+// have flagged the link since its status last changed. Repeat reports from the
+// same IP are ignored, so one visitor can't push a link into quarantine.
+func (r *URLRepository) SaveReport(ctx context.Context, report *models.AbuseReport) (int, error) {
+	ctx, span := r.span(ctx, "SaveReport")
+	defer span.End()
+
+	query := `
+        INSERT INTO abuse_reports (url_id, reason, details, reporter_ip, created_at)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (url_id, reporter_ip) DO NOTHING
+	`
+	if _, err := r.db.ExecContext(ctx, query, report.URLID, report.Reason, nullString(report.Details), report.ReporterIP, report.CreatedAt); err != nil {
+		r.log(ctx).Error("save abuse report failed", "url_id", report.URLID, "err", err)
+		return 0, err
+	}
+
+	countQuery := `
+        SELECT COUNT(*)
+        FROM abuse_reports a JOIN urls u ON u.id = a.url_id
+        WHERE a.url_id = $1 AND (u.status_changed_at IS NULL OR a.created_at > u.status_changed_at)
+	`
+	var n int
+	if err := r.db.QueryRowContext(ctx, countQuery, report.URLID).Scan(&n); err != nil {
+		r.log(ctx).Error("count abuse reports failed", "url_id", report.URLID, "err", err)
+		return 0, err
+	}
+	return n, nil
+}
+
+// Quarantine moves an active link into quarantine. It returns false when the
+// link is no longer active (already quarantined, disabled or deleted), so
+// concurrent reports quarantine it only once.
+func (r *URLRepository) Quarantine(ctx context.Context, urlID int64, reason string) (bool, error) {
+	ctx, span := r.span(ctx, "Quarantine")
+	defer span.End()
+
+	query := `
+        UPDATE urls SET status = 'quarantined', status_reason = $2, status_changed_at = NOW()
+        WHERE id = $1 AND status = 'active'
+        RETURNING ` + urlColumns + `
+	`
+	url, err := r.updateReturning(ctx, models.EventLinkUpdated, query, urlID, reason)
+	if err != nil {
+		r.log(ctx).Error("quarantine URL failed", "url_id", urlID, "err", err)
+		return false, err
+	}
+	return url != nil, nil
+}
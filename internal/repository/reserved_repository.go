@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+// AddReservedWord adds or updates an entry of the reserved list.
+func (r *URLRepository) AddReservedWord(ctx context.Context, w *models.ReservedWord) error {
+	ctx, span := r.span(ctx, "AddReservedWord")
+	defer span.End()
+
+	query := `
+        INSERT INTO reserved_codes (word, kind, note) VALUES ($1, $2, $3)
+        ON CONFLICT (word) DO UPDATE SET kind = EXCLUDED.kind, note = EXCLUDED.note
+        RETURNING created_at
+	`
+	if err := r.db.QueryRowContext(ctx, query, w.Word, w.Kind, nullString(w.Note)).Scan(&w.CreatedAt); err != nil {
+		r.log(ctx).Error("add reserved word failed", "word", w.Word, "err", err)
+		return err
+	}
+	return nil
+}
+
+// RemoveReservedWord deletes an entry. It returns false when there was none.
+func (r *URLRepository) RemoveReservedWord(ctx context.Context, word string) (bool, error) {
+	ctx, span := r.span(ctx, "RemoveReservedWord")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM reserved_codes WHERE word = $1`, word)
+	if err != nil {
+		r.log(ctx).Error("remove reserved word failed", "word", word, "err", err)
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// ListReservedWords returns the reserved list ordered by word.
+func (r *URLRepository) ListReservedWords(ctx context.Context) ([]*models.ReservedWord, error) {
+	ctx, span := r.span(ctx, "ListReservedWords")
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT word, kind, note, created_at FROM reserved_codes ORDER BY word`)
+	if err != nil {
+		r.log(ctx).Error("list reserved words failed", "err", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var words []*models.ReservedWord
+	for rows.Next() {
+		var w models.ReservedWord
+		var note sql.NullString
+		if err := rows.Scan(&w.Word, &w.Kind, &note, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		w.Note = note.String
+		words = append(words, &w)
+	}
+	return words, rows.Err()
+}
+
+// MatchReservedWord returns the entry that rules out a code, or nil. Reserved
+// words match the lowercased code; blocked words match anywhere in one of its
+// folded variants.
+func (r *URLRepository) MatchReservedWord(ctx context.Context, code string, variants []string) (*models.ReservedWord, error) {
+	ctx, span := r.span(ctx, "MatchReservedWord")
+	defer span.End()
+
+	query := `
+        SELECT word, kind FROM reserved_codes
+        WHERE (kind = 'reserved' AND word = lower($1))
+           OR (kind = 'blocked' AND EXISTS (SELECT 1 FROM unnest($2::text[]) v WHERE strpos(v, word) > 0))
+        LIMIT 1
+	`
+	var w models.ReservedWord
+	if err := r.db.QueryRowContext(ctx, query, code, pq.Array(variants)).Scan(&w.Word, &w.Kind); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		r.log(ctx).Error("match reserved words failed", "err", err)
+		return nil, err
+	}
+	return &w, nil
+}
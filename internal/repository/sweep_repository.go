@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+// sweepLockKey is the advisory lock that keeps expiry sweeps on different
+// replicas from running at the same time ("urlswep" in ASCII).
+const sweepLockKey int64 = 0x75726c73776570
+
+// SweepExpired removes up to limit links that expired more than grace ago,
+// copying them to urls_archive first when archive is set, and queues a
+// link.expired event for each. Their codes stay unclaimable until cooldown
+// after they expired. It returns the removed links, or ok=false without
+// touching anything when another sweep holds the advisory lock.
+func (r *URLRepository) SweepExpired(ctx context.Context, grace time.Duration, limit int, archive bool, cooldown time.Duration) (swept []*models.URL, ok bool, err error) {
+	ctx, span := r.span(ctx, "SweepExpired")
+	defer span.End()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	// Held until the transaction ends, so a crashed sweeper can't keep it
+	if err := tx.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock($1)`, sweepLockKey).Scan(&ok); err != nil || !ok {
+		return nil, false, err
+	}
+
+	archiveCTE := ""
+	if archive {
+		// Sub-statements share one snapshot, so the click count still sees
+		// the rows the delete cascades to
+		archiveCTE = `, archived AS (
+            INSERT INTO urls_archive (id, domain, short_code, long_url, owner, created_at, expires_at, clicks, snapshot)
+            SELECT m.id, m.domain, m.short_code, m.long_url, m.owner, m.created_at, m.expires_at,
+                   (SELECT COUNT(*) FROM clicks c WHERE c.url_id = m.id),
+                   to_jsonb(m) - 'search_vector'
+            FROM moved m
+            ON CONFLICT (id) DO NOTHING
+        )`
+	}
+	query := `
+        WITH expired AS (
+            SELECT id FROM urls
+            WHERE expires_at < NOW() - make_interval(secs => $1)
+            ORDER BY expires_at
+            LIMIT $2
+            FOR UPDATE SKIP LOCKED
+        ), moved AS (
+            DELETE FROM urls u USING expired e
+            WHERE u.id = e.id
+            RETURNING u.*
+        )` + archiveCTE + `
+        SELECT ` + urlColumns + ` FROM moved
+	`
+	rows, err := tx.QueryContext(ctx, query, grace.Seconds(), limit)
+	if err != nil {
+		r.log(ctx).Error("sweep expired links failed", "err", err)
+		return nil, true, err
+	}
+	for rows.Next() {
+		url, err := scanURL(rows)
+		if err != nil {
+			rows.Close()
+			return nil, true, err
+		}
+		swept = append(swept, url)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, true, err
+	}
+
+	for _, url := range swept {
+		// Swept links always have an expiry; count the cooldown from it
+		if err := insertTombstone(ctx, tx, url.Domain, url.ShortCode, TombstoneExpired, url.ExpiresAt.Add(cooldown)); err != nil {
+			return nil, true, err
+		}
+		if err := insertEvent(ctx, tx, url.Owner, models.NewLinkEvent(models.EventLinkExpired, url)); err != nil {
+			return nil, true, err
+		}
+	}
+	if err := pruneTombstones(ctx, tx); err != nil {
+		return nil, true, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, true, err
+	}
+	return swept, true, nil
+}
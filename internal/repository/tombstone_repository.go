@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Siddarth2230/url-shortener/pkg/logging"
+)
+
+// Reasons a short code was released.
+const (
+	TombstoneDeleted = "deleted"
+	TombstoneExpired = "expired"
+)
+
+// insertTombstone records that a code was released and can't be claimed again
+// until availableAt. A code released twice keeps the later availability and
+// the time of its first release.
+func insertTombstone(ctx context.Context, q queryer, domain, shortCode, reason string, availableAt time.Time) error {
+	query := `
+        INSERT INTO code_tombstones (domain, short_code, reason, available_at)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (domain, short_code) DO UPDATE
+        SET reason = EXCLUDED.reason,
+            available_at = GREATEST(code_tombstones.available_at, EXCLUDED.available_at)
+	`
+	if _, err := q.ExecContext(ctx, query, domain, shortCode, reason, availableAt); err != nil {
+		logging.FromContext(ctx, nil).Error("write tombstone failed", "code", shortCode, "err", err)
+		return err
+	}
+	return nil
+}
+
+// FindTombstone returns when a released code becomes claimable again, or nil if
+// it isn't cooling down.
+func (r *URLRepository) FindTombstone(ctx context.Context, domain, shortCode string) (*time.Time, error) {
+	ctx, span := r.span(ctx, "FindTombstone")
+	defer span.End()
+
+	query := `
+        SELECT available_at FROM code_tombstones
+        WHERE domain = $1 AND ` + r.codeEq("short_code", "$2") + ` AND available_at > NOW()
+	`
+	var availableAt time.Time
+	if err := r.db.QueryRowContext(ctx, query, domain, shortCode).Scan(&availableAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		r.log(ctx).Error("find tombstone failed", "code", shortCode, "err", err)
+		return nil, err
+	}
+	return &availableAt, nil
+}
+
+// pruneTombstones drops tombstones whose cooldown is over.
+func pruneTombstones(ctx context.Context, q queryer) error {
+	if _, err := q.ExecContext(ctx, `DELETE FROM code_tombstones WHERE available_at <= NOW()`); err != nil {
+		logging.FromContext(ctx, nil).Error("prune tombstones failed", "err", err)
+		return err
+	}
+	return nil
+}
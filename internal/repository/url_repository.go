@@ -3,95 +3,683 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/Siddarth2230/url-shortener/internal/models"
+	"github.com/Siddarth2230/url-shortener/pkg/logging"
 )
 
+// urlColumns is the column list scanURL expects, in order.
+const urlColumns = `id, domain, short_code, long_url, created_at, expires_at, owner, password_hash, max_clicks, clicks_remaining, rules, status, status_reason, interstitial, title, notes, query_policy, utm`
+
+// variantsColumn aggregates a link's split variants into a JSON array, in position order.
+// Select it right after urlColumns and scan with scanURLWithVariants.
+const variantsColumn = `COALESCE((
+            SELECT json_agg(json_build_object('id', v.id, 'name', v.name, 'destination', v.destination, 'weight', v.weight) ORDER BY v.position)
+            FROM url_variants v WHERE v.url_id = urls.id
+        ), '[]')`
+
 type URLRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	logger *slog.Logger
+
+	// CaseInsensitive matches short codes ignoring case. Codes keep the case
+	// they were created with; lookups go through the lower(short_code) index.
+	CaseInsensitive bool
+}
+
+func NewURLRepository(db *sql.DB, logger *slog.Logger) *URLRepository {
+	return &URLRepository{db: db, logger: logger}
+}
+
+// log returns the request logger carried by ctx, or the repository's.
+func (r *URLRepository) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, r.logger)
+}
+
+// codeEq compares a short code column with a value, ignoring case in
+// case-insensitive mode.
+func (r *URLRepository) codeEq(column, value string) string {
+	if r.CaseInsensitive {
+		return "lower(" + column + ") = lower(" + value + ")"
+	}
+	return column + " = " + value
+}
+
+// ErrNotFound is returned by writes to a link that doesn't exist. Reads return
+// a nil link instead.
+var ErrNotFound = errors.New("link not found")
+
+var tracer = otel.Tracer("github.com/Siddarth2230/url-shortener/internal/repository")
+
+// span starts a client span for a repository operation; queries made with the
+// returned context (including inside transactions) belong to it.
+func (r *URLRepository) span(ctx context.Context, op string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "URLRepository."+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system", "postgresql"), attribute.String("db.operation", op)))
 }
 
-func NewURLRepository(db *sql.DB) *URLRepository {
-	return &URLRepository{db: db}
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 }
 
+// Save inserts a link with its variants and tags. Links with an owner also
+// queue a link.created webhook event in the same transaction.
 func (r *URLRepository) Save(ctx context.Context, url *models.URL) error {
+	ctx, span := r.span(ctx, "Save")
+	defer span.End()
+
+	if len(url.Variants) == 0 && len(url.Tags) == 0 && url.Owner == "" {
+		return insertURL(ctx, r.db, url)
+	}
+
+	// Write the link with its variants, tags and event atomically
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertURL(ctx, tx, url); err != nil {
+		return err
+	}
+	if err := insertVariants(ctx, tx, url); err != nil {
+		return err
+	}
+	if err := insertTags(ctx, tx, url); err != nil {
+		return err
+	}
+	if err := insertEvent(ctx, tx, url.Owner, models.NewLinkEvent(models.EventLinkCreated, url)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertURL(ctx context.Context, q queryer, url *models.URL) error {
 	query := `
-        INSERT INTO urls (short_code, long_url, created_at, expires_at)
-        VALUES ($1, $2, $3, $4)
-        RETURNING id
+        INSERT INTO urls (domain, short_code, long_url, created_at, expires_at, owner, password_hash, max_clicks, clicks_remaining, rules, interstitial, title, notes, query_policy, utm)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8, $9, $10, $11, $12, $13, $14)
+        RETURNING id, status
     `
+	rules, err := marshalRules(url.Rules)
+	if err != nil {
+		return err
+	}
+	utm, err := marshalUTM(url.UTM)
+	if err != nil {
+		return err
+	}
+	policy := url.QueryPolicy
+	if policy == "" {
+		policy = models.QueryIgnore
+	}
 	var expires_at sql.NullTime
 	if url.ExpiresAt != nil {
 		expires_at = sql.NullTime{Time: *url.ExpiresAt, Valid: true}
 	} else {
 		expires_at = sql.NullTime{Valid: false}
 	}
-	row := r.db.QueryRowContext(ctx, query, url.ShortCode, url.LongURL, url.CreatedAt, expires_at)
-	if err := row.Scan(&url.ID); err != nil {
-		log.Printf("Error saving URL: %v", err)
+	row := q.QueryRowContext(ctx, query, url.Domain, url.ShortCode, url.LongURL, url.CreatedAt, expires_at, nullString(url.Owner), nullString(url.PasswordHash), nullInt(url.MaxClicks), rules, url.Interstitial, nullString(url.Title), nullString(url.Notes), policy, utm)
+	if err := row.Scan(&url.ID, &url.Status); err != nil {
+		logging.FromContext(ctx, nil).Error("save URL failed", "err", err)
+		return err
+	}
+	return nil
+}
+
+// insertVariants stores the variants of a freshly inserted link and fills in their IDs.
+func insertVariants(ctx context.Context, q queryer, url *models.URL) error {
+	query := `
+        INSERT INTO url_variants (url_id, position, name, destination, weight)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id
+    `
+	for i := range url.Variants {
+		v := &url.Variants[i]
+		if err := q.QueryRowContext(ctx, query, url.ID, i, v.Name, v.Destination, v.Weight).Scan(&v.ID); err != nil {
+			logging.FromContext(ctx, nil).Error("save variant failed", "code", url.ShortCode, "variant", v.Name, "err", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// insertTags attaches tags to a freshly inserted link, creating missing tags.
+func insertTags(ctx context.Context, q queryer, url *models.URL) error {
+	if len(url.Tags) == 0 {
+		return nil
+	}
+	query := `
+        WITH new_tags AS (
+            INSERT INTO tags (name) SELECT unnest($2::text[])
+            ON CONFLICT (name) DO NOTHING
+            RETURNING id
+        )
+        INSERT INTO url_tags (url_id, tag_id)
+        SELECT $1, id FROM new_tags
+        UNION
+        SELECT $1, id FROM tags WHERE name = ANY($2)
+        ON CONFLICT DO NOTHING
+    `
+	if _, err := q.ExecContext(ctx, query, url.ID, pq.Array(url.Tags)); err != nil {
+		logging.FromContext(ctx, nil).Error("save tags failed", "code", url.ShortCode, "err", err)
 		return err
 	}
 	return nil
 }
 
-func (r *URLRepository) FindByShortCode(ctx context.Context, shortCode string) (*models.URL, error) {
+// FindByShortCode returns the active (unexpired) link for a short code on a domain.
+// The default domain is "".
+func (r *URLRepository) FindByShortCode(ctx context.Context, domain, shortCode string) (*models.URL, error) {
+	ctx, span := r.span(ctx, "FindByShortCode")
+	defer span.End()
+
 	query := `
-        SELECT id, short_code, long_url, created_at, expires_at
+        SELECT ` + urlColumns + `, ` + variantsColumn + `
         FROM urls
-        WHERE short_code = $1 AND (expires_at IS NULL OR expires_at > NOW())
+        WHERE domain = $1 AND ` + r.codeEq("short_code", "$2") + ` AND (expires_at IS NULL OR expires_at > NOW())
 	`
 
-	var expires_at sql.NullTime
-	row := r.db.QueryRowContext(ctx, query, shortCode)
-	var url models.URL
-	if err := row.Scan(&url.ID, &url.ShortCode, &url.LongURL, &url.CreatedAt, &expires_at); err != nil {
+	url, err := scanURLWithVariants(r.db.QueryRowContext(ctx, query, domain, shortCode))
+	if err != nil {
 		if err == sql.ErrNoRows {
-			log.Printf("No rows found for the given short code: %s", shortCode)
 			return nil, nil // Not found
 		}
-		log.Printf("Error finding URL by short code: %v", err)
+		r.log(ctx).Error("find URL by short code failed", "code", shortCode, "err", err)
 		return nil, err
 	}
-	if expires_at.Valid {
-		url.ExpiresAt = &expires_at.Time
+	return url, nil
+}
+
+// FindPlainByLongURL returns the oldest active, unexpired link on a domain that
+// points at longURL and carries nothing but the destination: no password, click
+// limit, rules, variants, interstitial, query passthrough, UTM defaults, metadata
+// or owner. Only such links can be handed out again for a repeated shorten request.
+func (r *URLRepository) FindPlainByLongURL(ctx context.Context, domain, longURL string) (*models.URL, error) {
+	ctx, span := r.span(ctx, "FindPlainByLongURL")
+	defer span.End()
+
+	query := `
+        SELECT ` + urlColumns + `
+        FROM urls u
+        WHERE domain = $1 AND long_url = $2 AND status = 'active'
+          AND (expires_at IS NULL OR expires_at > NOW())
+          AND password_hash IS NULL AND max_clicks IS NULL AND rules IS NULL
+          AND NOT interstitial AND title IS NULL AND notes IS NULL AND owner IS NULL
+          AND query_policy = 'ignore' AND utm IS NULL
+          AND NOT EXISTS (SELECT 1 FROM url_variants v WHERE v.url_id = u.id)
+          AND NOT EXISTS (SELECT 1 FROM url_tags t WHERE t.url_id = u.id)
+        ORDER BY id
+        LIMIT 1
+	`
+
+	url, err := scanURL(r.db.QueryRowContext(ctx, query, domain, longURL))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		r.log(ctx).Error("find URL by long URL failed", "err", err)
+		return nil, err
 	}
-	return &url, nil
+	return url, nil
 }
 
-func (r *URLRepository) ExistsByShortCode(ctx context.Context, shortCode string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM urls WHERE short_code = $1)`
-	row := r.db.QueryRowContext(ctx, query, shortCode)
+// ExistsByShortCode reports whether a code is in use or still cooling down after
+// its link was deleted or swept.
+func (r *URLRepository) ExistsByShortCode(ctx context.Context, domain, shortCode string) (bool, error) {
+	ctx, span := r.span(ctx, "ExistsByShortCode")
+	defer span.End()
+
+	query := `
+        SELECT EXISTS(SELECT 1 FROM urls WHERE domain = $1 AND ` + r.codeEq("short_code", "$2") + `)
+            OR EXISTS(SELECT 1 FROM code_tombstones WHERE domain = $1 AND ` + r.codeEq("short_code", "$2") + ` AND available_at > NOW())
+	`
+	row := r.db.QueryRowContext(ctx, query, domain, shortCode)
 	var exists bool
 	if err := row.Scan(&exists); err != nil {
-		log.Printf("Error checking if URL exists by short code: %v", err)
+		r.log(ctx).Error("check short code failed", "code", shortCode, "err", err)
 		return false, err
 	}
 	return exists, nil
 }
 
-func (r *URLRepository) DeleteByShortCode(ctx context.Context, shortCode string) error {
-	query := `DELETE FROM urls WHERE short_code = $1`
+// DeleteByShortCode removes a link, queues its link.deleted event and keeps the
+// code from being claimed again for cooldown.
+func (r *URLRepository) DeleteByShortCode(ctx context.Context, domain, shortCode string, cooldown time.Duration) error {
+	ctx, span := r.span(ctx, "DeleteByShortCode")
+	defer span.End()
+
+	query := `DELETE FROM urls WHERE domain = $1 AND ` + r.codeEq("short_code", "$2") + ` RETURNING ` + urlColumns
 
-	result, err := r.db.ExecContext(ctx, query, shortCode)
+	url, err := r.withEvent(ctx, models.EventLinkDeleted, func(q queryer) (*models.URL, error) {
+		url, err := scanURL(q.QueryRowContext(ctx, query, domain, shortCode))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if err := insertTombstone(ctx, q, url.Domain, url.ShortCode, TombstoneDeleted, time.Now().Add(cooldown)); err != nil {
+			return nil, err
+		}
+		return url, nil
+	})
 	if err != nil {
-		log.Printf("Error deleting short_code: %s with error: %v", shortCode, err)
+		r.log(ctx).Error("delete URL failed", "code", shortCode, "err", err)
 		return err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	if url == nil {
+		return ErrNotFound
+	}
+
+	r.log(ctx).Info("deleted URL", "domain", domain, "code", shortCode)
+	return nil
+}
+
+// FindAnyByShortCode returns the URL for a short code regardless of expiry.
+// Used by admin tooling that needs to inspect expired links too.
+func (r *URLRepository) FindAnyByShortCode(ctx context.Context, domain, shortCode string) (*models.URL, error) {
+	ctx, span := r.span(ctx, "FindAnyByShortCode")
+	defer span.End()
+
+	query := `
+        SELECT ` + urlColumns + `, ` + variantsColumn + `
+        FROM urls
+        WHERE domain = $1 AND ` + r.codeEq("short_code", "$2") + `
+	`
+
+	url, err := scanURLWithVariants(r.db.QueryRowContext(ctx, query, domain, shortCode))
 	if err != nil {
-		log.Printf("Error fetching rows affected for short_code: %s: %v", shortCode, err)
-		return err
+		if err == sql.ErrNoRows {
+			return nil, nil // Not found
+		}
+		r.log(ctx).Error("find URL by short code failed", "code", shortCode, "err", err)
+		return nil, err
+	}
+	return url, nil
+}
+
+// UpdateLongURL points an existing short code at a new destination.
+// Returns a nil URL when the code doesn't exist.
+func (r *URLRepository) UpdateLongURL(ctx context.Context, domain, shortCode, longURL string) (*models.URL, error) {
+	ctx, span := r.span(ctx, "UpdateLongURL")
+	defer span.End()
+
+	query := `
+        UPDATE urls SET long_url = $3
+        WHERE domain = $1 AND ` + r.codeEq("short_code", "$2") + `
+        RETURNING ` + urlColumns + `
+	`
+	return r.updateReturning(ctx, models.EventLinkUpdated, query, domain, shortCode, longURL)
+}
+
+// UpdateExpiry sets (or clears, when expiresAt is nil) the expiry of a short code.
+func (r *URLRepository) UpdateExpiry(ctx context.Context, domain, shortCode string, expiresAt *time.Time) (*models.URL, error) {
+	ctx, span := r.span(ctx, "UpdateExpiry")
+	defer span.End()
+
+	query := `
+        UPDATE urls SET expires_at = $3
+        WHERE domain = $1 AND ` + r.codeEq("short_code", "$2") + `
+        RETURNING ` + urlColumns + `
+	`
+	var expires_at sql.NullTime
+	event := models.EventLinkUpdated
+	if expiresAt != nil {
+		expires_at = sql.NullTime{Time: *expiresAt, Valid: true}
+		if !expiresAt.After(time.Now()) {
+			event = models.EventLinkExpired
+		}
+	}
+	return r.updateReturning(ctx, event, query, domain, shortCode, expires_at)
+}
+
+// UpdateStatus changes the status of a short code and records when it changed.
+func (r *URLRepository) UpdateStatus(ctx context.Context, domain, shortCode, status, reason string) (*models.URL, error) {
+	ctx, span := r.span(ctx, "UpdateStatus")
+	defer span.End()
+
+	query := `
+        UPDATE urls SET status = $3, status_reason = $4, status_changed_at = NOW()
+        WHERE domain = $1 AND ` + r.codeEq("short_code", "$2") + `
+        RETURNING ` + urlColumns + `
+	`
+	return r.updateReturning(ctx, models.EventLinkUpdated, query, domain, shortCode, status, nullString(reason))
+}
+
+// updateReturning runs a statement that returns urlColumns of one link and
+// queues the given webhook event for it in the same transaction.
+func (r *URLRepository) updateReturning(ctx context.Context, eventType, query string, args ...interface{}) (*models.URL, error) {
+	return r.withEvent(ctx, eventType, func(q queryer) (*models.URL, error) {
+		url, err := scanURL(q.QueryRowContext(ctx, query, args...))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, nil // Not found
+			}
+			r.log(ctx).Error("update URL failed", "err", err)
+			return nil, err
+		}
+		return url, nil
+	})
+}
+
+// Search returns links whose short code or destination contains the query (case-insensitive),
+// newest first.
+func (r *URLRepository) Search(ctx context.Context, q string, limit int) ([]*models.URL, error) {
+	ctx, span := r.span(ctx, "Search")
+	defer span.End()
+
+	query := `
+        SELECT ` + urlColumns + `
+        FROM urls
+        WHERE short_code ILIKE $1 OR long_url ILIKE $1
+        ORDER BY created_at DESC, id DESC
+        LIMIT $2
+	`
+	pattern := "%" + escapeLike(q) + "%"
+	rows, err := r.db.QueryContext(ctx, query, pattern, limit)
+	if err != nil {
+		r.log(ctx).Error("search URLs failed", "err", err)
+		return nil, err
 	}
+	defer rows.Close()
 
-	if rowsAffected == 0 {
-		log.Printf("No record found for short_code: %s", shortCode)
-		return fmt.Errorf("no record found for short_code: %s", shortCode)
+	var urls []*models.URL
+	for rows.Next() {
+		url, err := scanURL(rows)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, url)
 	}
+	return urls, rows.Err()
+}
+
+// ConsumeClick atomically takes one click from a max-click link and returns how many
+// remain. ok is false when the link has no clicks left (or doesn't exist). The row
+// update is the single source of truth across instances, so cached copies never
+// let a link serve more than max_clicks redirects.
+func (r *URLRepository) ConsumeClick(ctx context.Context, urlID int64) (remaining int, ok bool, err error) {
+	ctx, span := r.span(ctx, "ConsumeClick")
+	defer span.End()
 
-	log.Printf("Successfully deleted short_code: %s", shortCode)
+	query := `
+        UPDATE urls SET clicks_remaining = clicks_remaining - 1
+        WHERE id = $1 AND clicks_remaining > 0
+        RETURNING clicks_remaining
+	`
+	if err := r.db.QueryRowContext(ctx, query, urlID).Scan(&remaining); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, false, nil
+		}
+		r.log(ctx).Error("consume click failed", "url_id", urlID, "err", err)
+		return 0, false, err
+	}
+	return remaining, true, nil
+}
+
+// SaveClick records a single redirect in the clicks table. The same statement
+// queues a link.clicked event for subscriptions of the link owner that want one.
+func (r *URLRepository) SaveClick(ctx context.Context, click *models.Click) error {
+	ctx, span := r.span(ctx, "SaveClick")
+	defer span.End()
+
+	query := `
+        WITH c AS (
+            INSERT INTO clicks (url_id, short_code, variant_id, clicked_at, ip_address, user_agent, referer)
+            VALUES ($1, $2, $3, $4, $5, $6, $7)
+            RETURNING url_id
+        )
+        INSERT INTO webhook_outbox (subscription_id, event_id, event_type, payload)
+        SELECT s.id, $8::text, 'link.clicked', $9::jsonb
+        FROM c
+        JOIN urls u ON u.id = c.url_id
+        JOIN api_keys k ON k.name = u.owner AND k.revoked_at IS NULL
+        JOIN webhook_subscriptions s ON s.api_key_id = k.id AND 'link.clicked' = ANY(s.events)
+    `
+	ev := models.NewClickEvent(click)
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	var variantID sql.NullInt64
+	if click.VariantID != nil {
+		variantID = sql.NullInt64{Int64: *click.VariantID, Valid: true}
+	}
+	_, err = r.db.ExecContext(ctx, query,
+		click.URLID,
+		click.ShortCode,
+		variantID,
+		click.ClickedAt,
+		nullString(click.IPAddress),
+		nullString(click.UserAgent),
+		nullString(click.Referer),
+		ev.ID,
+		string(payload),
+	)
+	if err != nil {
+		r.log(ctx).Error("save click failed", "code", click.ShortCode, "err", err)
+		return err
+	}
 	return nil
 }
+
+// CountClicks returns the total number of recorded clicks for a link.
+func (r *URLRepository) CountClicks(ctx context.Context, urlID int64) (int64, error) {
+	ctx, span := r.span(ctx, "CountClicks")
+	defer span.End()
+
+	var n int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM clicks WHERE url_id = $1`, urlID).Scan(&n); err != nil {
+		r.log(ctx).Error("count clicks failed", "url_id", urlID, "err", err)
+		return 0, err
+	}
+	return n, nil
+}
+
+// ClickStatsByURLID aggregates the clicks recorded for a link.
+func (r *URLRepository) ClickStatsByURLID(ctx context.Context, urlID int64, days int) (*models.ClickStats, error) {
+	ctx, span := r.span(ctx, "ClickStatsByURLID")
+	defer span.End()
+
+	stats := &models.ClickStats{}
+
+	query := `
+        SELECT COUNT(*), COUNT(DISTINCT ip_address), MIN(clicked_at), MAX(clicked_at)
+        FROM clicks
+        WHERE url_id = $1
+	`
+	var first, last sql.NullTime
+	row := r.db.QueryRowContext(ctx, query, urlID)
+	if err := row.Scan(&stats.TotalClicks, &stats.UniqueVisitors, &first, &last); err != nil {
+		r.log(ctx).Error("fetch click stats failed", "url_id", urlID, "err", err)
+		return nil, err
+	}
+	if first.Valid {
+		stats.FirstClickAt = &first.Time
+	}
+	if last.Valid {
+		stats.LastClickAt = &last.Time
+	}
+
+	dailyQuery := `
+        SELECT date_trunc('day', clicked_at) AS day, COUNT(*)
+        FROM clicks
+        WHERE url_id = $1 AND clicked_at > NOW() - make_interval(days => $2)
+        GROUP BY day
+        ORDER BY day
+	`
+	rows, err := r.db.QueryContext(ctx, dailyQuery, urlID, days)
+	if err != nil {
+		r.log(ctx).Error("fetch daily clicks failed", "url_id", urlID, "err", err)
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var d models.DailyClicks
+		if err := rows.Scan(&d.Day, &d.Clicks); err != nil {
+			return nil, err
+		}
+		stats.Daily = append(stats.Daily, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if stats.Variants, err = r.variantStats(ctx, urlID); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// variantStats returns per-variant click counts of a split link (nil for plain links).
+func (r *URLRepository) variantStats(ctx context.Context, urlID int64) ([]models.VariantStats, error) {
+	query := `
+        SELECT v.id, v.name, v.destination, v.weight, COUNT(c.id), COUNT(DISTINCT c.ip_address)
+        FROM url_variants v
+        LEFT JOIN clicks c ON c.variant_id = v.id
+        WHERE v.url_id = $1
+        GROUP BY v.id
+        ORDER BY v.position
+	`
+	rows, err := r.db.QueryContext(ctx, query, urlID)
+	if err != nil {
+		r.log(ctx).Error("fetch variant stats failed", "url_id", urlID, "err", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.VariantStats
+	var total int64
+	for rows.Next() {
+		var vs models.VariantStats
+		if err := rows.Scan(&vs.VariantID, &vs.Name, &vs.Destination, &vs.Weight, &vs.Clicks, &vs.UniqueVisitors); err != nil {
+			return nil, err
+		}
+		total += vs.Clicks
+		out = append(out, vs)
+	}
+	if total > 0 {
+		for i := range out {
+			out[i].Share = float64(out[i].Clicks) / float64(total)
+		}
+	}
+	return out, rows.Err()
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanURL scans a row selected with urlColumns. Any extra destinations are
+// scanned from the columns that follow urlColumns in the select list.
+func scanURL(row rowScanner, extra ...interface{}) (*models.URL, error) {
+	var url models.URL
+	var expires_at sql.NullTime
+	var owner, passwordHash, statusReason, title, notes sql.NullString
+	var maxClicks, clicksRemaining sql.NullInt64
+	var rules, utm []byte
+	dest := append([]interface{}{&url.ID, &url.Domain, &url.ShortCode, &url.LongURL, &url.CreatedAt, &expires_at, &owner, &passwordHash, &maxClicks, &clicksRemaining, &rules, &url.Status, &statusReason, &url.Interstitial, &title, &notes, &url.QueryPolicy, &utm}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	if expires_at.Valid {
+		url.ExpiresAt = &expires_at.Time
+	}
+	url.Owner = owner.String
+	url.PasswordHash = passwordHash.String
+	url.StatusReason = statusReason.String
+	url.Title = title.String
+	url.Notes = notes.String
+	if maxClicks.Valid {
+		n := int(maxClicks.Int64)
+		url.MaxClicks = &n
+	}
+	if clicksRemaining.Valid {
+		n := int(clicksRemaining.Int64)
+		url.ClicksRemaining = &n
+	}
+	if len(rules) > 0 {
+		if err := json.Unmarshal(rules, &url.Rules); err != nil {
+			return nil, fmt.Errorf("decode rules for %s: %w", url.ShortCode, err)
+		}
+	}
+	if len(utm) > 0 {
+		if err := json.Unmarshal(utm, &url.UTM); err != nil {
+			return nil, fmt.Errorf("decode utm for %s: %w", url.ShortCode, err)
+		}
+	}
+	return &url, nil
+}
+
+// marshalUTM encodes UTM defaults for the JSONB column (NULL when none are set).
+func marshalUTM(utm *models.UTM) (interface{}, error) {
+	if len(utm.Params()) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(utm)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// marshalRules encodes redirect rules for the JSONB column (NULL when there are none).
+func marshalRules(rules []models.RedirectRule) (interface{}, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// scanURLWithVariants scans urlColumns followed by variantsColumn.
+func scanURLWithVariants(row rowScanner) (*models.URL, error) {
+	var variants []byte
+	url, err := scanURL(row, &variants)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(variants, &url.Variants); err != nil {
+		return nil, fmt.Errorf("decode variants for %s: %w", url.ShortCode, err)
+	}
+	if len(url.Variants) == 0 {
+		url.Variants = nil
+	}
+	return url, nil
+}
+
+func nullInt(n *int) sql.NullInt64 {
+	if n == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(*n), Valid: true}
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// escapeLike escapes LIKE wildcards so user input is matched literally.
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}
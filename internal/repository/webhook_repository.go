@@ -0,0 +1,309 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+	"github.com/Siddarth2230/url-shortener/pkg/logging"
+	"github.com/lib/pq"
+)
+
+// insertEvent queues ev for every subscription of the owner's API key that
+// asked for its type. Callers run it in the transaction that made the change,
+// so the event is stored if and only if the change commits.
+func insertEvent(ctx context.Context, q queryer, owner string, ev *models.WebhookEvent) error {
+	if owner == "" {
+		return nil // only links created with an API key have subscribers
+	}
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	query := `
+        INSERT INTO webhook_outbox (subscription_id, event_id, event_type, payload)
+        SELECT s.id, $2::text, $3::text, $4::jsonb
+        FROM webhook_subscriptions s JOIN api_keys k ON k.id = s.api_key_id
+        WHERE k.name = $1 AND k.revoked_at IS NULL AND $3::text = ANY(s.events)
+	`
+	if _, err := q.ExecContext(ctx, query, owner, ev.ID, ev.Type, string(payload)); err != nil {
+		logging.FromContext(ctx, nil).Error("queue webhook event failed", "event", ev.Type, "err", err)
+		return err
+	}
+	return nil
+}
+
+// withEvent runs fn in a transaction and queues an event of the given type for
+// the link it returns. A nil link (not found) queues nothing.
+func (r *URLRepository) withEvent(ctx context.Context, eventType string, fn func(q queryer) (*models.URL, error)) (*models.URL, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	url, err := fn(tx)
+	if err != nil || url == nil {
+		return url, err
+	}
+	if err := insertEvent(ctx, tx, url.Owner, models.NewLinkEvent(eventType, url)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return url, nil
+}
+
+// CreateAPIKey stores a new key by the SHA-256 hash of its secret value.
+func (r *URLRepository) CreateAPIKey(ctx context.Context, name, tier, keyHash string) (*models.APIKey, error) {
+	ctx, span := r.span(ctx, "CreateAPIKey")
+	defer span.End()
+
+	query := `
+        INSERT INTO api_keys (name, tier, key_hash) VALUES ($1, $2, $3)
+        RETURNING id, name, tier, created_at
+	`
+	var k models.APIKey
+	if err := r.db.QueryRowContext(ctx, query, name, tier, keyHash).Scan(&k.ID, &k.Name, &k.Tier, &k.CreatedAt); err != nil {
+		r.log(ctx).Error("create API key failed", "name", name, "err", err)
+		return nil, err
+	}
+	return &k, nil
+}
+
+// FindAPIKey returns the unrevoked key with the given hash, or nil.
+func (r *URLRepository) FindAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	ctx, span := r.span(ctx, "FindAPIKey")
+	defer span.End()
+
+	query := `SELECT id, name, tier, created_at FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL`
+	var k models.APIKey
+	if err := r.db.QueryRowContext(ctx, query, keyHash).Scan(&k.ID, &k.Name, &k.Tier, &k.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		r.log(ctx).Error("find API key failed", "err", err)
+		return nil, err
+	}
+	return &k, nil
+}
+
+// RevokeAPIKey disables a key by name. It returns false when no active key has that name.
+func (r *URLRepository) RevokeAPIKey(ctx context.Context, name string) (bool, error) {
+	ctx, span := r.span(ctx, "RevokeAPIKey")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE api_keys SET revoked_at = NOW() WHERE name = $1 AND revoked_at IS NULL`, name)
+	if err != nil {
+		r.log(ctx).Error("revoke API key failed", "name", name, "err", err)
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// CreateSubscription stores a webhook subscription and fills in its ID and creation time.
+func (r *URLRepository) CreateSubscription(ctx context.Context, sub *models.WebhookSubscription) error {
+	ctx, span := r.span(ctx, "CreateSubscription")
+	defer span.End()
+
+	query := `
+        INSERT INTO webhook_subscriptions (api_key_id, url, secret, events)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, created_at
+	`
+	if err := r.db.QueryRowContext(ctx, query, sub.APIKeyID, sub.URL, sub.Secret, pq.Array(sub.Events)).Scan(&sub.ID, &sub.CreatedAt); err != nil {
+		r.log(ctx).Error("create webhook subscription failed", "err", err)
+		return err
+	}
+	return nil
+}
+
+// ListSubscriptions returns an API key's subscriptions, oldest first, without secrets.
+func (r *URLRepository) ListSubscriptions(ctx context.Context, apiKeyID int64) ([]*models.WebhookSubscription, error) {
+	ctx, span := r.span(ctx, "ListSubscriptions")
+	defer span.End()
+
+	query := `
+        SELECT id, api_key_id, url, events, created_at
+        FROM webhook_subscriptions
+        WHERE api_key_id = $1
+        ORDER BY id
+	`
+	rows, err := r.db.QueryContext(ctx, query, apiKeyID)
+	if err != nil {
+		r.log(ctx).Error("list webhook subscriptions failed", "err", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []*models.WebhookSubscription{}
+	for rows.Next() {
+		var s models.WebhookSubscription
+		if err := rows.Scan(&s.ID, &s.APIKeyID, &s.URL, pq.Array(&s.Events), &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		subs = append(subs, &s)
+	}
+	return subs, rows.Err()
+}
+
+// DeleteSubscription removes one of an API key's subscriptions along with its
+// queued deliveries. It returns false when the key has no such subscription.
+func (r *URLRepository) DeleteSubscription(ctx context.Context, apiKeyID, id int64) (bool, error) {
+	ctx, span := r.span(ctx, "DeleteSubscription")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1 AND api_key_id = $2`, id, apiKeyID)
+	if err != nil {
+		r.log(ctx).Error("delete webhook subscription failed", "subscription_id", id, "err", err)
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// ClaimDeliveries picks up to limit due deliveries and leases them for lease,
+// counting the attempt. Rows locked by another dispatcher are skipped, and a
+// dispatcher that dies mid-delivery only delays the retry until the lease ends.
+func (r *URLRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	ctx, span := r.span(ctx, "ClaimDeliveries")
+	defer span.End()
+
+	query := `
+        UPDATE webhook_outbox o
+        SET attempts = o.attempts + 1, last_attempt_at = NOW(),
+            next_attempt_at = NOW() + make_interval(secs => $2)
+        FROM webhook_subscriptions s
+        WHERE s.id = o.subscription_id AND o.id IN (
+            SELECT id FROM webhook_outbox
+            WHERE status = 'pending' AND next_attempt_at <= NOW()
+            ORDER BY next_attempt_at, id
+            LIMIT $1
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING o.id, o.subscription_id, s.url, s.secret, o.event_id, o.event_type, o.status, o.attempts, o.created_at, o.next_attempt_at, o.payload
+	`
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		r.log(ctx).Error("claim webhook deliveries failed", "err", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*models.WebhookDelivery
+	for rows.Next() {
+		var d models.WebhookDelivery
+		var payload []byte
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.URL, &d.Secret, &d.EventID, &d.EventType, &d.Status, &d.Attempts, &d.CreatedAt, &d.NextAttemptAt, &payload); err != nil {
+			return nil, err
+		}
+		d.Payload = payload
+		deliveries = append(deliveries, &d)
+	}
+	return deliveries, rows.Err()
+}
+
+// MarkDelivered records a successful delivery.
+func (r *URLRepository) MarkDelivered(ctx context.Context, id int64, statusCode int) error {
+	ctx, span := r.span(ctx, "MarkDelivered")
+	defer span.End()
+
+	query := `
+        UPDATE webhook_outbox SET status = 'delivered', last_status_code = $2, last_error = NULL
+        WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, id, statusCode)
+	return err
+}
+
+// MarkFailed records a failed attempt. The delivery is retried at next, or
+// moved to the dead letters when next is nil.
+func (r *URLRepository) MarkFailed(ctx context.Context, id int64, statusCode int, errMsg string, next *time.Time) error {
+	ctx, span := r.span(ctx, "MarkFailed")
+	defer span.End()
+
+	status, nextAt := models.DeliveryDead, sql.NullTime{}
+	if next != nil {
+		status, nextAt = models.DeliveryPending, sql.NullTime{Time: *next, Valid: true}
+	}
+	query := `
+        UPDATE webhook_outbox
+        SET status = $2, next_attempt_at = COALESCE($3, next_attempt_at), last_status_code = $4, last_error = $5
+        WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, id, status, nextAt, sql.NullInt64{Int64: int64(statusCode), Valid: statusCode != 0}, errMsg)
+	return err
+}
+
+// ListDeadLetters returns an API key's dead deliveries, most recent failure first.
+func (r *URLRepository) ListDeadLetters(ctx context.Context, apiKeyID int64, limit int) ([]*models.WebhookDelivery, error) {
+	ctx, span := r.span(ctx, "ListDeadLetters")
+	defer span.End()
+
+	query := `
+        SELECT id, subscription_id, url, event_id, event_type, payload, attempts, last_error, last_status_code, created_at
+        FROM webhook_dead_letters
+        WHERE api_key_id = $1
+        ORDER BY last_attempt_at DESC, id DESC
+        LIMIT $2
+	`
+	rows, err := r.db.QueryContext(ctx, query, apiKeyID, limit)
+	if err != nil {
+		r.log(ctx).Error("list webhook dead letters failed", "err", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*models.WebhookDelivery{}
+	for rows.Next() {
+		d := models.WebhookDelivery{Status: models.DeliveryDead}
+		var payload []byte
+		var lastError sql.NullString
+		var lastStatus sql.NullInt64
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.URL, &d.EventID, &d.EventType, &payload, &d.Attempts, &lastError, &lastStatus, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		d.Payload = payload
+		d.LastError = lastError.String
+		d.LastStatusCode = int(lastStatus.Int64)
+		deliveries = append(deliveries, &d)
+	}
+	return deliveries, rows.Err()
+}
+
+// ReplayDelivery queues a dead delivery of an API key again with a fresh retry
+// budget. It returns false when the key has no dead delivery with that ID.
+func (r *URLRepository) ReplayDelivery(ctx context.Context, apiKeyID, id int64) (bool, error) {
+	ctx, span := r.span(ctx, "ReplayDelivery")
+	defer span.End()
+
+	query := `
+        UPDATE webhook_outbox o
+        SET status = 'pending', attempts = 0, next_attempt_at = NOW()
+        FROM webhook_subscriptions s
+        WHERE o.id = $2 AND o.status = 'dead' AND s.id = o.subscription_id AND s.api_key_id = $1
+	`
+	result, err := r.db.ExecContext(ctx, query, apiKeyID, id)
+	if err != nil {
+		r.log(ctx).Error("replay webhook delivery failed", "delivery_id", id, "err", err)
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// PruneDeliveries deletes delivered rows older than the cutoff and returns how many went.
+func (r *URLRepository) PruneDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := r.span(ctx, "PruneDeliveries")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_outbox WHERE status = 'delivered' AND created_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
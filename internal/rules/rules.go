@@ -0,0 +1,338 @@
+// Package rules evaluates per-link conditional redirect rules against a visitor.
+//
+// Rules are evaluated in order and the first one whose conditions all match picks
+// the destination. Everything runs in-process from the cached link; the only
+// external input is an optional local GeoIP database for country conditions.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+// MaxRules caps how many rules one link may carry.
+const MaxRules = 50
+
+// Device classes understood by RedirectRule.Devices.
+const (
+	DeviceIOS     = "ios"
+	DeviceAndroid = "android"
+	DeviceMobile  = "mobile"
+	DeviceDesktop = "desktop"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Visitor holds the request attributes rules can match on.
+type Visitor struct {
+	UserAgent      string
+	AcceptLanguage string
+	IP             net.IP
+	Now            time.Time
+}
+
+// CountryResolver maps an IP address to an ISO 3166-1 alpha-2 country code.
+type CountryResolver interface {
+	Country(ip net.IP) (string, error)
+}
+
+// Evaluator picks destinations for visitors. Countries may be nil, in which case
+// rules with country conditions never match.
+type Evaluator struct {
+	Countries CountryResolver
+}
+
+// Evaluate returns the destination of the first matching rule.
+func (e *Evaluator) Evaluate(rs []models.RedirectRule, v Visitor) (string, bool) {
+	if len(rs) == 0 {
+		return "", false
+	}
+	if v.Now.IsZero() {
+		v.Now = time.Now()
+	}
+
+	m := &matcher{e: e, v: v}
+	for i := range rs {
+		if m.matches(&rs[i]) {
+			return rs[i].Destination, true
+		}
+	}
+	return "", false
+}
+
+// matcher lazily derives visitor attributes so each is computed at most once per request.
+type matcher struct {
+	e *Evaluator
+	v Visitor
+
+	devices     map[string]bool
+	lang        *string
+	country     *string
+	countryDone bool
+}
+
+func (m *matcher) matches(r *models.RedirectRule) bool {
+	if len(r.Devices) > 0 && !m.matchDevice(r.Devices) {
+		return false
+	}
+	if len(r.Languages) > 0 && !m.matchLanguage(r.Languages) {
+		return false
+	}
+	if len(r.Countries) > 0 && !m.matchCountry(r.Countries) {
+		return false
+	}
+	if r.TimeWindow != nil && !matchTime(r.TimeWindow, m.v.Now) {
+		return false
+	}
+	return true
+}
+
+func (m *matcher) matchDevice(want []string) bool {
+	if m.devices == nil {
+		m.devices = DeviceClasses(m.v.UserAgent)
+	}
+	for _, d := range want {
+		if m.devices[strings.ToLower(d)] {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *matcher) matchLanguage(want []string) bool {
+	if m.lang == nil {
+		lang := PreferredLanguage(m.v.AcceptLanguage)
+		m.lang = &lang
+	}
+	if *m.lang == "" {
+		return false
+	}
+	for _, w := range want {
+		w = strings.ToLower(w)
+		if *m.lang == w || strings.HasPrefix(*m.lang, w+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *matcher) matchCountry(want []string) bool {
+	if !m.countryDone {
+		m.countryDone = true
+		if m.e.Countries != nil && m.v.IP != nil {
+			if c, err := m.e.Countries.Country(m.v.IP); err == nil && c != "" {
+				c = strings.ToUpper(c)
+				m.country = &c
+			}
+		}
+	}
+	if m.country == nil {
+		return false
+	}
+	for _, w := range want {
+		if strings.EqualFold(w, *m.country) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchTime(tw *models.TimeWindow, now time.Time) bool {
+	if tw.Start != nil && now.Before(*tw.Start) {
+		return false
+	}
+	if tw.End != nil && !now.Before(*tw.End) {
+		return false
+	}
+	if len(tw.Days) == 0 && tw.From == "" && tw.To == "" {
+		return true
+	}
+
+	loc, err := location(tw.Timezone)
+	if err != nil {
+		return false
+	}
+	local := now.In(loc)
+
+	if len(tw.Days) > 0 {
+		ok := false
+		for _, d := range tw.Days {
+			if weekdays[strings.ToLower(d)] == local.Weekday() {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+
+	if tw.From != "" || tw.To != "" {
+		from, to := 0, 24*60
+		if tw.From != "" {
+			from, _ = parseClock(tw.From)
+		}
+		if tw.To != "" {
+			to, _ = parseClock(tw.To)
+		}
+		minute := local.Hour()*60 + local.Minute()
+		if from <= to {
+			return minute >= from && minute < to
+		}
+		// Window wraps past midnight, e.g. 22:00-06:00
+		return minute >= from || minute < to
+	}
+	return true
+}
+
+// DeviceClasses classifies a User-Agent into the device classes it belongs to.
+func DeviceClasses(ua string) map[string]bool {
+	classes := make(map[string]bool, 2)
+	switch {
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPad"), strings.Contains(ua, "iPod"):
+		classes[DeviceIOS] = true
+		classes[DeviceMobile] = true
+	case strings.Contains(ua, "Android"):
+		classes[DeviceAndroid] = true
+		classes[DeviceMobile] = true
+	case strings.Contains(ua, "Mobile"):
+		classes[DeviceMobile] = true
+	default:
+		classes[DeviceDesktop] = true
+	}
+	return classes
+}
+
+// PreferredLanguage returns the lowercase language tag with the highest q-value
+// in an Accept-Language header, or "" if there is none.
+func PreferredLanguage(header string) string {
+	type tag struct {
+		name string
+		q    float64
+		pos  int
+	}
+	var tags []tag
+	for i, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		if name == "" || name == "*" {
+			continue
+		}
+		q := 1.0
+		for _, f := range fields[1:] {
+			f = strings.TrimSpace(f)
+			if strings.HasPrefix(f, "q=") {
+				if v, err := strconv.ParseFloat(f[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			tags = append(tags, tag{name: name, q: q, pos: i})
+		}
+	}
+	if len(tags) == 0 {
+		return ""
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	return tags[0].name
+}
+
+// Validate checks the shape of a rule list. Destination URLs are validated by the caller.
+func Validate(rs []models.RedirectRule) error {
+	if len(rs) > MaxRules {
+		return fmt.Errorf("at most %d rules are allowed", MaxRules)
+	}
+	for i, r := range rs {
+		if err := validateRule(&r); err != nil {
+			return fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func validateRule(r *models.RedirectRule) error {
+	if r.Destination == "" {
+		return errors.New("destination is required")
+	}
+	for _, d := range r.Devices {
+		switch strings.ToLower(d) {
+		case DeviceIOS, DeviceAndroid, DeviceMobile, DeviceDesktop:
+		default:
+			return fmt.Errorf("unknown device %q", d)
+		}
+	}
+	for _, l := range r.Languages {
+		if l == "" || len(l) > 35 || strings.ContainsAny(l, " ,;") {
+			return fmt.Errorf("invalid language %q", l)
+		}
+	}
+	for _, c := range r.Countries {
+		if len(c) != 2 {
+			return fmt.Errorf("invalid country code %q", c)
+		}
+	}
+	if tw := r.TimeWindow; tw != nil {
+		if tw.Start != nil && tw.End != nil && !tw.End.After(*tw.Start) {
+			return errors.New("time window end must be after start")
+		}
+		for _, d := range tw.Days {
+			if _, ok := weekdays[strings.ToLower(d)]; !ok {
+				return fmt.Errorf("unknown day %q", d)
+			}
+		}
+		for _, c := range []string{tw.From, tw.To} {
+			if c == "" {
+				continue
+			}
+			if _, err := parseClock(c); err != nil {
+				return err
+			}
+		}
+		if _, err := location(tw.Timezone); err != nil {
+			return fmt.Errorf("unknown timezone %q", tw.Timezone)
+		}
+	}
+	if len(r.Devices) == 0 && len(r.Languages) == 0 && len(r.Countries) == 0 && r.TimeWindow == nil {
+		return errors.New("rule has no conditions")
+	}
+	return nil
+}
+
+// parseClock parses "HH:MM" into minutes since midnight.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q (want HH:MM)", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+var locations sync.Map // name -> *time.Location
+
+// location loads (and memoizes) an IANA time zone; "" means UTC.
+func location(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
@@ -0,0 +1,144 @@
+package rules
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+const (
+	iPhoneUA  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148"
+	androidUA = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 Chrome/120.0 Mobile Safari/537.36"
+	desktopUA = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36"
+)
+
+type staticCountries map[string]string
+
+func (s staticCountries) Country(ip net.IP) (string, error) {
+	c, ok := s[ip.String()]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return c, nil
+}
+
+func TestEvaluateFirstMatchWins(t *testing.T) {
+	rs := []models.RedirectRule{
+		{Devices: []string{"ios"}, Destination: "https://apps.apple.com/app"},
+		{Devices: []string{"android"}, Destination: "https://play.google.com/app"},
+		{Devices: []string{"mobile"}, Destination: "https://m.example.com"},
+	}
+	ev := &Evaluator{}
+
+	tests := []struct {
+		ua   string
+		want string
+		ok   bool
+	}{
+		{iPhoneUA, "https://apps.apple.com/app", true},
+		{androidUA, "https://play.google.com/app", true},
+		{desktopUA, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ev.Evaluate(rs, Visitor{UserAgent: tt.ua})
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Evaluate(%q) = %q, %v; want %q, %v", tt.ua, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestEvaluateLanguage(t *testing.T) {
+	rs := []models.RedirectRule{
+		{Languages: []string{"pt-BR"}, Destination: "https://example.com/br"},
+		{Languages: []string{"de"}, Destination: "https://example.com/de"},
+	}
+	ev := &Evaluator{}
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"de-AT,de;q=0.9,en;q=0.8", "https://example.com/de"},
+		{"en;q=0.5, pt-BR", "https://example.com/br"},
+		{"pt-PT", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got, _ := ev.Evaluate(rs, Visitor{AcceptLanguage: tt.header})
+		if got != tt.want {
+			t.Errorf("Evaluate(Accept-Language=%q) = %q; want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateCountry(t *testing.T) {
+	rs := []models.RedirectRule{
+		{Countries: []string{"de", "AT"}, Destination: "https://example.com/dach"},
+	}
+
+	ev := &Evaluator{Countries: staticCountries{"1.2.3.4": "AT"}}
+	if got, ok := ev.Evaluate(rs, Visitor{IP: net.ParseIP("1.2.3.4")}); !ok || got != "https://example.com/dach" {
+		t.Errorf("expected DACH destination, got %q, %v", got, ok)
+	}
+	if _, ok := ev.Evaluate(rs, Visitor{IP: net.ParseIP("5.6.7.8")}); ok {
+		t.Error("unknown IP should not match")
+	}
+
+	// Without a GeoIP database country rules never match
+	if _, ok := (&Evaluator{}).Evaluate(rs, Visitor{IP: net.ParseIP("1.2.3.4")}); ok {
+		t.Error("country rule matched without a resolver")
+	}
+}
+
+func TestEvaluateTimeWindow(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	rs := []models.RedirectRule{
+		{TimeWindow: &models.TimeWindow{Start: &start, End: &end}, Destination: "https://example.com/sale"},
+		{TimeWindow: &models.TimeWindow{Days: []string{"sat", "sun"}}, Destination: "https://example.com/weekend"},
+		{TimeWindow: &models.TimeWindow{From: "22:00", To: "06:00", Timezone: "Europe/Berlin"}, Destination: "https://example.com/night"},
+	}
+	ev := &Evaluator{}
+
+	tests := []struct {
+		now  time.Time
+		want string
+	}{
+		{time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC), "https://example.com/sale"},
+		{time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC), "https://example.com/weekend"}, // Saturday, sale over
+		{time.Date(2025, 2, 3, 23, 30, 0, 0, time.UTC), "https://example.com/night"},  // 00:30 in Berlin
+		{time.Date(2025, 2, 3, 12, 0, 0, 0, time.UTC), ""},
+	}
+	for _, tt := range tests {
+		got, _ := ev.Evaluate(rs, Visitor{Now: tt.now})
+		if got != tt.want {
+			t.Errorf("Evaluate(now=%v) = %q; want %q", tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := []models.RedirectRule{
+		{Devices: []string{"ios"}, Languages: []string{"en-US"}, Destination: "https://example.com"},
+	}
+	if err := Validate(valid); err != nil {
+		t.Errorf("Validate(valid) = %v", err)
+	}
+
+	invalid := [][]models.RedirectRule{
+		{{Devices: []string{"toaster"}, Destination: "https://example.com"}},
+		{{Countries: []string{"DEU"}, Destination: "https://example.com"}},
+		{{Destination: "https://example.com"}},
+		{{Devices: []string{"ios"}}},
+		{{TimeWindow: &models.TimeWindow{From: "25:00"}, Destination: "https://example.com"}},
+		{{TimeWindow: &models.TimeWindow{Timezone: "Mars/Olympus"}, Destination: "https://example.com"}},
+	}
+	for i, rs := range invalid {
+		if err := Validate(rs); err == nil {
+			t.Errorf("case %d: expected validation error", i)
+		}
+	}
+}
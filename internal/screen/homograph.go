@@ -0,0 +1,123 @@
+package screen
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+)
+
+// Homograph blocks internationalized hostnames that can pass for other domains:
+// labels mixing scripts (Latin "paypal" with a Cyrillic "а") and labels written
+// entirely in Cyrillic or Greek letters that look like Latin ones ("аррӏе").
+// Hosts may be given in Unicode or punycode ("xn--") form.
+type Homograph struct {
+	// BlockIDN rejects every internationalized hostname, not just suspicious ones.
+	BlockIDN bool
+}
+
+// scripts checked for mixing. Han, Hiragana and Katakana are commonly mixed in
+// Japanese names and count as one script.
+var scripts = []struct {
+	name  string
+	table *unicode.RangeTable
+}{
+	{"Latin", unicode.Latin},
+	{"Cyrillic", unicode.Cyrillic},
+	{"Greek", unicode.Greek},
+	{"Armenian", unicode.Armenian},
+	{"Hebrew", unicode.Hebrew},
+	{"Arabic", unicode.Arabic},
+	{"CJK", unicode.Han},
+	{"CJK", unicode.Hiragana},
+	{"CJK", unicode.Katakana},
+	{"Hangul", unicode.Hangul},
+	{"Thai", unicode.Thai},
+}
+
+// latinLookalikes are lowercase Cyrillic and Greek letters that render like
+// Latin ones (hostnames are lowercased by IDNA before this check).
+const latinLookalikes = "аеорсухіјѕԁһӏԛԝ" + // Cyrillic: a e o p c y x i j s d h l q w
+	"αικνορτυ" // Greek: a i k v o p t u
+
+func (h *Homograph) Screen(_ context.Context, u *url.URL) (Result, error) {
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if isASCII(host) && !strings.Contains(host, "xn--") {
+		return Result{Verdict: Pass}, nil
+	}
+	unicodeHost, err := idna.Lookup.ToUnicode(host)
+	if err != nil {
+		return blocked("invalid internationalized hostname"), nil
+	}
+	if isASCII(unicodeHost) {
+		return Result{Verdict: Pass}, nil
+	}
+	if h.BlockIDN {
+		return blocked("internationalized hostnames are not allowed"), nil
+	}
+
+	for _, label := range strings.Split(unicodeHost, ".") {
+		if isASCII(label) {
+			continue
+		}
+		found := labelScripts(label)
+		if len(found) > 1 {
+			return blocked("hostname label %q mixes %s scripts", label, strings.Join(found, " and ")), nil
+		}
+		if len(found) == 1 && (found[0] == "Cyrillic" || found[0] == "Greek") && allLookalikes(label) {
+			return blocked("hostname label %q imitates a Latin name", label), nil
+		}
+	}
+	return Result{Verdict: Pass}, nil
+}
+
+// labelScripts returns the distinct scripts of the letters in label.
+func labelScripts(label string) []string {
+	var found []string
+	seen := make(map[string]bool)
+	for _, r := range label {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		for _, s := range scripts {
+			if unicode.Is(s.table, r) {
+				if !seen[s.name] {
+					seen[s.name] = true
+					found = append(found, s.name)
+				}
+				break
+			}
+		}
+	}
+	return found
+}
+
+func allLookalikes(label string) bool {
+	for _, r := range label {
+		if unicode.IsLetter(r) && !strings.ContainsRune(latinLookalikes, r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// asciiHost returns the lowercased punycode form of u's host, so lists can
+// match Unicode and punycode spellings of the same domain.
+func asciiHost(u *url.URL) string {
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if a, err := idna.Lookup.ToASCII(host); err == nil {
+		return a
+	}
+	return host
+}
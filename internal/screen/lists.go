@@ -0,0 +1,165 @@
+package screen
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/idna"
+
+	"github.com/Siddarth2230/url-shortener/pkg/logging"
+)
+
+// Lists blocks and allows domains from a text file. Each non-empty line is
+// "block <domain>" or "allow <domain>"; "#" starts a comment. A domain matches
+// itself and all of its subdomains, and the most specific entry wins, so
+//
+//	block example.com
+//	allow docs.example.com
+//
+// blocks example.com and www.example.com but allows docs.example.com.
+type Lists struct {
+	path string
+
+	mu      sync.RWMutex
+	entries map[string]Verdict
+	modTime time.Time
+	size    int64
+}
+
+// LoadLists reads the list file at path.
+func LoadLists(path string) (*Lists, error) {
+	l := &Lists{path: path}
+	if err := l.Reload(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Reload rereads the file. On error the previously loaded lists stay in effect.
+func (l *Lists) Reload() error {
+	f, err := os.Open(l.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	entries, err := parseLists(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", l.path, err)
+	}
+
+	l.mu.Lock()
+	l.entries = entries
+	l.modTime = info.ModTime()
+	l.size = info.Size()
+	l.mu.Unlock()
+	return nil
+}
+
+// Watch reloads the file whenever its modification time or size changes,
+// checking every interval until ctx is canceled.
+func (l *Lists) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		info, err := os.Stat(l.path)
+		if err != nil {
+			logging.FromContext(ctx, nil).Warn("stat screening lists failed", "path", l.path, "err", err)
+			continue
+		}
+		l.mu.RLock()
+		changed := !info.ModTime().Equal(l.modTime) || info.Size() != l.size
+		l.mu.RUnlock()
+		if !changed {
+			continue
+		}
+		if err := l.Reload(); err != nil {
+			logging.FromContext(ctx, nil).Error("reload screening lists failed, keeping previous version", "path", l.path, "err", err)
+			continue
+		}
+		logging.FromContext(ctx, nil).Info("reloaded screening lists", "path", l.path, "entries", l.Len())
+	}
+}
+
+// Len returns the number of loaded entries.
+func (l *Lists) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.entries)
+}
+
+func (l *Lists) Screen(_ context.Context, u *url.URL) (Result, error) {
+	host := asciiHost(u)
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	// Walk from the full host up to the registrable parts: a.b.example.com, b.example.com, ...
+	for h := host; h != ""; {
+		if v, ok := l.entries[h]; ok {
+			if v == Block {
+				return blocked("domain %s is blocklisted", h), nil
+			}
+			return Result{Verdict: Allow}, nil
+		}
+		i := strings.IndexByte(h, '.')
+		if i < 0 {
+			break
+		}
+		h = h[i+1:]
+	}
+	return Result{Verdict: Pass}, nil
+}
+
+func parseLists(r io.Reader) (map[string]Verdict, error) {
+	entries := make(map[string]Verdict)
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: want \"block <domain>\" or \"allow <domain>\"", n)
+		}
+		var v Verdict
+		switch strings.ToLower(fields[0]) {
+		case "block":
+			v = Block
+		case "allow":
+			v = Allow
+		default:
+			return nil, fmt.Errorf("line %d: unknown action %q", n, fields[0])
+		}
+		domain := strings.Trim(strings.ToLower(fields[1]), ".")
+		if domain == "" {
+			return nil, fmt.Errorf("line %d: empty domain", n)
+		}
+		if a, err := idna.Lookup.ToASCII(domain); err == nil {
+			domain = a
+		}
+		entries[domain] = v
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
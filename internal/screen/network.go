@@ -0,0 +1,90 @@
+package screen
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Resolver looks up the addresses of a host. *net.Resolver satisfies it.
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// Network blocks destinations that point into private networks: bare IP hosts,
+// localhost-style names and, when Resolver is set, hostnames that resolve to
+// loopback, private, link-local or unspecified addresses.
+type Network struct {
+	Resolver Resolver      // optional; nil skips DNS resolution
+	Timeout  time.Duration // per lookup, default 2s
+}
+
+// internalSuffixes are names that never resolve on the public internet.
+var internalSuffixes = []string{"localhost", ".localhost", ".local", ".internal", ".lan", ".home.arpa"}
+
+func (n *Network) Screen(ctx context.Context, u *url.URL) (Result, error) {
+	host := asciiHost(u)
+
+	if ip := net.ParseIP(host); ip != nil {
+		if IsInternalIP(ip) {
+			return blocked("destination is a private or loopback address"), nil
+		}
+		return blocked("bare IP address hosts are not allowed"), nil
+	}
+	if isNumericHost(host) {
+		// Forms like 2130706433 or 0x7f.1 that browsers still treat as IPv4
+		return blocked("bare IP address hosts are not allowed"), nil
+	}
+	for _, suffix := range internalSuffixes {
+		if host == strings.TrimPrefix(suffix, ".") || strings.HasSuffix(host, suffix) {
+			return blocked("destination is an internal hostname"), nil
+		}
+	}
+	if !strings.Contains(host, ".") {
+		return blocked("destination host is not a fully qualified domain"), nil
+	}
+
+	if n.Resolver == nil {
+		return Result{Verdict: Pass}, nil
+	}
+	timeout := n.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	lookupCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	addrs, err := n.Resolver.LookupIPAddr(lookupCtx, host)
+	if err != nil {
+		// Unresolvable hosts aren't an attack on us; the link just won't work yet
+		return Result{Verdict: Pass}, nil
+	}
+	for _, a := range addrs {
+		if IsInternalIP(a.IP) {
+			return blocked("destination resolves to a private or loopback address"), nil
+		}
+	}
+	return Result{Verdict: Pass}, nil
+}
+
+// IsInternalIP reports loopback, private, link-local and unspecified addresses.
+func IsInternalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
+
+// isNumericHost reports whether host consists only of digits, dots and hex
+// markers, i.e. a shorthand IPv4 literal rather than a domain name.
+func isNumericHost(host string) bool {
+	labels := strings.Split(host, ".")
+	last := labels[len(labels)-1]
+	if last == "" {
+		return false
+	}
+	if strings.HasPrefix(last, "0x") {
+		last = last[2:]
+		return last != "" && strings.Trim(last, "0123456789abcdef") == ""
+	}
+	return strings.Trim(last, "0123456789") == ""
+}
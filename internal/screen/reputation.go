@@ -0,0 +1,56 @@
+package screen
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ReputationProvider looks a URL up in an external threat feed (Safe Browsing,
+// a commercial reputation API, an in-house list service, ...). Implementations
+// report malicious URLs with a short threat description and return an error only
+// when the lookup itself failed.
+type ReputationProvider interface {
+	Check(ctx context.Context, rawURL string) (malicious bool, threat string, err error)
+}
+
+// Reputation screens URLs with a ReputationProvider.
+type Reputation struct {
+	Provider ReputationProvider
+	Timeout  time.Duration // per lookup, default 3s
+}
+
+func (r *Reputation) Screen(ctx context.Context, u *url.URL) (Result, error) {
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	malicious, threat, err := r.Provider.Check(ctx, u.String())
+	if err != nil {
+		return Result{}, err
+	}
+	if malicious {
+		if threat == "" {
+			threat = "unsafe"
+		}
+		return blocked("destination flagged by reputation provider: %s", threat), nil
+	}
+	return Result{Verdict: Pass}, nil
+}
+
+// StaticReputation is a ReputationProvider backed by a fixed map from host to
+// threat, for tests and local development.
+type StaticReputation map[string]string
+
+func (s StaticReputation) Check(_ context.Context, rawURL string) (bool, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false, "", err
+	}
+	threat, ok := s[strings.ToLower(u.Hostname())]
+	return ok, threat, nil
+}
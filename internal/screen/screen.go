@@ -0,0 +1,80 @@
+// Package screen decides whether a destination URL may be shortened.
+//
+// A Chain runs a list of Screeners in order. Each one either blocks the URL,
+// vouches for it (allow, which skips the rest of the chain) or has no opinion.
+// Screeners must be cheap or bounded by the context: the chain runs inline on
+// every shorten request.
+package screen
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/Siddarth2230/url-shortener/pkg/logging"
+)
+
+// Verdict is the outcome of screening a URL.
+type Verdict int
+
+const (
+	Pass  Verdict = iota // no opinion, continue with the next screener
+	Allow                // trusted, skip the remaining screeners
+	Block                // rejected
+)
+
+// Result is a screener's verdict. Reason explains a Block.
+type Result struct {
+	Verdict Verdict
+	Reason  string
+}
+
+// Screener inspects a parsed http(s) URL. A returned error means the screener
+// itself failed (e.g. a provider timed out), not that the URL is bad.
+type Screener interface {
+	Screen(ctx context.Context, u *url.URL) (Result, error)
+}
+
+// Func adapts a function to the Screener interface.
+type Func func(ctx context.Context, u *url.URL) (Result, error)
+
+func (f Func) Screen(ctx context.Context, u *url.URL) (Result, error) { return f(ctx, u) }
+
+// Chain runs screeners in order and stops at the first Allow or Block.
+type Chain struct {
+	Screeners []Screener
+
+	// FailClosed blocks the URL when a screener errors. By default errors are
+	// logged and the screener is skipped, so an unreachable reputation provider
+	// doesn't take shortening down.
+	FailClosed bool
+}
+
+// Screen parses rawURL and runs it through the chain.
+func (c *Chain) Screen(ctx context.Context, rawURL string) (Result, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return Result{Verdict: Block, Reason: "unparseable URL"}, nil
+	}
+	for _, s := range c.Screeners {
+		res, err := s.Screen(ctx, u)
+		if err != nil {
+			if ctx.Err() != nil {
+				return Result{}, ctx.Err()
+			}
+			if c.FailClosed {
+				return Result{}, fmt.Errorf("screener %T: %w", s, err)
+			}
+			logging.FromContext(ctx, nil).Warn("URL screener failed, skipping", "screener", fmt.Sprintf("%T", s), "err", err)
+			continue
+		}
+		if res.Verdict != Pass {
+			return res, nil
+		}
+	}
+	return Result{Verdict: Pass}, nil
+}
+
+func blocked(format string, args ...interface{}) Result {
+	return Result{Verdict: Block, Reason: fmt.Sprintf(format, args...)}
+}
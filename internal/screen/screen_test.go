@@ -0,0 +1,214 @@
+package screen
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fakeResolver map[string][]string
+
+func (f fakeResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := f[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	var addrs []net.IPAddr
+	for _, ip := range ips {
+		addrs = append(addrs, net.IPAddr{IP: net.ParseIP(ip)})
+	}
+	return addrs, nil
+}
+
+func screenOne(t *testing.T, s Screener, rawURL string) Result {
+	t.Helper()
+	res, err := (&Chain{Screeners: []Screener{s}, FailClosed: true}).Screen(context.Background(), rawURL)
+	if err != nil {
+		t.Fatalf("Screen(%q) error: %v", rawURL, err)
+	}
+	return res
+}
+
+func TestNetwork(t *testing.T) {
+	n := &Network{Resolver: fakeResolver{
+		"intranet.example.com": {"10.0.0.5"},
+		"rebind.example.com":   {"93.184.216.34", "127.0.0.1"},
+		"www.example.com":      {"93.184.216.34"},
+	}}
+
+	tests := []struct {
+		url  string
+		want Verdict
+	}{
+		{"https://www.example.com/path", Pass},
+		{"https://unresolvable.example.org", Pass},
+		{"http://127.0.0.1/admin", Block},
+		{"http://[::1]:8080/", Block},
+		{"http://192.168.1.1/", Block},
+		{"http://169.254.169.254/latest/meta-data", Block},
+		{"http://93.184.216.34/", Block}, // public, but bare IP
+		{"http://2130706433/", Block},    // 127.0.0.1 as a decimal integer
+		{"http://0x7f.1/", Block},
+		{"http://localhost:8080/", Block},
+		{"http://printer.local/", Block},
+		{"http://db.internal/", Block},
+		{"http://intranet/", Block},
+		{"https://intranet.example.com/", Block},
+		{"https://rebind.example.com/", Block},
+	}
+	for _, tt := range tests {
+		if got := screenOne(t, n, tt.url); got.Verdict != tt.want {
+			t.Errorf("%s: verdict %d (%s), want %d", tt.url, got.Verdict, got.Reason, tt.want)
+		}
+	}
+}
+
+func TestHomograph(t *testing.T) {
+	h := &Homograph{}
+	tests := []struct {
+		url  string
+		want Verdict
+	}{
+		{"https://example.com", Pass},
+		{"https://münchen.de", Pass},              // single-script Latin IDN
+		{"https://xn--mnchen-3ya.de", Pass},       // same, punycode
+		{"https://пример.рф", Pass},               // Cyrillic, not Latin lookalikes
+		{"https://東京.jp", Pass},                   // Han
+		{"https://pаypal.com", Block},             // Latin with Cyrillic "а"
+		{"https://xn--pypal-4ve.com", Block},      // same, punycode
+		{"https://аррӏе.com", Block},              // all-Cyrillic lookalike of "apple"
+		{"https://www.xn--80ak6aa92e.com", Block}, // same, punycode
+	}
+	for _, tt := range tests {
+		if got := screenOne(t, h, tt.url); got.Verdict != tt.want {
+			t.Errorf("%s: verdict %d (%s), want %d", tt.url, got.Verdict, got.Reason, tt.want)
+		}
+	}
+
+	strict := &Homograph{BlockIDN: true}
+	if got := screenOne(t, strict, "https://münchen.de"); got.Verdict != Block {
+		t.Errorf("BlockIDN: verdict %d, want Block", got.Verdict)
+	}
+	if got := screenOne(t, strict, "https://example.com"); got.Verdict != Pass {
+		t.Errorf("BlockIDN ascii: verdict %d, want Pass", got.Verdict)
+	}
+}
+
+func TestListsMostSpecificWins(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lists.txt")
+	writeFile(t, path, `
+# phishing
+block example.com
+allow docs.example.com
+block evil.test   # trailing comment
+`)
+	l, err := LoadLists(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		url  string
+		want Verdict
+	}{
+		{"https://example.com", Block},
+		{"https://www.example.com/login", Block},
+		{"https://docs.example.com/guide", Allow},
+		{"https://a.docs.example.com", Allow},
+		{"https://notexample.com", Pass},
+		{"https://EVIL.test.", Block},
+	}
+	for _, tt := range tests {
+		if got := screenOne(t, l, tt.url); got.Verdict != tt.want {
+			t.Errorf("%s: verdict %d, want %d", tt.url, got.Verdict, tt.want)
+		}
+	}
+}
+
+func TestListsReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lists.txt")
+	writeFile(t, path, "block example.com\n")
+	l, err := LoadLists(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A broken file keeps the previous lists
+	writeFile(t, path, "deny example.com\n")
+	if err := l.Reload(); err == nil {
+		t.Fatal("Reload accepted an invalid file")
+	}
+	if got := screenOne(t, l, "https://example.com"); got.Verdict != Block {
+		t.Fatalf("after failed reload: verdict %d, want Block", got.Verdict)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go l.Watch(ctx, 10*time.Millisecond)
+
+	writeFile(t, path, "allow example.com\nblock example.org\n")
+	deadline := time.Now().Add(2 * time.Second)
+	for screenOne(t, l, "https://example.com").Verdict != Allow {
+		if time.Now().After(deadline) {
+			t.Fatal("Watch did not pick up the new file")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := screenOne(t, l, "https://example.org"); got.Verdict != Block {
+		t.Errorf("example.org: verdict %d, want Block", got.Verdict)
+	}
+}
+
+type failingProvider struct{}
+
+func (failingProvider) Check(context.Context, string) (bool, string, error) {
+	return false, "", errors.New("provider unavailable")
+}
+
+func TestChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lists.txt")
+	writeFile(t, path, "allow trusted.example\n")
+	lists, err := LoadLists(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep := &Reputation{Provider: StaticReputation{
+		"phish.example":   "phishing",
+		"trusted.example": "false positive",
+	}}
+	chain := &Chain{Screeners: []Screener{lists, &Network{}, rep}}
+	ctx := context.Background()
+
+	res, err := chain.Screen(ctx, "https://phish.example/login")
+	if err != nil || res.Verdict != Block || res.Reason == "" {
+		t.Errorf("phish: %+v, %v; want Block with reason", res, err)
+	}
+	// Allowlisted hosts skip the rest of the chain
+	if res, _ := chain.Screen(ctx, "https://trusted.example"); res.Verdict != Allow {
+		t.Errorf("trusted: verdict %d, want Allow", res.Verdict)
+	}
+	if res, _ := chain.Screen(ctx, "https://fine.example"); res.Verdict != Pass {
+		t.Errorf("fine: verdict %d, want Pass", res.Verdict)
+	}
+
+	// Provider failures are skipped by default and fatal with FailClosed
+	chain.Screeners = []Screener{&Reputation{Provider: failingProvider{}}}
+	if res, err := chain.Screen(ctx, "https://fine.example"); err != nil || res.Verdict != Pass {
+		t.Errorf("fail open: %+v, %v; want Pass", res, err)
+	}
+	chain.FailClosed = true
+	if _, err := chain.Screen(ctx, "https://fine.example"); err == nil {
+		t.Error("fail closed: want error")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+// Reasons reported for rows ImportURLs refuses.
+const (
+	RejectInvalid   = "invalid"   // failed URL or custom-code validation
+	RejectDuplicate = "duplicate" // domain and short code appear twice in the same batch
+	RejectConflict  = "conflict"  // short code already points somewhere else or is cooling down
+	RejectUnsafe    = "unsafe"    // a destination was rejected by the screener
+)
+
+// ImportRejection describes a row that was not imported.
+type ImportRejection struct {
+	Index  int    // position in the batch passed to ImportURLs
+	Reason string // one of the Reject* constants
+	Detail string
+}
+
+// ImportResult summarizes one ImportURLs batch.
+type ImportResult struct {
+	Imported int
+	// Unchanged counts rows whose code already exists with the same destination,
+	// e.g. when a batch is replayed after resuming from a checkpoint.
+	Unchanged int
+	Rejected  []ImportRejection
+}
+
+// ImportURLs validates and bulk-inserts links that already have short codes,
+// e.g. when migrating from another shortener. Invalid rows, conflicting codes
+// and destinations the screener rejects are reported instead of failing the batch.
+// A screener that can't be reached fails the batch.
+func (s *URLService) ImportURLs(ctx context.Context, urls []*models.URL) (*ImportResult, error) {
+	res := &ImportResult{}
+	now := time.Now().UTC()
+
+	valid := make([]*models.URL, 0, len(urls))
+	index := make(map[models.LinkKey]int, len(urls))
+	for i, u := range urls {
+		longURL, err := s.canonicalURL(u.LongURL)
+		if err != nil {
+			res.Rejected = append(res.Rejected, ImportRejection{Index: i, Reason: RejectInvalid, Detail: err.Error()})
+			continue
+		}
+		u.LongURL = longURL
+		if err := s.checkCustomCode(ctx, u.ShortCode, ""); err != nil {
+			if !errors.Is(err, ErrInvalidCustomCode) {
+				return nil, err
+			}
+			res.Rejected = append(res.Rejected, ImportRejection{Index: i, Reason: RejectInvalid, Detail: err.Error()})
+			continue
+		}
+		tags, err := normalizeMetadata(u.Title, u.Notes, u.Tags)
+		if err != nil {
+			res.Rejected = append(res.Rejected, ImportRejection{Index: i, Reason: RejectInvalid, Detail: err.Error()})
+			continue
+		}
+		u.Tags = tags
+		domain, err := s.ResolveDomain(u.Domain)
+		if err != nil {
+			res.Rejected = append(res.Rejected, ImportRejection{Index: i, Reason: RejectInvalid, Detail: err.Error()})
+			continue
+		}
+		u.Domain = domain
+		if _, dup := index[u.Key()]; dup {
+			res.Rejected = append(res.Rejected, ImportRejection{Index: i, Reason: RejectDuplicate, Detail: "short code repeated in batch"})
+			continue
+		}
+		if err := s.screenDestinations(ctx, u.LongURL, nil, nil); err != nil {
+			if !errors.Is(err, ErrUnsafeURL) {
+				return nil, err
+			}
+			res.Rejected = append(res.Rejected, ImportRejection{Index: i, Reason: RejectUnsafe, Detail: err.Error()})
+			continue
+		}
+		if u.CreatedAt.IsZero() {
+			u.CreatedAt = now
+		}
+		index[u.Key()] = i
+		valid = append(valid, u)
+	}
+
+	skipped, err := s.repo.BulkInsert(ctx, valid)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, u := range valid {
+		existing, ok := skipped[u.Key()]
+		switch {
+		case !ok:
+			res.Imported++
+		case existing.AvailableAt != nil:
+			res.Rejected = append(res.Rejected, ImportRejection{
+				Index:  index[u.Key()],
+				Reason: RejectConflict,
+				Detail: coolingDownError(*existing.AvailableAt).Error(),
+			})
+		case existing.LongURL == u.LongURL:
+			res.Unchanged++
+		default:
+			res.Rejected = append(res.Rejected, ImportRejection{
+				Index:  index[u.Key()],
+				Reason: RejectConflict,
+				Detail: ErrCustomCodeTaken.Error(),
+			})
+		}
+	}
+	return res, nil
+}
+
+// ExportURLs streams every link matching filter to fn.
+func (s *URLService) ExportURLs(ctx context.Context, filter models.URLFilter, fn func(*models.URL) error) error {
+	return s.repo.ExportURLs(ctx, filter, fn)
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+	"github.com/Siddarth2230/url-shortener/internal/screen"
+)
+
+// expectBulkInsert expects one BulkInsert of rows, answers the existing-code
+// lookup with existing, inserts the given codes and queues events link.created
+// events for owner "team".
+func expectBulkInsert(mock sqlmock.Sqlmock, rows int, existing *sqlmock.Rows, events int, inserted ...string) {
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE TEMP TABLE import_staging").WillReturnResult(sqlmock.NewResult(0, 0))
+	copyIn := mock.ExpectPrepare("COPY")
+	for i := 0; i <= rows; i++ { // the rows, then the flush
+		copyIn.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("UNION ALL")).WillReturnRows(existing)
+	ids := sqlmock.NewRows([]string{"id", "domain", "short_code", "status"})
+	for i, code := range inserted {
+		ids.AddRow(int64(i+1), "", code, models.StatusActive)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO urls")).WillReturnRows(ids)
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM import_staging")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO tags")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO url_tags")).WillReturnResult(sqlmock.NewResult(0, 0))
+	for i := 0; i < events; i++ {
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO webhook_outbox")).
+			WithArgs("team", sqlmock.AnyArg(), models.EventLinkCreated, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+}
+
+// Imported codes that are cooling down say so, like single creates do.
+func TestImportURLsCoolingDown(t *testing.T) {
+	s, mock := newTestService(t)
+	availableAt := time.Now().Add(time.Hour)
+
+	for i := 0; i < 3; i++ {
+		mock.ExpectQuery(regexp.QuoteMeta("FROM reserved_codes")).WillReturnRows(sqlmock.NewRows([]string{"word", "kind"}))
+	}
+	expectBulkInsert(mock, 3, sqlmock.NewRows([]string{"domain", "short_code", "long_url", "available_at"}).
+		AddRow("", "taken", "https://example.com/other", nil).
+		AddRow("", "same", "https://example.com/same", nil).
+		AddRow("", "cooling", "", availableAt), 0)
+
+	res, err := s.ImportURLs(context.Background(), []*models.URL{
+		{ShortCode: "taken", LongURL: "https://example.com/taken"},
+		{ShortCode: "same", LongURL: "https://example.com/same"},
+		{ShortCode: "cooling", LongURL: "https://example.com/cooling"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Imported != 0 || res.Unchanged != 1 || len(res.Rejected) != 2 {
+		t.Fatalf("result = %+v", res)
+	}
+	want := map[int]string{0: ErrCustomCodeTaken.Error(), 2: coolingDownError(availableAt).Error()}
+	for _, r := range res.Rejected {
+		if r.Reason != RejectConflict || r.Detail != want[r.Index] {
+			t.Errorf("rejection %+v, want conflict %q", r, want[r.Index])
+		}
+	}
+	if !strings.Contains(want[2], availableAt.UTC().Format(time.RFC3339)) || !errors.Is(coolingDownError(availableAt), ErrCodeCoolingDown) {
+		t.Errorf("cooldown detail %q", want[2])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// Imported links with an owner queue link.created in the import transaction,
+// like links created one at a time; skipped and unowned links queue nothing.
+func TestImportURLsQueuesCreatedEvents(t *testing.T) {
+	s, mock := newTestService(t)
+
+	for i := 0; i < 3; i++ {
+		mock.ExpectQuery(regexp.QuoteMeta("FROM reserved_codes")).WillReturnRows(sqlmock.NewRows([]string{"word", "kind"}))
+	}
+	expectBulkInsert(mock, 3, sqlmock.NewRows([]string{"domain", "short_code", "long_url", "available_at"}).
+		AddRow("", "taken", "https://example.com/other", nil), 1, "owned", "public")
+
+	res, err := s.ImportURLs(context.Background(), []*models.URL{
+		{ShortCode: "owned", LongURL: "https://example.com/owned", Owner: "team"},
+		{ShortCode: "public", LongURL: "https://example.com/public"},
+		{ShortCode: "taken", LongURL: "https://example.com/taken", Owner: "team"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Imported != 2 || len(res.Rejected) != 1 {
+		t.Fatalf("result = %+v", res)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// Imported destinations are screened like created ones; blocked rows are rejected.
+func TestImportURLsScreened(t *testing.T) {
+	s, mock := newTestService(t)
+	s.Screener = &screen.Chain{Screeners: []screen.Screener{screen.Func(func(_ context.Context, u *url.URL) (screen.Result, error) {
+		if u.Hostname() == "evil.example" {
+			return screen.Result{Verdict: screen.Block, Reason: "listed"}, nil
+		}
+		return screen.Result{}, nil
+	})}}
+
+	for i := 0; i < 3; i++ {
+		mock.ExpectQuery(regexp.QuoteMeta("FROM reserved_codes")).WillReturnRows(sqlmock.NewRows([]string{"word", "kind"}))
+	}
+	expectBulkInsert(mock, 1, sqlmock.NewRows([]string{"domain", "short_code", "long_url", "available_at"}), 0, "good")
+
+	res, err := s.ImportURLs(context.Background(), []*models.URL{
+		{ShortCode: "phish", LongURL: "https://evil.example/login"},
+		{ShortCode: "good", LongURL: "https://example.com"},
+		{ShortCode: "worse", LongURL: "https://EVIL.example/reset"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Imported != 1 || len(res.Rejected) != 2 {
+		t.Fatalf("result = %+v", res)
+	}
+	for i, r := range res.Rejected {
+		if r.Index != i*2 || r.Reason != RejectUnsafe || !strings.Contains(r.Detail, "listed") {
+			t.Errorf("rejection %+v, want row %d unsafe", r, i*2)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// A screener that fails closed stops the batch with a retryable error.
+func TestImportURLsScreenerUnavailable(t *testing.T) {
+	s, mock := newTestService(t)
+	s.Screener = &screen.Chain{FailClosed: true, Screeners: []screen.Screener{screen.Func(func(context.Context, *url.URL) (screen.Result, error) {
+		return screen.Result{}, errors.New("provider timeout")
+	})}}
+	mock.ExpectQuery(regexp.QuoteMeta("FROM reserved_codes")).WillReturnRows(sqlmock.NewRows([]string{"word", "kind"}))
+
+	_, err := s.ImportURLs(context.Background(), []*models.URL{{ShortCode: "code", LongURL: "https://example.com"}})
+	var serr *Error
+	if !errors.Is(err, ErrScreenUnavailable) || !errors.As(err, &serr) || serr.Kind != KindUnavailable {
+		t.Fatalf("err = %v, want screening unavailable", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+	"github.com/Siddarth2230/url-shortener/internal/urlnorm"
+)
+
+// canonicalURL validates rawURL and rewrites it into the canonical form that is
+// stored and compared for deduplication.
+func (s *URLService) canonicalURL(rawURL string) (string, error) {
+	if rawURL == "" {
+		return "", fmt.Errorf("%w: URL is required", ErrInvalidURL)
+	}
+	canonical, err := urlnorm.Canonicalize(rawURL, s.Canonical)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	return canonical, nil
+}
+
+// canonicalizeRequest rewrites the main, rule and variant destinations of req.
+// Rules and variants are copied so the caller's slices are left untouched.
+func (s *URLService) canonicalizeRequest(req *models.ShortenRequest) error {
+	longURL, err := s.canonicalURL(req.URL)
+	if err != nil {
+		return err
+	}
+	req.URL = longURL
+
+	if len(req.Rules) > 0 {
+		req.Rules = append([]models.RedirectRule(nil), req.Rules...)
+		for i := range req.Rules {
+			dest, err := s.canonicalURL(req.Rules[i].Destination)
+			if err != nil {
+				return fmt.Errorf("%w: rule %d: %v", ErrInvalidRules, i+1, err)
+			}
+			req.Rules[i].Destination = dest
+		}
+	}
+	if len(req.Variants) > 0 {
+		req.Variants = append([]models.Variant(nil), req.Variants...)
+		for i := range req.Variants {
+			dest, err := s.canonicalURL(req.Variants[i].Destination)
+			if err != nil {
+				return fmt.Errorf("%w: variant %d: %v", ErrInvalidVariants, i+1, err)
+			}
+			req.Variants[i].Destination = dest
+		}
+	}
+	return nil
+}
+
+// isPlainRequest reports whether req asks for nothing but a generated short
+// code for a destination, so an existing identical link can be returned instead.
+func isPlainRequest(req models.ShortenRequest) bool {
+	return req.CustomCode == "" && req.Owner == "" && req.Password == "" && req.MaxClicks == nil &&
+		len(req.Rules) == 0 && len(req.Variants) == 0 && !req.Interstitial &&
+		(req.QueryPolicy == "" || req.QueryPolicy == models.QueryIgnore) && len(req.UTM.Params()) == 0 &&
+		req.Title == "" && req.Notes == "" && len(req.Tags) == 0
+}
+
+// findDuplicate returns an existing plain link for the canonical longURL on
+// domain, or nil. Lookup errors are logged and treated as "no duplicate".
+func (s *URLService) findDuplicate(ctx context.Context, domain, longURL string) *models.URL {
+	u, err := s.repo.FindPlainByLongURL(ctx, domain, longURL)
+	if err != nil {
+		s.log(ctx).Warn("dedup lookup failed", "err", err)
+		return nil
+	}
+	return u
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Siddarth2230/url-shortener/internal/codepolicy"
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+var (
+	ErrInvalidCustomCode   = newError(KindInvalid, "invalid_custom_code", "custom_code", "invalid custom code")
+	ErrInvalidReservedWord = newError(KindInvalid, "invalid_reserved_word", "word", "invalid reserved word")
+	ErrReservedWordMissing = newError(KindNotFound, "reserved_word_not_found", "word", "reserved word not found")
+)
+
+// checkCustomCode applies the code policy for the requester's tier and then
+// the admin-managed reserved list. Rejections wrap ErrInvalidCustomCode.
+func (s *URLService) checkCustomCode(ctx context.Context, code, tier string) error {
+	if err := s.Codes.Check(code, tier); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCustomCode, err)
+	}
+	w, err := s.repo.MatchReservedWord(ctx, code, codepolicy.Variants(code))
+	if err != nil {
+		return err
+	}
+	if w == nil {
+		return nil
+	}
+	if w.Kind == models.ReservedBlocked {
+		return fmt.Errorf("%w: %v", ErrInvalidCustomCode, codepolicy.ErrBlocked)
+	}
+	return fmt.Errorf("%w: %v: %q", ErrInvalidCustomCode, codepolicy.ErrReserved, code)
+}
+
+// AddReservedWord adds a word to the reserved list. Reserved words can't be
+// claimed as codes; blocked words can't appear anywhere in one and are stored
+// folded, so leetspeak spellings of them are blocked too.
+func (s *URLService) AddReservedWord(ctx context.Context, word, kind, note string) (*models.ReservedWord, error) {
+	switch kind {
+	case models.ReservedExact:
+		word = strings.ToLower(strings.TrimSpace(word))
+	case models.ReservedBlocked:
+		word = codepolicy.Fold(word)
+	default:
+		return nil, fmt.Errorf("%w: unknown kind %q", ErrInvalidReservedWord, kind)
+	}
+	if word == "" || len(word) > codepolicy.MaxLength {
+		return nil, fmt.Errorf("%w: must be 1-%d characters", ErrInvalidReservedWord, codepolicy.MaxLength)
+	}
+	w := &models.ReservedWord{Word: word, Kind: kind, Note: note}
+	if err := s.repo.AddReservedWord(ctx, w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// RemoveReservedWord deletes a word from the reserved list, as given or
+// folded like blocked words are stored.
+func (s *URLService) RemoveReservedWord(ctx context.Context, word string) error {
+	ok, err := s.repo.RemoveReservedWord(ctx, strings.ToLower(strings.TrimSpace(word)))
+	if err == nil && !ok {
+		ok, err = s.repo.RemoveReservedWord(ctx, codepolicy.Fold(word))
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrReservedWordMissing
+	}
+	return nil
+}
+
+// ListReservedWords returns the admin-managed reserved list.
+func (s *URLService) ListReservedWords(ctx context.Context) ([]*models.ReservedWord, error) {
+	return s.repo.ListReservedWords(ctx)
+}
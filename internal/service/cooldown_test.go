@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+// seqGenerator hands out codes in order.
+type seqGenerator struct{ codes []string }
+
+func (g *seqGenerator) Generate(context.Context) (string, error) {
+	code := g.codes[0]
+	g.codes = g.codes[1:]
+	return code, nil
+}
+
+func expectTombstone(mock sqlmock.Sqlmock, code string, availableAt *time.Time) {
+	rows := sqlmock.NewRows([]string{"available_at"})
+	if availableAt != nil {
+		rows.AddRow(*availableAt)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("FROM code_tombstones")).WithArgs("", code).WillReturnRows(rows)
+}
+
+func TestShortenCustomCodeCoolingDown(t *testing.T) {
+	s, mock := newTestService(t)
+	availableAt := time.Now().Add(24 * time.Hour)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM reserved_codes")).WillReturnRows(sqlmock.NewRows([]string{"word", "kind"}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS")).WithArgs("", "promo").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	expectTombstone(mock, "promo", &availableAt)
+
+	_, err := s.ShortenURL(context.Background(), models.ShortenRequest{URL: "https://example.com", CustomCode: "promo"})
+	if !errors.Is(err, ErrCodeCoolingDown) || !strings.Contains(err.Error(), availableAt.UTC().Format(time.RFC3339)) {
+		t.Fatalf("err = %v, want cooling down until %s", err, availableAt.UTC().Format(time.RFC3339))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// Codes still in use report as taken, not cooling down.
+func TestShortenCustomCodeTaken(t *testing.T) {
+	s, mock := newTestService(t)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM reserved_codes")).WillReturnRows(sqlmock.NewRows([]string{"word", "kind"}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS")).WithArgs("", "promo").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	expectTombstone(mock, "promo", nil)
+
+	_, err := s.ShortenURL(context.Background(), models.ShortenRequest{URL: "https://example.com", CustomCode: "promo"})
+	if !errors.Is(err, ErrCustomCodeTaken) {
+		t.Fatalf("err = %v, want taken", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGeneratorSkipsTombstonedCodes(t *testing.T) {
+	s, mock := newTestService(t)
+	s.generator = &seqGenerator{codes: []string{"old1", "new1"}}
+	availableAt := time.Now().Add(time.Hour)
+	expectTombstone(mock, "old1", &availableAt)
+	expectTombstone(mock, "new1", nil)
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO urls")).
+		WithArgs("", "new1", "https://example.com/", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "active"))
+
+	resp, err := s.ShortenURL(context.Background(), models.ShortenRequest{URL: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ShortCode != "new1" {
+		t.Errorf("code = %q, want the code that isn't cooling down", resp.ShortCode)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// The tombstone keeps the stored spelling, whatever case the caller used.
+func TestDeleteTombstonesStoredCode(t *testing.T) {
+	s, mock := newTestService(t)
+	s.CaseInsensitive = true
+	s.repo.CaseInsensitive = true
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("DELETE FROM urls")).WithArgs("", "PROMO").WillReturnRows(sqlmock.NewRows(urlRow[:18]).
+		AddRow(1, "", "Promo", "https://example.com", time.Now(), nil, nil, nil, nil, nil, nil, "active", nil, false, nil, nil, "ignore", nil))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO code_tombstones")).
+		WithArgs("", "Promo", "deleted", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := s.DeleteShortCode(context.Background(), "", "PROMO"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
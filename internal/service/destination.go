@@ -0,0 +1,86 @@
+package service
+
+import (
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+	"github.com/Siddarth2230/url-shortener/internal/passthrough"
+	"github.com/Siddarth2230/url-shortener/internal/rules"
+	"github.com/Siddarth2230/url-shortener/internal/split"
+)
+
+// Visitor describes who follows a link, as far as redirect rules and split
+// variants care.
+type Visitor struct {
+	IP             string
+	UserAgent      string
+	AcceptLanguage string
+	// Query is the query string of the short URL, forwarded according to the
+	// link's passthrough policy.
+	Query url.Values
+	// VariantID is the split variant the visitor was assigned before (e.g. from a
+	// cookie). It is kept if the link still has it.
+	VariantID int64
+}
+
+// Destination is where a visitor is sent.
+type Destination struct {
+	URL string
+	// VariantID is set when a split variant was chosen so the click can be
+	// attributed to it and the visitor kept on it.
+	VariantID *int64
+	// Personalized is set when the answer depends on the visitor (rules or
+	// variants), so shared caches must not reuse it.
+	Personalized bool
+}
+
+// Destination picks where the visitor goes: the first matching redirect rule,
+// else the visitor's split variant, else LongURL. The link's UTM defaults and
+// query passthrough policy are applied to the result.
+func (s *URLService) Destination(link *models.URL, v Visitor) Destination {
+	d := s.chooseDestination(link, v)
+	d.URL = passthrough.Apply(d.URL, v.Query, link.QueryPolicy, link.UTM)
+	return d
+}
+
+func (s *URLService) chooseDestination(link *models.URL, v Visitor) Destination {
+	if len(link.Rules) == 0 && len(link.Variants) == 0 {
+		return Destination{URL: link.LongURL}
+	}
+
+	if len(link.Rules) > 0 {
+		ev := rules.Evaluator{Countries: s.Countries}
+		dest, ok := ev.Evaluate(link.Rules, rules.Visitor{
+			UserAgent:      v.UserAgent,
+			AcceptLanguage: v.AcceptLanguage,
+			IP:             net.ParseIP(v.IP),
+			Now:            time.Now(),
+		})
+		if ok {
+			return Destination{URL: dest, Personalized: true}
+		}
+	}
+
+	if variant := chooseVariant(link, v); variant != nil {
+		id := variant.ID
+		return Destination{URL: variant.Destination, VariantID: &id, Personalized: true}
+	}
+	return Destination{URL: link.LongURL, Personalized: true}
+}
+
+// chooseVariant assigns the visitor to a split variant. A previous assignment
+// wins; otherwise the variant is derived from a hash of the visitor's IP and
+// user agent, which keeps visitors without a stored assignment sticky too.
+func chooseVariant(link *models.URL, v Visitor) *models.Variant {
+	if len(link.Variants) == 0 {
+		return nil
+	}
+	if v.VariantID != 0 {
+		if variant := split.Find(link.Variants, v.VariantID); variant != nil {
+			return variant
+		}
+	}
+	return split.Choose(link.Variants, link.ShortCode+"|"+v.IP+"|"+v.UserAgent)
+}
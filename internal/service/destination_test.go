@@ -0,0 +1,43 @@
+package service
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+func TestDestination(t *testing.T) {
+	s, _ := newTestService(t)
+	variants := []models.Variant{
+		{ID: 11, Name: "a", Destination: "https://example.com/a", Weight: 1},
+		{ID: 12, Name: "b", Destination: "https://example.com/b", Weight: 1},
+	}
+	mobile := []models.RedirectRule{{Destination: "https://m.example.com", Devices: []string{"mobile"}}}
+	iPhone := "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) Mobile/15E148"
+
+	plain := s.Destination(&models.URL{LongURL: "https://example.com", QueryPolicy: models.QueryMerge, UTM: &models.UTM{Source: "news"}},
+		Visitor{Query: url.Values{"ref": {"x"}}})
+	if u, _ := url.Parse(plain.URL); u.Query().Get("utm_source") != "news" || u.Query().Get("ref") != "x" || plain.Personalized || plain.VariantID != nil {
+		t.Errorf("plain link = %+v", plain)
+	}
+
+	rule := s.Destination(&models.URL{ShortCode: "r", LongURL: "https://example.com", Rules: mobile, Variants: variants}, Visitor{UserAgent: iPhone})
+	if rule.URL != "https://m.example.com" || rule.VariantID != nil || !rule.Personalized {
+		t.Errorf("matching rule = %+v", rule)
+	}
+
+	// No rule matches: the earlier variant is kept, an unknown one is replaced
+	sticky := s.Destination(&models.URL{ShortCode: "r", LongURL: "https://example.com", Rules: mobile, Variants: variants}, Visitor{VariantID: 12})
+	if sticky.URL != "https://example.com/b" || sticky.VariantID == nil || *sticky.VariantID != 12 {
+		t.Errorf("sticky variant = %+v", sticky)
+	}
+	fresh := s.Destination(&models.URL{ShortCode: "r", LongURL: "https://example.com", Variants: variants}, Visitor{VariantID: 99, IP: "203.0.113.7"})
+	if fresh.VariantID == nil || (*fresh.VariantID != 11 && *fresh.VariantID != 12) {
+		t.Errorf("stale variant = %+v", fresh)
+	}
+	again := s.Destination(&models.URL{ShortCode: "r", LongURL: "https://example.com", Variants: variants}, Visitor{IP: "203.0.113.7"})
+	if again.URL != fresh.URL {
+		t.Errorf("same visitor got %q, then %q", fresh.URL, again.URL)
+	}
+}
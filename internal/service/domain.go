@@ -0,0 +1,106 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+var ErrUnknownDomain = newError(KindInvalid, "unknown_domain", "domain", "unknown domain")
+
+// AddDomain registers a branded domain served by this deployment, e.g.
+// "https://go.acme.com". Short URLs on it are built from baseURL and redirects
+// arriving with that Host resolve codes within that domain.
+func (s *URLService) AddDomain(baseURL string) error {
+	parsed, err := url.Parse(baseURL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return fmt.Errorf("invalid domain base URL %q", baseURL)
+	}
+	host := normalizeHost(parsed.Host)
+	if host == s.defaultHost() {
+		return nil // same as BaseURL
+	}
+	if s.domains == nil {
+		s.domains = make(map[string]string)
+	}
+	s.domains[host] = strings.TrimRight(baseURL, "/")
+	return nil
+}
+
+// Domains returns the hosts of every registered branded domain.
+func (s *URLService) Domains() []string {
+	hosts := make([]string, 0, len(s.domains))
+	for h := range s.domains {
+		hosts = append(hosts, h)
+	}
+	return hosts
+}
+
+// DomainForHost maps a request Host header to the domain its links live on.
+// Unknown hosts (direct IP access, the default BaseURL host, ...) map to the
+// default domain "".
+func (s *URLService) DomainForHost(host string) string {
+	host = normalizeHost(host)
+	if _, ok := s.domains[host]; ok {
+		return host
+	}
+	return ""
+}
+
+// ResolveDomain validates a domain named by a client, e.g. when creating a link,
+// and returns it in stored form ("" for the default domain).
+func (s *URLService) ResolveDomain(requested string) (string, error) {
+	if requested == "" {
+		return "", nil
+	}
+	host := normalizeHost(requested)
+	if host == s.defaultHost() {
+		return "", nil
+	}
+	if _, ok := s.domains[host]; !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownDomain, requested)
+	}
+	return host, nil
+}
+
+// ShortURL builds the public short URL for a code on a domain.
+func (s *URLService) ShortURL(domain, code string) string {
+	base := s.BaseURL
+	if domain != "" {
+		base = s.domains[domain]
+	}
+	if base == "" {
+		return code
+	}
+	return fmt.Sprintf("%s/%s", base, code)
+}
+
+func (s *URLService) defaultHost() string {
+	parsed, err := url.Parse(s.BaseURL)
+	if err != nil {
+		return ""
+	}
+	return normalizeHost(parsed.Host)
+}
+
+// normalizeHost lowercases a host and strips the port.
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// cacheKey namespaces a short code by domain for both cache layers. The default
+// domain keeps the bare code so existing cache entries stay valid. Codes are
+// lowercased in case-insensitive mode, so every spelling shares one entry.
+func (s *URLService) cacheKey(domain, shortCode string) string {
+	if s.CaseInsensitive {
+		shortCode = strings.ToLower(shortCode)
+	}
+	if domain == "" {
+		return shortCode
+	}
+	return domain + "/" + shortCode
+}
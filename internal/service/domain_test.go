@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// newDomainService serves go.acme.com next to the default domain. expect
+// answers the next lookup of "docs" on a domain.
+func newDomainService(t *testing.T) (s *URLService, mock sqlmock.Sqlmock, expect func(domain, dest string)) {
+	t.Helper()
+	s, mock = newTestService(t)
+	if err := s.AddDomain("https://go.acme.com"); err != nil {
+		t.Fatal(err)
+	}
+	expect = func(domain, dest string) { expectLink(mock, domain, "docs", dest, nil, nil) }
+	return s, mock, expect
+}
+
+func TestDomainForHost(t *testing.T) {
+	s, _, _ := newDomainService(t)
+	for host, want := range map[string]string{
+		"go.acme.com":      "go.acme.com",
+		"GO.Acme.com:8080": "go.acme.com",
+		"go.acme.com.":     "go.acme.com",
+		"sho.rt":           "",
+		"10.0.0.1:8080":    "",
+	} {
+		if got := s.DomainForHost(host); got != want {
+			t.Errorf("DomainForHost(%q) = %q, want %q", host, got, want)
+		}
+	}
+	if d, err := s.ResolveDomain("Go.Acme.com"); err != nil || d != "go.acme.com" {
+		t.Errorf("ResolveDomain(Go.Acme.com) = %q, %v", d, err)
+	}
+	if _, err := s.ResolveDomain("evil.example"); !errors.Is(err, ErrUnknownDomain) {
+		t.Errorf("ResolveDomain(unknown) = %v, want ErrUnknownDomain", err)
+	}
+	if got := s.ShortURL("go.acme.com", "docs"); got != "https://go.acme.com/docs" {
+		t.Errorf("ShortURL = %q", got)
+	}
+}
+
+// The same code on two domains is two links, cached separately.
+func TestResolveURLPerDomain(t *testing.T) {
+	s, mock, expect := newDomainService(t)
+	ctx := context.Background()
+	expect("", "https://example.com/default")
+	expect("go.acme.com", "https://acme.com/docs")
+
+	for i := 0; i < 2; i++ { // the second round comes from L1
+		if u, err := s.ResolveURL(ctx, "", "docs"); err != nil || u.LongURL != "https://example.com/default" {
+			t.Errorf("default domain: %v, %v", u, err)
+		}
+		if u, err := s.ResolveURL(ctx, "go.acme.com", "docs"); err != nil || u.LongURL != "https://acme.com/docs" {
+			t.Errorf("go.acme.com: %v, %v", u, err)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// Purging a code on one domain leaves the other domain's entry cached.
+func TestPurgeCacheScopedToDomain(t *testing.T) {
+	s, mock, expect := newDomainService(t)
+	ctx := context.Background()
+	expect("", "https://example.com/default")
+	expect("go.acme.com", "https://acme.com/docs")
+	for _, domain := range []string{"", "go.acme.com"} {
+		if _, err := s.ResolveURL(ctx, domain, "docs"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.PurgeCache(ctx, "go.acme.com", "docs"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.l1Cache.Get("go.acme.com/docs"); ok {
+		t.Error("go.acme.com/docs is still cached")
+	}
+	if _, ok := s.l1Cache.Get("docs"); !ok {
+		t.Error("purging go.acme.com evicted the default domain's docs")
+	}
+
+	// Only the purged domain goes back to the database
+	expect("go.acme.com", "https://acme.com/new")
+	if u, err := s.ResolveURL(ctx, "go.acme.com", "docs"); err != nil || u.LongURL != "https://acme.com/new" {
+		t.Errorf("go.acme.com after purge: %v, %v", u, err)
+	}
+	if u, err := s.ResolveURL(ctx, "", "docs"); err != nil || u.LongURL != "https://example.com/default" {
+		t.Errorf("default domain after purge: %v, %v", u, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+// Kind classifies service errors. The API layers map each kind to a status,
+// so a new error only needs the right kind to be answered correctly.
+type Kind int
+
+const (
+	KindInvalid         Kind = iota + 1 // the request breaks a validation rule
+	KindUnauthenticated                 // missing or invalid API key
+	KindForbidden                       // password required or wrong, link of another key
+	KindNotFound                        // no such link, subscription, delivery or word
+	KindConflict                        // the short code is taken or cooling down
+	KindGone                            // the link expired, was disabled or ran out of clicks
+	KindRejected                        // a destination failed screening
+	KindRateLimited                     // too many attempts
+	KindLegal                           // the link was taken down for legal reasons
+	KindUnavailable                     // a temporary failure worth retrying
+)
+
+// Error is an error a client can act on. Code is a stable machine-readable
+// identifier such as "invalid_custom_code"; Field names the request field at
+// fault, if any.
+//
+// The exported Err* values are *Error sentinels. errors.Is compares codes,
+// so it also matches copies made by WithField, and errors.As extracts the
+// details through any fmt.Errorf("%w: ...") wrapping.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Field   string
+	Message string
+}
+
+func newError(kind Kind, code, field, message string) *Error {
+	return &Error{Kind: kind, Code: code, Field: field, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is reports whether target is an *Error with the same code.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithField returns a copy of e about the given request field.
+func (e *Error) WithField(field string) *Error {
+	c := *e
+	c.Field = field
+	return &c
+}
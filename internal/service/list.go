@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+	"github.com/Siddarth2230/url-shortener/internal/repository"
+)
+
+var (
+	ErrInvalidMetadata = newError(KindInvalid, "invalid_metadata", "", "invalid link metadata")
+	ErrInvalidListing  = newError(KindInvalid, "invalid_listing", "", "invalid listing parameters")
+)
+
+// Limits for link metadata.
+const (
+	maxTitleLen  = 200
+	maxNotesLen  = 2000
+	maxTags      = 20
+	maxTagLen    = 50
+	defaultLimit = 50
+	maxLimit     = 200
+)
+
+// normalizeMetadata validates title, notes and tags and returns the tags
+// trimmed, lowercased and deduplicated.
+func normalizeMetadata(title, notes string, tags []string) ([]string, error) {
+	if utf8.RuneCountInString(title) > maxTitleLen {
+		return nil, fmt.Errorf("%w: title is longer than %d characters", ErrInvalidMetadata.WithField("title"), maxTitleLen)
+	}
+	if utf8.RuneCountInString(notes) > maxNotesLen {
+		return nil, fmt.Errorf("%w: notes are longer than %d characters", ErrInvalidMetadata.WithField("notes"), maxNotesLen)
+	}
+	if len(tags) > maxTags {
+		return nil, fmt.Errorf("%w: at most %d tags", ErrInvalidMetadata.WithField("tags"), maxTags)
+	}
+	var out []string
+	seen := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || utf8.RuneCountInString(t) > maxTagLen {
+			return nil, fmt.Errorf("%w: tags must be 1-%d characters", ErrInvalidMetadata.WithField("tags"), maxTagLen)
+		}
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// ListURLs returns one page of links. Zero options list the newest links first.
+func (s *URLService) ListURLs(ctx context.Context, opts models.ListOptions) (*models.URLPage, error) {
+	if opts.Sort == "" {
+		opts.Sort = models.SortNewest
+	}
+	if !repository.ValidSort(opts.Sort) {
+		return nil, fmt.Errorf("%w: unknown sort %q", ErrInvalidListing.WithField("sort"), opts.Sort)
+	}
+	switch opts.Filter.Expiry {
+	case "", models.ExpiryActive, models.ExpiryExpired:
+	default:
+		return nil, fmt.Errorf("%w: expiry must be active or expired", ErrInvalidListing.WithField("expiry"))
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = defaultLimit
+	}
+	if opts.Limit > maxLimit {
+		opts.Limit = maxLimit
+	}
+	opts.Query = strings.TrimSpace(opts.Query)
+	opts.Filter.Tag = strings.ToLower(strings.TrimSpace(opts.Filter.Tag))
+
+	page, err := s.repo.ListURLs(ctx, opts)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidListing.WithField("cursor"), err)
+	}
+	return page, err
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+// GetURL returns the stored link for a short code on a domain, including expired ones.
+func (s *URLService) GetURL(ctx context.Context, domain, shortCode string) (*models.URL, error) {
+	u, err := s.repo.FindAnyByShortCode(ctx, domain, shortCode)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrNotFound
+	}
+	return u, nil
+}
+
+// UpdateLongURL changes the destination of an existing short code.
+func (s *URLService) UpdateLongURL(ctx context.Context, domain, shortCode, longURL string) (*models.URL, error) {
+	longURL, err := s.canonicalURL(longURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.screenDestinations(ctx, longURL, nil, nil); err != nil {
+		return nil, err
+	}
+
+	u, err := s.repo.UpdateLongURL(ctx, domain, shortCode, longURL)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrNotFound
+	}
+
+	if err := s.PurgeCache(ctx, domain, shortCode); err != nil {
+		s.log(ctx).Warn("purge cache after update failed", "code", shortCode, "err", err)
+	}
+	return u, nil
+}
+
+// ExpireShortCode sets the expiry of a short code. A nil expiresAt removes the expiry.
+func (s *URLService) ExpireShortCode(ctx context.Context, domain, shortCode string, expiresAt *time.Time) (*models.URL, error) {
+	u, err := s.repo.UpdateExpiry(ctx, domain, shortCode, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrNotFound
+	}
+
+	if err := s.PurgeCache(ctx, domain, shortCode); err != nil {
+		s.log(ctx).Warn("purge cache after expiry change failed", "code", shortCode, "err", err)
+	}
+	return u, nil
+}
+
+// SearchURLs finds links whose code or destination contains q.
+func (s *URLService) SearchURLs(ctx context.Context, q string, limit int) ([]*models.URL, error) {
+	if limit <= 0 || limit > 1000 {
+		limit = 50
+	}
+	return s.repo.Search(ctx, q, limit)
+}
+
+// GetClickStats returns click statistics for a short code over the last `days` days.
+func (s *URLService) GetClickStats(ctx context.Context, domain, shortCode string, days int) (*models.ClickStats, error) {
+	u, err := s.GetURL(ctx, domain, shortCode)
+	if err != nil {
+		return nil, err
+	}
+	if days <= 0 {
+		days = 30
+	}
+	stats, err := s.repo.ClickStatsByURLID(ctx, u.ID, days)
+	if err != nil {
+		return nil, err
+	}
+	stats.ShortCode = u.ShortCode
+	return stats, nil
+}
+
+// RecordClick stores a redirect for click statistics.
+func (s *URLService) RecordClick(ctx context.Context, click *models.Click) error {
+	if click.ClickedAt.IsZero() {
+		click.ClickedAt = time.Now().UTC()
+	}
+	return s.repo.SaveClick(ctx, click)
+}
+
+// PurgeCache synchronously removes a short code from both cache layers and tells
+// every other server to drop it from their L1 cache.
+func (s *URLService) PurgeCache(ctx context.Context, domain, shortCode string) error {
+	key := s.cacheKey(domain, shortCode)
+	s.l1Delete(key)
+
+	if s.l2Cache == nil {
+		return nil
+	}
+	if err := s.l2Cache.Delete(ctx, key); err != nil {
+		return err
+	}
+	return s.l2Cache.PublishInvalidation(ctx, key)
+}
+
+// ListenForInvalidations evicts keys from L1 whenever another process purges them.
+// It blocks until ctx is canceled.
+func (s *URLService) ListenForInvalidations(ctx context.Context) {
+	if s.l2Cache == nil {
+		return
+	}
+	err := s.l2Cache.SubscribeInvalidations(ctx, func(key string) {
+		s.l1Delete(key)
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		s.log(ctx).Error("cache invalidation listener stopped", "err", err)
+	}
+}
+
+// PreviewURL describes a link without consuming a click. Password-protected
+// links keep their destination hidden.
+func (s *URLService) PreviewURL(ctx context.Context, domain, shortCode string) (*models.LinkPreview, error) {
+	u, err := s.ResolveURL(ctx, domain, shortCode)
+	if err != nil {
+		return nil, err
+	}
+	clicks, err := s.repo.CountClicks(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &models.LinkPreview{
+		Domain:       u.Domain,
+		ShortCode:    u.ShortCode,
+		ShortURL:     s.ShortURL(u.Domain, u.ShortCode),
+		CreatedAt:    u.CreatedAt,
+		ExpiresAt:    u.ExpiresAt,
+		Clicks:       clicks,
+		Protected:    u.IsProtected(),
+		Quarantined:  u.IsQuarantined(),
+		Interstitial: u.Interstitial,
+	}
+	if !p.Protected {
+		p.Destination = u.LongURL
+	}
+	return p, nil
+}
+
+// CaseCollisions lists links whose codes differ only in case. They have to be
+// renamed or deleted before case-insensitive codes can be switched on.
+func (s *URLService) CaseCollisions(ctx context.Context) ([]*models.CaseCollision, error) {
+	return s.repo.FindCaseCollisions(ctx)
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+var (
+	ErrInvalidPassword  = newError(KindInvalid, "invalid_password", "password", "password must be between 4 and 72 bytes")
+	ErrPasswordRequired = newError(KindForbidden, "password_required", "password", "password required")
+	ErrWrongPassword    = newError(KindForbidden, "wrong_password", "password", "incorrect password")
+	ErrTooManyAttempts  = newError(KindRateLimited, "too_many_attempts", "", "too many password attempts, try again later")
+)
+
+const (
+	// maxPasswordAttempts is how many password attempts a short code accepts per
+	// window. A correct password starts a new count.
+	maxPasswordAttempts = 5
+	passwordFailWindow  = 15 * time.Minute
+)
+
+// hashPassword validates and bcrypt-hashes a link password.
+func hashPassword(password string) (string, error) {
+	if len(password) < 4 || len(password) > 72 { // bcrypt ignores bytes past 72
+		return "", ErrInvalidPassword
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// UnlockURL checks the password of a protected link and returns the link on success.
+// Attempts are rate limited per short code across all servers (via Redis when
+// available). Each attempt is counted before the password is checked, so parallel
+// guesses can't slip past the limit; once it is hit every attempt fails with
+// ErrTooManyAttempts until the window resets.
+func (s *URLService) UnlockURL(ctx context.Context, domain, shortCode, password string) (*models.URL, error) {
+	u, err := s.ResolveURL(ctx, domain, shortCode)
+	if err != nil {
+		return nil, err
+	}
+	if !u.IsProtected() {
+		return u, nil
+	}
+
+	key := s.cacheKey(domain, shortCode)
+	attempts, err := s.countPasswordAttempt(ctx, key)
+	if err != nil {
+		// Fail closed: without the counter we can't enforce the limit
+		s.log(ctx).Warn("count password attempt failed", "code", shortCode, "err", err)
+		return nil, ErrTooManyAttempts
+	}
+	if attempts > maxPasswordAttempts {
+		return nil, ErrTooManyAttempts
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrWrongPassword
+	}
+	if err := s.resetPasswordAttempts(ctx, key); err != nil {
+		s.log(ctx).Warn("reset password attempts failed", "code", shortCode, "err", err)
+	}
+	return u, nil
+}
+
+func passwordAttemptKey(key string) string {
+	return "pwfail:" + key
+}
+
+// countPasswordAttempt atomically counts an attempt and returns the count in
+// the current window, including this one.
+func (s *URLService) countPasswordAttempt(ctx context.Context, key string) (int64, error) {
+	if s.l2Cache != nil {
+		return s.l2Cache.IncrWithTTL(ctx, passwordAttemptKey(key), passwordFailWindow)
+	}
+	return s.localFailures.incr(key), nil
+}
+
+func (s *URLService) resetPasswordAttempts(ctx context.Context, key string) error {
+	if s.l2Cache != nil {
+		return s.l2Cache.Delete(ctx, passwordAttemptKey(key))
+	}
+	s.localFailures.reset(key)
+	return nil
+}
+
+// failureCounter is the in-process fallback for password attempt counting when
+// the service runs without Redis (single instance only).
+type failureCounter struct {
+	mu      sync.Mutex
+	windows map[string]*failureWindow
+}
+
+type failureWindow struct {
+	count int64
+	reset time.Time
+}
+
+// incr counts an attempt and returns the count in the current window.
+func (f *failureCounter) incr(key string) int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.windows == nil {
+		f.windows = make(map[string]*failureWindow)
+	}
+	now := time.Now()
+	w, ok := f.windows[key]
+	if !ok || now.After(w.reset) {
+		// Drop expired windows so the map doesn't grow without bound
+		for k, old := range f.windows {
+			if now.After(old.reset) {
+				delete(f.windows, k)
+			}
+		}
+		w = &failureWindow{reset: now.Add(passwordFailWindow)}
+		f.windows[key] = w
+	}
+	w.count++
+	return w.count
+}
+
+func (f *failureCounter) reset(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.windows, key)
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func protectedLink(t *testing.T) (*URLService, string) {
+	t.Helper()
+	s, mock := newTestService(t)
+	hash, err := bcrypt.GenerateFromPassword([]byte("hunter2"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectLink(mock, "", "secret", "https://example.com/secret", string(hash), nil)
+	return s, "secret"
+}
+
+func TestUnlockURLCorrectPassword(t *testing.T) {
+	s, code := protectedLink(t)
+	ctx := context.Background()
+
+	if _, err := s.UnlockURL(ctx, "", code, "wrong"); !errors.Is(err, ErrWrongPassword) {
+		t.Fatalf("wrong password: err = %v", err)
+	}
+	u, err := s.UnlockURL(ctx, "", code, "hunter2")
+	if err != nil || u.LongURL != "https://example.com/secret" {
+		t.Fatalf("UnlockURL = %v, %v", u, err)
+	}
+	// The correct password resets the count, so the full budget is back
+	for i := 0; i < maxPasswordAttempts; i++ {
+		if _, err := s.UnlockURL(ctx, "", code, "wrong"); !errors.Is(err, ErrWrongPassword) {
+			t.Fatalf("attempt %d after unlocking: err = %v", i+1, err)
+		}
+	}
+}
+
+func TestUnlockURLLockout(t *testing.T) {
+	s, code := protectedLink(t)
+	ctx := context.Background()
+
+	for i := 0; i < maxPasswordAttempts; i++ {
+		if _, err := s.UnlockURL(ctx, "", code, "wrong"); !errors.Is(err, ErrWrongPassword) {
+			t.Fatalf("attempt %d: err = %v", i+1, err)
+		}
+	}
+	// Locked out, even with the right password
+	if _, err := s.UnlockURL(ctx, "", code, "hunter2"); !errors.Is(err, ErrTooManyAttempts) {
+		t.Fatalf("attempt after the limit: err = %v, want ErrTooManyAttempts", err)
+	}
+}
+
+// Parallel guesses are counted before bcrypt runs, so no more than the limit
+// ever get checked.
+func TestUnlockURLConcurrentGuesses(t *testing.T) {
+	s, code := protectedLink(t)
+	if _, err := s.ResolveURL(context.Background(), "", code); err != nil { // warm the cache
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	checked := 0
+	for i := 0; i < 4*maxPasswordAttempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.UnlockURL(context.Background(), "", code, "wrong")
+			switch {
+			case errors.Is(err, ErrWrongPassword):
+				mu.Lock()
+				checked++
+				mu.Unlock()
+			case !errors.Is(err, ErrTooManyAttempts):
+				t.Errorf("err = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if checked != maxPasswordAttempts {
+		t.Errorf("%d guesses were checked, want %d", checked, maxPasswordAttempts)
+	}
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+	"github.com/Siddarth2230/url-shortener/internal/screen"
+)
+
+var (
+	ErrUnsafeURL         = newError(KindRejected, "unsafe_url", "", "destination URL rejected")
+	ErrScreenUnavailable = newError(KindUnavailable, "screening_unavailable", "", "destination screening unavailable")
+)
+
+// screenDestinations runs every destination of a link (main URL, rule and
+// variant destinations) through the screener chain, if one is configured.
+func (s *URLService) screenDestinations(ctx context.Context, longURL string, rs []models.RedirectRule, vs []models.Variant) error {
+	if s.Screener == nil {
+		return nil
+	}
+	dests := []string{longURL}
+	for _, r := range rs {
+		dests = append(dests, r.Destination)
+	}
+	for _, v := range vs {
+		dests = append(dests, v.Destination)
+	}
+	for _, d := range dests {
+		res, err := s.Screener.Screen(ctx, d)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrScreenUnavailable, err)
+		}
+		if res.Verdict == screen.Block {
+			s.log(ctx).Info("destination rejected", "url", d, "reason", res.Reason)
+			return fmt.Errorf("%w: %s", ErrUnsafeURL, res.Reason)
+		}
+	}
+	return nil
+}
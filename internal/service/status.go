@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+var (
+	ErrInvalidStatus = newError(KindInvalid, "invalid_status", "status", "status must be active, disabled or quarantined")
+	ErrInvalidReport = newError(KindInvalid, "invalid_report", "reason", "reason must be phishing, malware, spam or other")
+)
+
+// DefaultReportThreshold is how many distinct reporters quarantine a link when
+// URLService.ReportThreshold is unset.
+const DefaultReportThreshold = 5
+
+const maxReportDetails = 2000
+
+// SetStatus changes the status of a link (e.g. disabling it after an abuse review
+// or reactivating a quarantined one) and purges it from every cache.
+func (s *URLService) SetStatus(ctx context.Context, domain, shortCode, status, reason string) (*models.URL, error) {
+	switch status {
+	case models.StatusActive:
+		reason = ""
+	case models.StatusDisabled, models.StatusQuarantined:
+	default:
+		return nil, ErrInvalidStatus
+	}
+
+	u, err := s.repo.UpdateStatus(ctx, domain, shortCode, status, reason)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrNotFound
+	}
+
+	if err := s.PurgeCache(ctx, domain, shortCode); err != nil {
+		s.log(ctx).Warn("purge cache after status change failed", "code", shortCode, "err", err)
+	}
+	s.log(ctx).Info("status changed", "domain", domain, "code", shortCode, "status", status, "reason", reason)
+	return u, nil
+}
+
+// ReportLink records an abuse report. Once ReportThreshold distinct reporters
+// have flagged an active link since its last status change, the link is
+// quarantined automatically and purged from every cache.
+func (s *URLService) ReportLink(ctx context.Context, domain, shortCode string, report *models.AbuseReport) error {
+	switch report.Reason {
+	case models.ReportPhishing, models.ReportMalware, models.ReportSpam, models.ReportOther:
+	default:
+		return ErrInvalidReport
+	}
+	report.Details = strings.TrimSpace(report.Details)
+	if len(report.Details) > maxReportDetails {
+		report.Details = report.Details[:maxReportDetails]
+	}
+
+	u, err := s.GetURL(ctx, domain, shortCode)
+	if err != nil {
+		return err
+	}
+	report.URLID = u.ID
+	if report.CreatedAt.IsZero() {
+		report.CreatedAt = time.Now().UTC()
+	}
+
+	reporters, err := s.repo.SaveReport(ctx, report)
+	if err != nil {
+		return err
+	}
+
+	threshold := s.ReportThreshold
+	if threshold <= 0 {
+		threshold = DefaultReportThreshold
+	}
+	if reporters < threshold || u.Status != models.StatusActive {
+		return nil
+	}
+
+	quarantined, err := s.repo.Quarantine(ctx, u.ID, fmt.Sprintf("%d abuse reports", reporters))
+	if err != nil {
+		return err
+	}
+	if quarantined {
+		s.log(ctx).Warn("quarantined after abuse reports", "domain", domain, "code", shortCode, "reporters", reporters)
+		if err := s.PurgeCache(ctx, domain, shortCode); err != nil {
+			s.log(ctx).Warn("purge cache after quarantine failed", "code", shortCode, "err", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/Siddarth2230/url-shortener/pkg/metrics"
+	"github.com/Siddarth2230/url-shortener/pkg/tracing"
+)
+
+// ExpirySweeper periodically removes links that expired more than Grace ago,
+// archiving them to urls_archive unless Archive is off. Replicas coordinate
+// through a Postgres advisory lock, so only one sweeps at a time.
+type ExpirySweeper struct {
+	svc *URLService
+
+	Interval  time.Duration // time between sweeps, default 5m
+	Grace     time.Duration // how long expired links stay in urls, default 24h
+	BatchSize int           // links removed per transaction, default 500
+	Archive   bool          // copy to urls_archive before deleting, default true
+}
+
+// NewExpirySweeper creates a sweeper with the defaults above.
+func NewExpirySweeper(svc *URLService) *ExpirySweeper {
+	return &ExpirySweeper{
+		svc:       svc,
+		Interval:  5 * time.Minute,
+		Grace:     24 * time.Hour,
+		BatchSize: 500,
+		Archive:   true,
+	}
+}
+
+// Run sweeps every Interval until ctx is canceled.
+func (e *ExpirySweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.Interval)
+	defer ticker.Stop()
+	for {
+		if n, err := e.SweepOnce(ctx); err != nil && ctx.Err() == nil {
+			e.svc.log(ctx).Error("expiry sweep failed", "swept", n, "err", err)
+		} else if n > 0 {
+			e.svc.log(ctx).Info("expiry sweep done", "swept", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SweepOnce removes expired links in batches until none are left (or another
+// replica holds the lock) and returns how many it removed. Swept links are
+// purged from both cache layers so stale entries stop answering.
+func (e *ExpirySweeper) SweepOnce(ctx context.Context) (total int, err error) {
+	ctx, span := tracer.Start(ctx, "ExpirySweeper.SweepOnce")
+	start := time.Now()
+	defer func() {
+		metrics.Observe(ctx, metrics.SweepDuration, time.Since(start).Seconds())
+		span.SetAttributes(attribute.Int("sweep.links", total))
+		tracing.End(span, err)
+	}()
+
+	mode := "delete"
+	if e.Archive {
+		mode = "archive"
+	}
+
+	for {
+		swept, ok, err := e.svc.repo.SweepExpired(ctx, e.Grace, e.BatchSize, e.Archive, e.svc.CodeCooldown)
+		if err != nil || !ok {
+			return total, err
+		}
+		total += len(swept)
+		metrics.ExpiredLinksSwept.WithLabelValues(mode).Add(float64(len(swept)))
+
+		for _, u := range swept {
+			if err := e.svc.PurgeCache(ctx, u.Domain, u.ShortCode); err != nil {
+				e.svc.log(ctx).Warn("purge cache after sweep failed", "code", u.ShortCode, "err", err)
+			}
+		}
+		if len(swept) < e.BatchSize {
+			return total, nil
+		}
+	}
+}
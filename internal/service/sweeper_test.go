@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+// expectSweepBatch expects one SweepExpired transaction that removes the given
+// codes; codes prefixed "team-" are owned and queue a link.expired event.
+func expectSweepBatch(mock sqlmock.Sqlmock, expiresAt time.Time, cooldown time.Duration, codes ...string) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("pg_try_advisory_xact_lock")).WillReturnRows(sqlmock.NewRows([]string{"ok"}).AddRow(true))
+	rows := sqlmock.NewRows(urlRow[:18])
+	for i, code := range codes {
+		var owner interface{}
+		if strings.HasPrefix(code, "team-") {
+			owner = "team"
+		}
+		rows.AddRow(i+1, "", code, "https://example.com/"+code, expiresAt.Add(-time.Hour), expiresAt, owner, nil, nil, nil, nil, "active", nil, false, nil, nil, "ignore", nil)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO urls_archive")).WillReturnRows(rows)
+	for _, code := range codes {
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO code_tombstones")).
+			WithArgs("", code, "expired", expiresAt.Add(cooldown)).WillReturnResult(sqlmock.NewResult(0, 1))
+		if strings.HasPrefix(code, "team-") {
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO webhook_outbox")).
+				WithArgs("team", sqlmock.AnyArg(), models.EventLinkExpired, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+	}
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM code_tombstones")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+}
+
+func TestSweepOnceBatches(t *testing.T) {
+	s, mock := newTestService(t)
+	mock.MatchExpectationsInOrder(true)
+	expiresAt := time.Now().Add(-48 * time.Hour).UTC().Round(time.Second)
+
+	// A full batch means there may be more; the short one ends the sweep
+	expectSweepBatch(mock, expiresAt, s.CodeCooldown, "team-a", "old-b")
+	expectSweepBatch(mock, expiresAt, s.CodeCooldown, "old-c")
+
+	s.l1Cache.Put("old-b", &models.URL{ShortCode: "old-b"})
+	e := NewExpirySweeper(s)
+	e.BatchSize = 2
+	n, err := e.SweepOnce(context.Background())
+	if err != nil || n != 3 {
+		t.Fatalf("SweepOnce = %d, %v; want 3", n, err)
+	}
+	if _, ok := s.l1Cache.Get("old-b"); ok {
+		t.Error("swept link still cached")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// Another replica holding the lock makes the sweep a no-op.
+func TestSweepOnceLockHeld(t *testing.T) {
+	s, mock := newTestService(t)
+	mock.MatchExpectationsInOrder(true)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("pg_try_advisory_xact_lock")).WillReturnRows(sqlmock.NewRows([]string{"ok"}).AddRow(false))
+	mock.ExpectRollback()
+
+	n, err := NewExpirySweeper(s).SweepOnce(context.Background())
+	if err != nil || n != 0 {
+		t.Fatalf("SweepOnce = %d, %v; want 0", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -4,29 +4,53 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
-	"net/url"
-	"regexp"
+	"log/slog"
 	"strings"
 	"time"
 
+	"github.com/Siddarth2230/url-shortener/internal/codepolicy"
 	"github.com/Siddarth2230/url-shortener/internal/models"
+	"github.com/Siddarth2230/url-shortener/internal/passthrough"
 	"github.com/Siddarth2230/url-shortener/internal/repository"
+	"github.com/Siddarth2230/url-shortener/internal/rules"
+	"github.com/Siddarth2230/url-shortener/internal/screen"
+	"github.com/Siddarth2230/url-shortener/internal/split"
+	"github.com/Siddarth2230/url-shortener/internal/urlnorm"
 	"github.com/Siddarth2230/url-shortener/pkg/cache"
 	"github.com/Siddarth2230/url-shortener/pkg/idgen"
+	"github.com/Siddarth2230/url-shortener/pkg/logging"
 	"github.com/Siddarth2230/url-shortener/pkg/metrics"
+	"github.com/Siddarth2230/url-shortener/pkg/tracing"
 	"github.com/jackc/pgconn"
 	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
-	ErrInvalidURL      = errors.New("invalid URL")
-	ErrCustomCodeTaken = errors.New("custom short code already taken")
-	ErrNotFound        = errors.New("short code not found")
-	ErrExpired         = errors.New("short URL expired")
-	ErrGenExhausted    = errors.New("failed to generate unique short code after retries")
+	ErrInvalidURL       = newError(KindInvalid, "invalid_url", "url", "invalid URL")
+	ErrCustomCodeTaken  = newError(KindConflict, "custom_code_taken", "custom_code", "custom short code already taken")
+	ErrCodeCoolingDown  = newError(KindConflict, "code_cooling_down", "custom_code", "custom short code was recently released")
+	ErrNotFound         = newError(KindNotFound, "not_found", "", "short code not found")
+	ErrExpired          = newError(KindGone, "expired", "", "short URL expired")
+	ErrGenExhausted     = newError(KindUnavailable, "generator_exhausted", "", "failed to generate unique short code after retries")
+	ErrClicksExhausted  = newError(KindGone, "clicks_exhausted", "", "short URL has reached its click limit")
+	ErrInvalidMaxClicks = newError(KindInvalid, "invalid_max_clicks", "max_clicks", "max_clicks must be between 1 and 1000000000")
+	ErrInvalidRules     = newError(KindInvalid, "invalid_rules", "rules", "invalid redirect rules")
+	ErrInvalidVariants  = newError(KindInvalid, "invalid_variants", "variants", "invalid split variants")
+	ErrInvalidQuery     = newError(KindInvalid, "invalid_query", "", "invalid query passthrough options")
+	ErrDisabled         = newError(KindGone, "disabled", "", "short URL has been disabled")
+	ErrLegalTakedown    = newError(KindLegal, "legal_takedown", "", "short URL is unavailable for legal reasons")
 )
 
+// DefaultCodeCooldown is how long a deleted or expired code stays unclaimable,
+// so a new link doesn't pick up traffic meant for the old destination.
+const DefaultCodeCooldown = 30 * 24 * time.Hour
+
+// maxClicksLimit caps max_clicks so the counter always fits in an INTEGER column.
+const maxClicksLimit = 1_000_000_000
+
 // URLService provides URL shortening and lookup.
 type URLService struct {
 	repo      *repository.URLRepository
@@ -34,156 +58,356 @@ type URLService struct {
 	BaseURL   string // set to produce absolute short URLs
 	l1Cache   *cache.LRUCache
 	l2Cache   *cache.RedisCache
+
+	// domains maps branded hosts to the base URL their short URLs are built from.
+	// The default domain ("") uses BaseURL. Populated at startup via AddDomain.
+	domains map[string]string
+
+	// Screener vets destinations of new and updated links (optional).
+	Screener *screen.Chain
+
+	// Canonical selects the optional URL rewrites (query sorting, tracking
+	// parameter stripping) applied on top of the always-on canonicalization.
+	Canonical urlnorm.Options
+
+	// Dedupe returns an existing link instead of creating a new one when a plain
+	// shorten request repeats the canonical destination of a plain link.
+	Dedupe bool
+
+	// ReportThreshold is how many distinct abuse reporters quarantine a link
+	// (DefaultReportThreshold when zero).
+	ReportThreshold int
+
+	// Codes decides which custom codes may be claimed. The admin-managed
+	// reserved list in the database is checked on top of it.
+	Codes *codepolicy.Policy
+
+	// CaseInsensitive makes lookups and both cache layers ignore the case of
+	// short codes. The repository must be in the same mode.
+	CaseInsensitive bool
+
+	// CodeCooldown is how long a code stays unclaimable after its link is
+	// deleted or swept (counted from the expiry for swept links).
+	CodeCooldown time.Duration
+
+	// Countries resolves visitor IPs for country-based redirect rules (optional).
+	Countries rules.CountryResolver
+
+	logger *slog.Logger
+
+	localFailures failureCounter // password attempts when l2Cache is nil
 }
 
-func NewURLService(repo *repository.URLRepository, gen idgen.Generator, baseURL string, cacheSize int) *URLService {
+func NewURLService(repo *repository.URLRepository, gen idgen.Generator, baseURL string, cacheSize int, logger *slog.Logger) *URLService {
 	return &URLService{
 		repo:      repo,
 		generator: gen,
 		BaseURL:   baseURL,
 		l1Cache:   cache.NewLRUCache(cacheSize),
 		l2Cache:   nil,
+
+		Codes:        codepolicy.New(),
+		CodeCooldown: DefaultCodeCooldown,
+		logger:       logger,
 	}
 }
 
 // NewURLServiceWithRedis creates a URL service with both L1 and L2 caches
-func NewURLServiceWithRedis(repo *repository.URLRepository, gen idgen.Generator, baseURL string, l1CacheSize int, redisCache *cache.RedisCache) *URLService {
+func NewURLServiceWithRedis(repo *repository.URLRepository, gen idgen.Generator, baseURL string, l1CacheSize int, redisCache *cache.RedisCache, logger *slog.Logger) *URLService {
 	return &URLService{
 		repo:      repo,
 		generator: gen,
 		BaseURL:   baseURL,
 		l1Cache:   cache.NewLRUCache(l1CacheSize),
 		l2Cache:   redisCache,
+
+		Codes:        codepolicy.New(),
+		CodeCooldown: DefaultCodeCooldown,
+		logger:       logger,
 	}
 }
 
-var customCodeRE = regexp.MustCompile(`^[A-Za-z0-9\-\_\.]{4,10}$`)
+// log returns the request logger carried by ctx, or the service's.
+func (s *URLService) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, s.logger)
+}
+
+var tracer = otel.Tracer("github.com/Siddarth2230/url-shortener/internal/service")
 
 // ShortenURL creates a short code (or uses custom), persists, and returns the response.
-func (s *URLService) ShortenURL(ctx context.Context, req models.ShortenRequest) (*models.ShortenResponse, error) {
-	// 0. Validate long URL (uses validateURL helper)
-	if err := validateURL(req.URL); err != nil {
+func (s *URLService) ShortenURL(ctx context.Context, req models.ShortenRequest) (resp *models.ShortenResponse, err error) {
+	ctx, span := tracer.Start(ctx, "URLService.ShortenURL", trace.WithAttributes(attribute.Bool("shorten.custom_code", req.CustomCode != "")))
+	defer func() { tracing.End(span, err) }()
+
+	// 0. Validate and canonicalize every destination
+	if err := s.canonicalizeRequest(&req); err != nil {
 		return nil, err
 	}
 
+	if req.MaxClicks != nil && (*req.MaxClicks < 1 || *req.MaxClicks > maxClicksLimit) {
+		return nil, ErrInvalidMaxClicks
+	}
+
+	if err := validateRules(req.Rules); err != nil {
+		return nil, err
+	}
+	if err := validateVariants(req.Variants); err != nil {
+		return nil, err
+	}
+	if err := passthrough.Validate(req.QueryPolicy, req.UTM); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+	}
+	tags, err := normalizeMetadata(req.Title, req.Notes, req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	domain, err := s.ResolveDomain(req.Domain)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.screenDestinations(ctx, req.URL, req.Rules, req.Variants); err != nil {
+		return nil, err
+	}
+
+	var passwordHash string
+	if req.Password != "" {
+		hash, err := hashPassword(req.Password)
+		if err != nil {
+			return nil, err
+		}
+		passwordHash = hash
+	}
+
 	// If custom code provided, validate and try to save once
 	if req.CustomCode != "" {
-		if err := validateCustomCode(req.CustomCode); err != nil {
-			log.Printf("Invalid custom code %q: %v", req.CustomCode, err)
+		if err := s.checkCustomCode(ctx, req.CustomCode, req.Tier); err != nil {
+			s.log(ctx).Info("custom code rejected", "code", req.CustomCode, "reason", err)
 			return nil, err
 		}
 
 		// quick existence check
-		ok, err := s.repo.ExistsByShortCode(ctx, req.CustomCode)
+		ok, err := s.repo.ExistsByShortCode(ctx, domain, req.CustomCode)
 		if err != nil {
-			log.Printf("Error checking existence of custom code %q: %v", req.CustomCode, err)
+			s.log(ctx).Error("check custom code failed", "code", req.CustomCode, "err", err)
 			return nil, err
 		}
 		if ok {
-			return nil, ErrCustomCodeTaken
+			return nil, s.codeTakenError(ctx, domain, req.CustomCode)
 		}
 
 		now := time.Now().UTC()
 		u := &models.URL{
-			ShortCode: req.CustomCode,
-			LongURL:   req.URL,
-			CreatedAt: now,
-			ExpiresAt: nil,
+			Domain:       domain,
+			ShortCode:    req.CustomCode,
+			Owner:        req.Owner,
+			LongURL:      req.URL,
+			CreatedAt:    now,
+			ExpiresAt:    nil,
+			PasswordHash: passwordHash,
+			Rules:        req.Rules,
+			Variants:     req.Variants,
+			Interstitial: req.Interstitial,
+			QueryPolicy:  req.QueryPolicy,
+			UTM:          req.UTM,
+			Title:        req.Title,
+			Notes:        req.Notes,
+			Tags:         tags,
 		}
+		setMaxClicks(u, req.MaxClicks)
 
 		if err := s.repo.Save(ctx, u); err != nil {
 			if isUniqueConstraintErr(err) {
 				return nil, ErrCustomCodeTaken
 			}
-			log.Printf("Error saving custom code %q: %v", req.CustomCode, err)
+			s.log(ctx).Error("save custom code failed", "code", req.CustomCode, "err", err)
 			return nil, err
 		}
+		metrics.LinksCreated.WithLabelValues("custom").Inc()
 
 		// Cache immediately after creation (user will likely click soon)
-		s.cacheURL(ctx, req.CustomCode, u, 1*time.Hour)
+		s.cacheURL(ctx, s.cacheKey(domain, req.CustomCode), u, 1*time.Hour)
 
-		shortURL := req.CustomCode
-		if s.BaseURL != "" {
-			shortURL = fmt.Sprintf("%s/%s", s.BaseURL, req.CustomCode)
-		}
 		return &models.ShortenResponse{
+			Domain:    domain,
 			ShortCode: req.CustomCode,
-			ShortURL:  shortURL,
+			ShortURL:  s.ShortURL(domain, req.CustomCode),
 			LongURL:   req.URL,
+			Protected: u.IsProtected(),
+			MaxClicks: u.MaxClicks,
 		}, nil
 	}
 
+	// Plain links for a destination that already has one reuse it
+	if s.Dedupe && isPlainRequest(req) {
+		if existing := s.findDuplicate(ctx, domain, req.URL); existing != nil {
+			return &models.ShortenResponse{
+				Domain:    domain,
+				ShortCode: existing.ShortCode,
+				ShortURL:  s.ShortURL(domain, existing.ShortCode),
+				LongURL:   existing.LongURL,
+				Existing:  true,
+			}, nil
+		}
+	}
+
 	// if custom code not provided
 	u := &models.URL{
-		LongURL:   req.URL,
-		ExpiresAt: nil,
-	}
+		Domain:       domain,
+		LongURL:      req.URL,
+		Owner:        req.Owner,
+		ExpiresAt:    nil,
+		PasswordHash: passwordHash,
+		Rules:        req.Rules,
+		Variants:     req.Variants,
+		Interstitial: req.Interstitial,
+		QueryPolicy:  req.QueryPolicy,
+		UTM:          req.UTM,
+		Title:        req.Title,
+		Notes:        req.Notes,
+		Tags:         tags,
+	}
+	setMaxClicks(u, req.MaxClicks)
 
 	const maxAttempts = 6
 	code, gErr := s.generateAndSaveUniqueShortCode(ctx, u, maxAttempts)
 	if gErr != nil {
 		return nil, gErr
 	}
+	metrics.LinksCreated.WithLabelValues("generated").Inc()
 
 	// Cache the newly created URL
-	s.cacheURL(ctx, code, u, 1*time.Hour)
+	s.cacheURL(ctx, s.cacheKey(domain, code), u, 1*time.Hour)
 
-	shortURL := code
-	if s.BaseURL != "" {
-		shortURL = fmt.Sprintf("%s/%s", s.BaseURL, code)
-	}
 	return &models.ShortenResponse{
+		Domain:    domain,
 		ShortCode: code,
-		ShortURL:  shortURL,
+		ShortURL:  s.ShortURL(domain, code),
 		LongURL:   req.URL,
+		Protected: u.IsProtected(),
+		MaxClicks: u.MaxClicks,
 	}, nil
 }
 
-// GetLongURL looks up the long URL for a short code and checks expiry.
-func (s *URLService) GetLongURL(ctx context.Context, shortCode string) (string, error) {
-	if shortCode == "" {
-		return "", ErrNotFound
+// GetLongURL looks up the long URL for a short code, checks expiry and consumes
+// one click of max-click links. Password-protected links return ErrPasswordRequired;
+// use UnlockURL for those.
+func (s *URLService) GetLongURL(ctx context.Context, domain, shortCode string) (_ string, err error) {
+	ctx, span := tracer.Start(ctx, "URLService.GetLongURL")
+	defer func() { tracing.End(span, err) }()
+
+	u, err := s.ResolveURL(ctx, domain, shortCode)
+	if err != nil {
+		return "", err
+	}
+	if u.IsProtected() {
+		return "", ErrPasswordRequired
+	}
+	if err := s.ConsumeClick(ctx, u); err != nil {
+		return "", err
+	}
+	return u.LongURL, nil
+}
+
+// ConsumeClick takes one click from a max-click link right before it redirects.
+// Unlimited links are a no-op. The decrement always goes to the database, even when
+// the link came from L1 or L2, so the limit holds across every instance. Once the
+// last click is used the caches are purged so the exhausted state is picked up.
+func (s *URLService) ConsumeClick(ctx context.Context, u *models.URL) error {
+	if u.MaxClicks == nil {
+		return nil
 	}
 
-	// ===== CACHE LAYER (L1) =====
-	if cached, ok := s.l1Cache.Get(shortCode); ok {
-		metrics.CacheHits.WithLabelValues("l1").Inc()
-		// Cache hit!
-		if url, ok := cached.(*models.URL); ok {
-			// Check expiry
-			if url.ExpiresAt != nil && time.Now().UTC().After(*url.ExpiresAt) {
-				s.invalidateCache(ctx, shortCode)
-				return "", ErrExpired
+	remaining, ok, err := s.repo.ConsumeClick(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrClicksExhausted
+	}
+	if remaining == 0 {
+		go func() {
+			// Use background context so the purge is not canceled when the request ends.
+			if err := s.PurgeCache(context.Background(), u.Domain, u.ShortCode); err != nil {
+				s.log(ctx).Warn("purge exhausted link failed", "code", u.ShortCode, "err", err)
 			}
-			metrics.CacheSize.WithLabelValues("l1").Set(float64(s.l1Cache.Len()))
-			return url.LongURL, nil
+		}()
+	}
+	return nil
+}
+
+func setMaxClicks(u *models.URL, maxClicks *int) {
+	if maxClicks == nil {
+		return
+	}
+	n, remaining := *maxClicks, *maxClicks
+	u.MaxClicks = &n
+	u.ClicksRemaining = &remaining
+}
+
+// exhausted reports whether a max-click link had no clicks left when it was loaded.
+func exhausted(u *models.URL) bool {
+	return u.ClicksRemaining != nil && *u.ClicksRemaining <= 0
+}
+
+// checkAvailable reports why a found link can't be served: it was taken down or
+// used up. Quarantined links are served; callers show the warning.
+func checkAvailable(u *models.URL) error {
+	if u.IsDisabled() {
+		if u.StatusReason == models.ReasonLegal {
+			return ErrLegalTakedown
 		}
+		return ErrDisabled
 	}
-	metrics.CacheMisses.WithLabelValues("l1").Inc()
+	if exhausted(u) {
+		return ErrClicksExhausted
+	}
+	return nil
+}
 
-	// ===== CACHE LAYER (L2) =====
-	if s.l2Cache != nil {
-		var cachedURL models.URL
-		cacheKey := shortCode
-
-		err := s.l2Cache.Get(ctx, cacheKey, &cachedURL)
-		if err == nil {
-			// L2 cache hit
-			metrics.CacheHits.WithLabelValues("l2").Inc()
-			if cachedURL.ExpiresAt != nil && time.Now().UTC().After(*cachedURL.ExpiresAt) {
-				s.invalidateCache(ctx, shortCode)
-				return "", ErrExpired
-			}
+// ResolveURL looks up the link for a short code on a domain ("" for the default
+// domain) through both cache layers and checks expiry and status.
+// It does NOT enforce password protection: callers must check IsProtected before
+// handing out LongURL. The returned URL may be shared with the L1 cache; don't modify it.
+func (s *URLService) ResolveURL(ctx context.Context, domain, shortCode string) (_ *models.URL, err error) {
+	ctx, span := tracer.Start(ctx, "URLService.ResolveURL", trace.WithAttributes(attribute.String("url.short_code", shortCode)))
+	defer func() { tracing.End(span, err) }()
+
+	if shortCode == "" {
+		return nil, ErrNotFound
+	}
+	key := s.cacheKey(domain, shortCode)
 
-			// Store in L1 for next request to THIS server
-			s.l1Cache.Put(shortCode, &cachedURL)
+	// ===== CACHE LAYER (L1) =====
+	if url := s.lookupL1(ctx, key); url != nil {
+		if url.LongURL == notFoundMarker {
+			return nil, ErrNotFound
+		}
+		// Check expiry
+		if url.ExpiresAt != nil && time.Now().UTC().After(*url.ExpiresAt) {
+			s.invalidateCache(ctx, key)
+			return nil, ErrExpired
+		}
+		if err := checkAvailable(url); err != nil {
+			return nil, err
+		}
+		return url, nil
+	}
 
-			return cachedURL.LongURL, nil
+	// ===== CACHE LAYER (L2) =====
+	if url := s.lookupL2(ctx, key); url != nil {
+		if url.LongURL == notFoundMarker {
+			return nil, ErrNotFound
 		}
-		if !errors.Is(err, cache.ErrCacheMiss) {
-			log.Printf("Redis error for key %s: %v", cacheKey, err)
+		if url.ExpiresAt != nil && time.Now().UTC().After(*url.ExpiresAt) {
+			s.invalidateCache(ctx, key)
+			return nil, ErrExpired
 		}
-		metrics.CacheMisses.WithLabelValues("l2").Inc()
+		if err := checkAvailable(url); err != nil {
+			return nil, err
+		}
+		return url, nil
 	}
 
 	// L2 Cache miss - continue to database
@@ -192,81 +416,155 @@ func (s *URLService) GetLongURL(ctx context.Context, shortCode string) (string,
 	// LAYER 3: Query Database (PostgreSQL) - SLOWEST
 	// ============================================================
 	dbStart := time.Now()
-	u, err := s.repo.FindByShortCode(ctx, shortCode)
-	metrics.DatabaseQueryDuration.WithLabelValues("find").Observe(time.Since(dbStart).Seconds())
+	u, err := s.repo.FindByShortCode(ctx, domain, shortCode)
+	metrics.Observe(ctx, metrics.DatabaseQueryDuration.WithLabelValues("find"), time.Since(dbStart).Seconds())
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if u == nil {
-		s.cacheNotFound(ctx, shortCode)
-		return "", ErrNotFound
+		s.cacheNotFound(ctx, key)
+		return nil, ErrNotFound
 	}
 
 	if u.ExpiresAt != nil && time.Now().UTC().After(*u.ExpiresAt) {
-		return "", ErrExpired
+		return nil, ErrExpired
 	}
 
 	ttl := s.calculateCacheTTL(u)
 
-	// Save to cache for next time
-	s.cacheURL(ctx, shortCode, u, ttl)
+	// Save to cache for next time (exhausted links too, so they stop hitting the DB)
+	s.cacheURL(ctx, key, u, ttl)
 
-	return u.LongURL, nil
+	if err := checkAvailable(u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// lookupL1 returns the entry for key in this process's cache, or nil on a miss.
+func (s *URLService) lookupL1(ctx context.Context, key string) *models.URL {
+	_, span := tracer.Start(ctx, "cache.l1.get")
+	defer span.End()
+
+	if cached, ok := s.l1Cache.Get(key); ok {
+		if url, ok := cached.(*models.URL); ok && !isStaleNotFound(url) {
+			metrics.CacheHits.WithLabelValues("l1").Inc()
+			span.SetAttributes(attribute.Bool("cache.hit", true))
+			return url
+		}
+	}
+	metrics.CacheMisses.WithLabelValues("l1").Inc()
+	span.SetAttributes(attribute.Bool("cache.hit", false))
+	return nil
+}
+
+// lookupL2 returns the entry for key in Redis, or nil on a miss or without
+// Redis. Hits are copied into L1 for the next request to this server.
+func (s *URLService) lookupL2(ctx context.Context, key string) *models.URL {
+	if s.l2Cache == nil {
+		return nil
+	}
+	ctx, span := tracer.Start(ctx, "cache.l2.get")
+	defer span.End()
+
+	var cachedURL models.URL
+	err := s.l2Cache.Get(ctx, key, &cachedURL)
+	if err == nil {
+		metrics.CacheHits.WithLabelValues("l2").Inc()
+		span.SetAttributes(attribute.Bool("cache.hit", true))
+		s.l1Put(key, &cachedURL)
+		return &cachedURL
+	}
+	if !errors.Is(err, cache.ErrCacheMiss) {
+		// A Redis failure degrades to a database lookup rather than failing the request
+		span.RecordError(err)
+		s.log(ctx).Warn("redis lookup failed", "key", key, "err", err)
+	}
+	metrics.CacheMisses.WithLabelValues("l2").Inc()
+	span.SetAttributes(attribute.Bool("cache.hit", false))
+	return nil
+}
+
+// l1Put and l1Delete write to the L1 cache and keep its size gauge current.
+func (s *URLService) l1Put(key string, v interface{}) {
+	s.l1Cache.Put(key, v)
+	metrics.CacheSize.WithLabelValues("l1").Set(float64(s.l1Cache.Len()))
 }
 
-func (s *URLService) cacheURL(ctx context.Context, shortCode string, u *models.URL, ttl time.Duration) {
+func (s *URLService) l1Delete(key string) {
+	s.l1Cache.Delete(key)
+	metrics.CacheSize.WithLabelValues("l1").Set(float64(s.l1Cache.Len()))
+}
+
+func (s *URLService) cacheURL(ctx context.Context, key string, u *models.URL, ttl time.Duration) {
+	// Don't keep a link in Redis past its expiry
+	if u.ExpiresAt != nil {
+		if left := time.Until(*u.ExpiresAt); left < ttl {
+			ttl = max(left, time.Second)
+		}
+	}
+
 	// L1: In-memory cache (synchronous)
-	s.l1Cache.Put(shortCode, u)
+	s.l1Put(key, u)
 
 	// L2: Redis cache (asynchronous to not block response)
 	if s.l2Cache != nil {
 		go func() {
 			// Use background context to avoid cancellation
 			bgCtx := context.Background()
-			cacheKey := shortCode
-
-			err := s.l2Cache.SetWithTTL(bgCtx, cacheKey, u, ttl)
+			err := s.l2Cache.SetWithTTL(bgCtx, key, u, ttl)
 			if err != nil {
-				log.Printf("Failed to cache URL in Redis (key=%s): %v", cacheKey, err)
+				s.log(ctx).Warn("redis cache write failed", "key", key, "err", err)
 			}
 		}()
 	}
 }
 
+// notFoundMarker is cached as LongURL for codes that don't exist.
+const notFoundMarker = "__NOT_FOUND__"
+
+// notFoundTTL bounds how long a "not found" marker is trusted in either cache layer.
+const notFoundTTL = 1 * time.Minute
+
 // cacheNotFound stores a "not found" marker to prevent repeated DB queries
-func (s *URLService) cacheNotFound(ctx context.Context, shortCode string) {
-	notFoundMarker := &models.URL{
-		ShortCode: shortCode,
-		LongURL:   "__NOT_FOUND__",
+func (s *URLService) cacheNotFound(ctx context.Context, key string) {
+	marker := &models.URL{
+		ShortCode: key,
+		LongURL:   notFoundMarker,
 		CreatedAt: time.Now().UTC(),
 	}
 
-	s.l1Cache.Put(shortCode, notFoundMarker)
+	s.l1Put(key, marker)
 
 	if s.l2Cache != nil {
 		go func() {
 			// Use background context so caching is not canceled if request ends.
 			bgCtx := context.Background()
-			cacheKey := shortCode
-			s.l2Cache.SetWithTTL(bgCtx, cacheKey, notFoundMarker, 1*time.Minute)
+			s.l2Cache.SetWithTTL(bgCtx, key, marker, notFoundTTL)
 		}()
 	}
 }
 
+// isStaleNotFound reports whether u is a "not found" marker older than notFoundTTL.
+// L1 has no TTL of its own, so without this a code created on another server would
+// stay "not found" here until evicted.
+func isStaleNotFound(u *models.URL) bool {
+	return u.LongURL == notFoundMarker && time.Since(u.CreatedAt) > notFoundTTL
+}
+
 // invalidateCache removes a URL from both cache layers
-func (s *URLService) invalidateCache(ctx context.Context, shortCode string) {
+func (s *URLService) invalidateCache(ctx context.Context, key string) {
 	// L1: Remove from this server's cache
-	s.l1Cache.Delete(shortCode)
+	s.l1Delete(key)
 
 	// L2: Remove from Redis (affects all servers)
 	if s.l2Cache != nil {
 		go func() {
 			// Use background context so caching is not canceled if request ends.
 			bgCtx := context.Background()
-			cacheKey := shortCode
-			err := s.l2Cache.Delete(bgCtx, cacheKey)
+			err := s.l2Cache.Delete(bgCtx, key)
 			if err != nil {
-				log.Printf("Failed to invalidate cache in Redis (key=%s): %v", cacheKey, err)
+				s.log(ctx).Warn("redis invalidation failed", "key", key, "err", err)
 			}
 		}()
 	}
@@ -290,42 +588,6 @@ func (s *URLService) calculateCacheTTL(u *models.URL) time.Duration {
 	return defaultTTL
 }
 
-// validateCustomCode enforces allowed chars, length, and reserved blacklist.
-func validateCustomCode(code string) error {
-	if !customCodeRE.MatchString(code) {
-		return fmt.Errorf("custom code must be 4-10 chars and may contain letters, numbers, '-', '_' and '.'")
-	}
-
-	// Reserved words (lowercase)
-	reserved := map[string]struct{}{
-		"admin":  {},
-		"api":    {},
-		"health": {},
-		"www":    {},
-		"root":   {},
-		"login":  {},
-		"status": {},
-	}
-	l := strings.ToLower(code)
-	if _, ok := reserved[l]; ok {
-		return fmt.Errorf("custom code %q is reserved", code)
-	}
-
-	// Block purely numeric codes to avoid confusion with ID-based systems
-	allDigits := true
-	for _, r := range code {
-		if r < '0' || r > '9' {
-			allDigits = false
-			break
-		}
-	}
-	if allDigits {
-		return fmt.Errorf("custom code must not be purely numeric")
-	}
-
-	return nil
-}
-
 // generateAndSaveUniqueShortCode generates a code using the configured generator and saves it to DB.
 func (s *URLService) generateAndSaveUniqueShortCode(ctx context.Context, u *models.URL, maxAttempts int) (string, error) {
 	for i := 0; i < maxAttempts; i++ {
@@ -335,11 +597,21 @@ func (s *URLService) generateAndSaveUniqueShortCode(ctx context.Context, u *mode
 			return "", fmt.Errorf("generator failed: %w", genErr)
 		}
 		if code == "" {
-			log.Printf("idgen: generator returned empty code (attempt %d/%d)", i+1, maxAttempts)
+			s.log(ctx).Warn("generator returned empty code", "attempt", i+1, "max_attempts", maxAttempts)
+			metrics.GeneratorRetries.WithLabelValues("empty").Inc()
 			// small sleep/jitter could be added here
 			continue
 		}
 
+		// Codes released by a delete or sweep are cooling down; draw another
+		if availableAt, err := s.repo.FindTombstone(ctx, u.Domain, code); err != nil {
+			return "", err
+		} else if availableAt != nil {
+			s.log(ctx).Info("generated code is cooling down, retrying", "code", code, "attempt", i+1, "max_attempts", maxAttempts)
+			metrics.GeneratorRetries.WithLabelValues("cooling_down").Inc()
+			continue
+		}
+
 		u.ShortCode = code
 		now := time.Now().UTC()
 		u.CreatedAt = now
@@ -348,7 +620,8 @@ func (s *URLService) generateAndSaveUniqueShortCode(ctx context.Context, u *mode
 		if err := s.repo.Save(ctx, u); err != nil {
 			if isUniqueConstraintErr(err) {
 				// collision — try again
-				log.Printf("Save race detected for code=%s, retrying (attempt %d/%d)", code, i+1, maxAttempts)
+				s.log(ctx).Info("generated code taken, retrying", "code", code, "attempt", i+1, "max_attempts", maxAttempts)
+				metrics.GeneratorRetries.WithLabelValues("collision").Inc()
 				continue
 			}
 			return "", fmt.Errorf("save failed: %w", err)
@@ -356,29 +629,45 @@ func (s *URLService) generateAndSaveUniqueShortCode(ctx context.Context, u *mode
 		return code, nil
 	}
 
+	metrics.GeneratorExhausted.Inc()
 	return "", ErrGenExhausted
 }
 
-// validateURL checks that the URL is syntactically valid and uses http/https.
+// validateURL checks that the URL is valid and canonicalizable with the default options.
 func validateURL(urlStr string) error {
 	if urlStr == "" {
-		log.Println("URL isn't provided! Please provide URL")
-		return ErrInvalidURL
+		return fmt.Errorf("%w: URL is required", ErrInvalidURL)
 	}
-	parsed, err := url.ParseRequestURI(urlStr)
-	if err != nil {
-		log.Println("Invalide URL. Please check")
-		return ErrInvalidURL
+	if _, err := urlnorm.Canonicalize(urlStr, urlnorm.Options{}); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidURL, err)
 	}
-	if parsed.Scheme == "" || parsed.Host == "" {
-		log.Println("Scheme / Host is empty")
-		return ErrInvalidURL
+	return nil
+}
+
+// validateRules checks rule conditions and that every rule destination is a valid URL.
+func validateRules(rs []models.RedirectRule) error {
+	if err := rules.Validate(rs); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRules, err)
 	}
-	// Restrict to http(s) for redirect safety
-	if parsed.Scheme != "http" && parsed.Scheme != "https" {
-		return fmt.Errorf("unsupported URL scheme: %s", parsed.Scheme)
+	for i, r := range rs {
+		if err := validateURL(r.Destination); err != nil {
+			return fmt.Errorf("%w: rule %d: invalid destination", ErrInvalidRules, i+1)
+		}
 	}
+	return nil
+}
 
+// validateVariants checks split variants and that every variant destination is a valid URL.
+func validateVariants(vs []models.Variant) error {
+	if err := split.Validate(vs); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidVariants, err)
+	}
+	for i := range vs {
+		if err := validateURL(vs[i].Destination); err != nil {
+			return fmt.Errorf("%w: variant %d: invalid destination", ErrInvalidVariants, i+1)
+		}
+		vs[i].ID = 0 // assigned by the database
+	}
 	return nil
 }
 
@@ -406,15 +695,34 @@ func isUniqueConstraintErr(err error) bool {
 	return strings.Contains(l, "duplicate key value violates unique constraint")
 }
 
-// TODO: Add cache invalidation on update/delete
-func (s *URLService) DeleteShortCode(ctx context.Context, shortCode string) error {
+// codeTakenError tells a code that is in use apart from one still cooling down
+// after its link went away, and says when the latter can be claimed.
+func (s *URLService) codeTakenError(ctx context.Context, domain, code string) error {
+	availableAt, err := s.repo.FindTombstone(ctx, domain, code)
+	if err != nil || availableAt == nil {
+		return ErrCustomCodeTaken
+	}
+	return coolingDownError(*availableAt)
+}
+
+func coolingDownError(availableAt time.Time) error {
+	return fmt.Errorf("%w; it can be claimed again after %s", ErrCodeCoolingDown, availableAt.UTC().Format(time.RFC3339))
+}
+
+// DeleteShortCode removes a short code and purges it from every cache layer.
+func (s *URLService) DeleteShortCode(ctx context.Context, domain, shortCode string) error {
 	// Delete from DB
-	if err := s.repo.DeleteByShortCode(ctx, shortCode); err != nil {
+	if err := s.repo.DeleteByShortCode(ctx, domain, shortCode, s.CodeCooldown); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
 		return err
 	}
 
 	// Invalidate cache
-	s.invalidateCache(ctx, shortCode)
+	if err := s.PurgeCache(ctx, domain, shortCode); err != nil {
+		s.log(ctx).Warn("purge cache after delete failed", "code", shortCode, "err", err)
+	}
 
 	return nil
 }
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+	"github.com/Siddarth2230/url-shortener/internal/repository"
+)
+
+// urlRow has the columns FindByShortCode and FindAnyByShortCode select.
+var urlRow = []string{"id", "domain", "short_code", "long_url", "created_at", "expires_at", "owner", "password_hash",
+	"max_clicks", "clicks_remaining", "rules", "status", "status_reason", "interstitial", "title", "notes",
+	"query_policy", "utm", "variants"}
+
+// newTestService returns a service on the real repository with sqlmock in
+// place of PostgreSQL and only the in-process cache.
+func newTestService(t *testing.T) (*URLService, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.MatchExpectationsInOrder(false)
+	t.Cleanup(func() { db.Close() })
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewURLService(repository.NewURLRepository(db, logger), nil, "https://sho.rt", 100, logger), mock
+}
+
+// expectLink answers one lookup of code on domain with a link to dest.
+func expectLink(mock sqlmock.Sqlmock, domain, code, dest string, passwordHash, clicksRemaining interface{}) {
+	mock.ExpectQuery(regexp.QuoteMeta("FROM urls")).WithArgs(domain, code).WillReturnRows(sqlmock.NewRows(urlRow).
+		AddRow(1, domain, code, dest, time.Now(), nil, nil, passwordHash, clicksRemaining, clicksRemaining, nil, "active", nil, false, nil, nil, "ignore", nil, []byte("[]")))
+}
+
+// waitUncached waits for an asynchronous purge to evict key from L1.
+func waitUncached(t *testing.T, s *URLService, key string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, ok := s.l1Cache.Get(key); !ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s is still cached", key)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func expectConsume(mock sqlmock.Sqlmock, remaining ...int) {
+	rows := sqlmock.NewRows([]string{"clicks_remaining"})
+	for _, n := range remaining {
+		rows.AddRow(n)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("SET clicks_remaining = clicks_remaining - 1")).WithArgs(1).WillReturnRows(rows)
+}
+
+// The last click is served, then the purged cache makes the next lookup see
+// the exhausted link.
+func TestConsumeClickLastClick(t *testing.T) {
+	s, mock := newTestService(t)
+	ctx := context.Background()
+
+	expectLink(mock, "", "once", "https://example.com", nil, 1)
+	u, err := s.ResolveURL(ctx, "", "once")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectConsume(mock, 0)
+	if err := s.ConsumeClick(ctx, u); err != nil {
+		t.Fatalf("last click: %v", err)
+	}
+
+	waitUncached(t, s, "once")
+	expectLink(mock, "", "once", "https://example.com", nil, 0)
+	if _, err := s.ResolveURL(ctx, "", "once"); !errors.Is(err, ErrClicksExhausted) {
+		t.Errorf("ResolveURL after the last click = %v, want ErrClicksExhausted", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// Clicks racing for the last few are decided by the conditional decrement:
+// only as many succeed as the database hands out.
+func TestConsumeClickConcurrent(t *testing.T) {
+	s, mock := newTestService(t)
+	limit := 3
+	u := &models.URL{ID: 1, ShortCode: "few", MaxClicks: &limit, ClicksRemaining: &limit}
+
+	const clicks = 10
+	for i := limit - 1; i >= 0; i-- {
+		expectConsume(mock, i)
+	}
+	for i := limit; i < clicks; i++ {
+		expectConsume(mock) // clicks_remaining > 0 no longer matches
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	served, refused := 0, 0
+	for i := 0; i < clicks; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.ConsumeClick(context.Background(), u)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				served++
+			case errors.Is(err, ErrClicksExhausted):
+				refused++
+			default:
+				t.Errorf("ConsumeClick = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if served != limit || refused != clicks-limit {
+		t.Errorf("served %d, refused %d; want %d and %d", served, refused, limit, clicks-limit)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// A cached copy still showing clicks left doesn't beat the database once
+// another instance used them up.
+func TestConsumeClickStaleCache(t *testing.T) {
+	s, mock := newTestService(t)
+	ctx := context.Background()
+
+	expectLink(mock, "", "stale", "https://example.com", nil, 5)
+	if _, err := s.ResolveURL(ctx, "", "stale"); err != nil {
+		t.Fatal(err)
+	}
+	u, err := s.ResolveURL(ctx, "", "stale") // from L1
+	if err != nil || *u.ClicksRemaining != 5 {
+		t.Fatalf("cached link = %v, %v", u, err)
+	}
+
+	expectConsume(mock)
+	if err := s.ConsumeClick(ctx, u); !errors.Is(err, ErrClicksExhausted) {
+		t.Errorf("ConsumeClick on a stale link = %v, want ErrClicksExhausted", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
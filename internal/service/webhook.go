@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+	"github.com/Siddarth2230/url-shortener/pkg/webhook"
+)
+
+var (
+	ErrInvalidAPIKey    = newError(KindUnauthenticated, "invalid_api_key", "", "invalid API key")
+	ErrInvalidKeyName   = newError(KindInvalid, "invalid_key_name", "name", "API key names are 1-64 letters, digits, '.', '_' or '-'")
+	ErrInvalidWebhook   = newError(KindInvalid, "invalid_webhook", "", "invalid webhook subscription")
+	ErrWebhookNotFound  = newError(KindNotFound, "webhook_not_found", "", "webhook subscription not found")
+	ErrDeliveryNotFound = newError(KindNotFound, "delivery_not_found", "", "dead webhook delivery not found")
+)
+
+// maxDeadLetters caps one page of the dead-letter view.
+const maxDeadLetters = 200
+
+var keyNameRE = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// webhookEvents are the event types a subscription may ask for.
+var webhookEvents = map[string]bool{
+	models.EventLinkCreated: true,
+	models.EventLinkUpdated: true,
+	models.EventLinkDeleted: true,
+	models.EventLinkExpired: true,
+	models.EventLinkClicked: true,
+}
+
+// CreateAPIKey issues a key for an integrator and returns its secret value,
+// which is only stored hashed and can't be shown again. The tier selects the
+// key's custom code length limit ("" for the default).
+func (s *URLService) CreateAPIKey(ctx context.Context, name, tier string) (string, *models.APIKey, error) {
+	if !keyNameRE.MatchString(name) {
+		return "", nil, ErrInvalidKeyName
+	}
+	if tier != "" && !keyNameRE.MatchString(tier) {
+		return "", nil, fmt.Errorf("%w: invalid tier %q", ErrInvalidKeyName.WithField("tier"), tier)
+	}
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	raw := "sk_" + hex.EncodeToString(b)
+	k, err := s.repo.CreateAPIKey(ctx, name, tier, hashAPIKey(raw))
+	if err != nil {
+		if isUniqueConstraintErr(err) {
+			return "", nil, fmt.Errorf("%w: name %q is taken", ErrInvalidKeyName, name)
+		}
+		return "", nil, err
+	}
+	return raw, k, nil
+}
+
+// RevokeAPIKey disables a key by name. Its subscriptions stop receiving events.
+func (s *URLService) RevokeAPIKey(ctx context.Context, name string) error {
+	ok, err := s.repo.RevokeAPIKey(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// AuthenticateAPIKey returns the key a request presented, or ErrInvalidAPIKey.
+func (s *URLService) AuthenticateAPIKey(ctx context.Context, raw string) (*models.APIKey, error) {
+	if raw == "" {
+		return nil, ErrInvalidAPIKey
+	}
+	k, err := s.repo.FindAPIKey(ctx, hashAPIKey(raw))
+	if err != nil {
+		return nil, err
+	}
+	if k == nil {
+		return nil, ErrInvalidAPIKey
+	}
+	return k, nil
+}
+
+func hashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateWebhook subscribes a URL to an API key's link events. The endpoint is
+// screened like a link destination, so subscriptions can't reach internal
+// services. The returned subscription carries the signing secret.
+func (s *URLService) CreateWebhook(ctx context.Context, key *models.APIKey, req models.WebhookSubscriptionRequest) (*models.WebhookSubscription, error) {
+	endpoint, err := s.canonicalURL(req.URL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhook.WithField("url"), err)
+	}
+	if err := s.screenDestinations(ctx, endpoint, nil, nil); err != nil {
+		return nil, err
+	}
+
+	events := req.Events
+	if len(events) == 0 {
+		events = models.LifecycleEvents
+	}
+	seen := make(map[string]bool, len(events))
+	var unique []string
+	for _, e := range events {
+		if !webhookEvents[e] {
+			return nil, fmt.Errorf("%w: unknown event %q", ErrInvalidWebhook.WithField("events"), e)
+		}
+		if !seen[e] {
+			seen[e] = true
+			unique = append(unique, e)
+		}
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+	sub := &models.WebhookSubscription{APIKeyID: key.ID, URL: endpoint, Events: unique, Secret: secret}
+	if err := s.repo.CreateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// ListWebhooks returns an API key's subscriptions without their secrets.
+func (s *URLService) ListWebhooks(ctx context.Context, key *models.APIKey) ([]*models.WebhookSubscription, error) {
+	return s.repo.ListSubscriptions(ctx, key.ID)
+}
+
+// DeleteWebhook removes one of an API key's subscriptions and its pending deliveries.
+func (s *URLService) DeleteWebhook(ctx context.Context, key *models.APIKey, id int64) error {
+	ok, err := s.repo.DeleteSubscription(ctx, key.ID, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// DeadLetters lists an API key's deliveries that ran out of retries.
+func (s *URLService) DeadLetters(ctx context.Context, key *models.APIKey, limit int) ([]*models.WebhookDelivery, error) {
+	if limit <= 0 || limit > maxDeadLetters {
+		limit = 50
+	}
+	return s.repo.ListDeadLetters(ctx, key.ID, limit)
+}
+
+// ReplayDelivery queues a dead delivery again with a fresh retry budget.
+func (s *URLService) ReplayDelivery(ctx context.Context, key *models.APIKey, id int64) error {
+	ok, err := s.repo.ReplayDelivery(ctx, key.ID, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrDeliveryNotFound
+	}
+	return nil
+}
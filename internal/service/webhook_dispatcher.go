@@ -0,0 +1,191 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+	"github.com/Siddarth2230/url-shortener/internal/repository"
+	"github.com/Siddarth2230/url-shortener/internal/screen"
+	"github.com/Siddarth2230/url-shortener/pkg/metrics"
+	"github.com/Siddarth2230/url-shortener/pkg/webhook"
+)
+
+// WebhookDispatcher delivers queued webhook events from the outbox. Several
+// dispatchers (one per API replica) can run at once: each claims its own rows.
+type WebhookDispatcher struct {
+	repo   *repository.URLRepository
+	logger *slog.Logger
+
+	Client      *http.Client    // default: 10s timeout, no redirects, no private addresses
+	Interval    time.Duration   // outbox poll interval, default 5s
+	BatchSize   int             // deliveries claimed per poll, default 50
+	MaxAttempts int             // attempts before a delivery is dead, default 10
+	Backoff     webhook.Backoff // delay between attempts
+	Retention   time.Duration   // how long delivered rows are kept, default 7 days
+}
+
+// NewWebhookDispatcher creates a dispatcher with the defaults above.
+func NewWebhookDispatcher(repo *repository.URLRepository, logger *slog.Logger) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		repo:        repo,
+		logger:      logger,
+		Client:      NewWebhookClient(false),
+		Interval:    5 * time.Second,
+		BatchSize:   50,
+		MaxAttempts: 10,
+		Retention:   7 * 24 * time.Hour,
+	}
+}
+
+var errPrivateAddress = errors.New("webhook endpoint resolves to a private address")
+
+// NewWebhookClient returns the HTTP client used for deliveries. It never follows
+// redirects and, unless allowPrivate is set, refuses to connect to loopback or
+// private addresses, which also covers endpoints whose DNS changed after the
+// subscription was screened.
+func NewWebhookClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || screen.IsInternalIP(ip) {
+				return errPrivateAddress
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Run polls the outbox until ctx is canceled.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+	lastPrune := time.Time{}
+
+	for {
+		// Drain full batches right away; wait for the ticker once the outbox is caught up
+		for {
+			n, err := d.DispatchOnce(ctx)
+			if err != nil && ctx.Err() == nil {
+				d.logger.Error("webhook dispatch failed", "err", err)
+			}
+			if err != nil || n < d.BatchSize {
+				break
+			}
+		}
+
+		if time.Since(lastPrune) > time.Hour {
+			lastPrune = time.Now()
+			if n, err := d.PruneOnce(ctx); err != nil {
+				d.logger.Error("webhook outbox prune failed", "err", err)
+			} else if n > 0 {
+				d.logger.Info("pruned delivered webhook events", "count", n)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PruneOnce deletes delivered events older than Retention and returns how many went.
+func (d *WebhookDispatcher) PruneOnce(ctx context.Context) (int64, error) {
+	return d.repo.PruneDeliveries(ctx, time.Now().UTC().Add(-d.Retention))
+}
+
+// DispatchOnce claims one batch of due deliveries, sends them concurrently and
+// records the outcomes. It returns how many deliveries were claimed.
+func (d *WebhookDispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	// Hold the lease for longer than any attempt can take
+	lease := d.Client.Timeout + time.Minute
+	deliveries, err := d.repo.ClaimDeliveries(ctx, d.BatchSize, lease)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for _, del := range deliveries {
+		wg.Add(1)
+		go func(del *models.WebhookDelivery) {
+			defer wg.Done()
+			d.deliver(ctx, del)
+		}(del)
+	}
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+func (d *WebhookDispatcher) deliver(ctx context.Context, del *models.WebhookDelivery) {
+	statusCode, err := d.send(ctx, del)
+	if err == nil {
+		metrics.WebhookDeliveries.WithLabelValues("delivered").Inc()
+		if err := d.repo.MarkDelivered(ctx, del.ID, statusCode); err != nil {
+			d.logger.Error("mark webhook delivery delivered failed", "delivery_id", del.ID, "err", err)
+		}
+		return
+	}
+
+	var next *time.Time
+	result := "dead"
+	if del.Attempts < d.MaxAttempts {
+		t := time.Now().UTC().Add(d.Backoff.Delay(del.Attempts))
+		next, result = &t, "retry"
+	} else {
+		d.logger.Warn("webhook delivery is dead", "delivery_id", del.ID, "event", del.EventType, "url", del.URL, "attempts", del.Attempts, "err", err)
+	}
+	metrics.WebhookDeliveries.WithLabelValues(result).Inc()
+	if err := d.repo.MarkFailed(ctx, del.ID, statusCode, err.Error(), next); err != nil {
+		d.logger.Error("record webhook delivery failure failed", "delivery_id", del.ID, "err", err)
+	}
+}
+
+// send POSTs the signed payload. Any 2xx response counts as delivered.
+func (d *WebhookDispatcher) send(ctx context.Context, del *models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, del.URL, bytes.NewReader(del.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "url-shortener-webhooks/1")
+	req.Header.Set(webhook.IDHeader, del.EventID)
+	req.Header.Set(webhook.EventHeader, del.EventType)
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(del.Secret, time.Now(), del.Payload))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+	"github.com/Siddarth2230/url-shortener/pkg/webhook"
+)
+
+// timeBetween matches a time argument in [from, to].
+type timeBetween struct{ from, to time.Time }
+
+func (b timeBetween) Match(v driver.Value) bool {
+	t, ok := v.(time.Time)
+	return ok && !t.Before(b.from) && !t.After(b.to)
+}
+
+// newTestDispatcher returns a dispatcher on sqlmock whose client may reach
+// httptest servers.
+func newTestDispatcher(t *testing.T) (*WebhookDispatcher, *URLService, sqlmock.Sqlmock) {
+	t.Helper()
+	s, mock := newTestService(t)
+	d := NewWebhookDispatcher(s.repo, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	d.Client = NewWebhookClient(true)
+	d.Backoff = webhook.Backoff{Base: time.Minute, Max: time.Hour}
+	return d, s, mock
+}
+
+// expectClaim hands out one delivery to url that has been attempted attempts times.
+func expectClaim(mock sqlmock.Sqlmock, url string, attempts int) {
+	mock.ExpectQuery(regexp.QuoteMeta("RETURNING o.id")).WillReturnRows(sqlmock.NewRows([]string{"id", "subscription_id", "url", "secret",
+		"event_id", "event_type", "status", "attempts", "created_at", "next_attempt_at", "payload"}).
+		AddRow(7, 3, url, "whsec", "evt_1", models.EventLinkCreated, models.DeliveryPending, attempts, time.Now(), time.Now(), []byte(`{"id":"evt_1"}`)))
+}
+
+func TestDispatchDelivered(t *testing.T) {
+	d, _, mock := newTestDispatcher(t)
+	received := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		err := webhook.Verify("whsec", r.Header.Get(webhook.SignatureHeader), body, time.Minute, time.Now())
+		if r.Header.Get(webhook.IDHeader) != "evt_1" || r.Header.Get(webhook.EventHeader) != models.EventLinkCreated {
+			err = errors.New("missing event headers")
+		}
+		received <- err
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	expectClaim(mock, srv.URL, 1)
+	mock.ExpectExec(regexp.QuoteMeta("SET status = 'delivered'")).WithArgs(7, http.StatusNoContent).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if n, err := d.DispatchOnce(context.Background()); err != nil || n != 1 {
+		t.Fatalf("DispatchOnce = %d, %v", n, err)
+	}
+	if err := <-received; err != nil {
+		t.Errorf("delivery: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// Failed attempts are retried after the backoff for their attempt number.
+func TestDispatchBackoff(t *testing.T) {
+	d, _, mock := newTestDispatcher(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	// The third failure waits Base*4, plus up to 20% jitter
+	now := time.Now().UTC()
+	expectClaim(mock, srv.URL, 3)
+	mock.ExpectExec(regexp.QuoteMeta("next_attempt_at = COALESCE")).
+		WithArgs(7, models.DeliveryPending, timeBetween{now.Add(4 * time.Minute), now.Add(4*time.Minute*6/5 + time.Minute)},
+			http.StatusInternalServerError, "endpoint answered 500 Internal Server Error").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if _, err := d.DispatchOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDispatchDeadLetter(t *testing.T) {
+	d, _, mock := newTestDispatcher(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer srv.Close()
+
+	expectClaim(mock, srv.URL, d.MaxAttempts)
+	mock.ExpectExec(regexp.QuoteMeta("next_attempt_at = COALESCE")).
+		WithArgs(7, models.DeliveryDead, nil, http.StatusGone, "endpoint answered 410 Gone").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if _, err := d.DispatchOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReplayDelivery(t *testing.T) {
+	_, s, mock := newTestDispatcher(t)
+	key := &models.APIKey{ID: 5, Name: "team"}
+	mock.ExpectExec(regexp.QuoteMeta("SET status = 'pending', attempts = 0")).WithArgs(5, 7).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("SET status = 'pending', attempts = 0")).WithArgs(5, 8).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := s.ReplayDelivery(context.Background(), key, 7); err != nil {
+		t.Errorf("replay dead delivery: %v", err)
+	}
+	// Unknown, not dead or another key's: the update matches nothing
+	if err := s.ReplayDelivery(context.Background(), key, 8); !errors.Is(err, ErrDeliveryNotFound) {
+		t.Errorf("replay missing delivery: %v, want not found", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPruneDeliveries(t *testing.T) {
+	d, _, mock := newTestDispatcher(t)
+	d.Retention = 48 * time.Hour
+	now := time.Now().UTC()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM webhook_outbox WHERE status = 'delivered'")).
+		WithArgs(timeBetween{now.Add(-48 * time.Hour), now.Add(-48*time.Hour + time.Minute)}).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	if n, err := d.PruneOnce(context.Background()); err != nil || n != 4 {
+		t.Fatalf("PruneOnce = %d, %v", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,84 @@
+// Package split assigns visitors to the weighted variants of an A/B split link.
+//
+// Assignment is deterministic for a given visitor key, so a visitor keeps seeing
+// the same variant even when they don't keep the sticky cookie.
+package split
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+const (
+	MinVariants = 2
+	MaxVariants = 20
+	MaxWeight   = 10000
+)
+
+// Choose picks the variant for visitorKey in proportion to the variant weights.
+// It returns nil if there are no variants or every weight is zero.
+func Choose(variants []models.Variant, visitorKey string) *models.Variant {
+	total := 0
+	for _, v := range variants {
+		total += v.Weight
+	}
+	if total <= 0 {
+		return nil
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(visitorKey))
+	bucket := int(h.Sum64() % uint64(total))
+
+	for i := range variants {
+		if bucket < variants[i].Weight {
+			return &variants[i]
+		}
+		bucket -= variants[i].Weight
+	}
+	return nil // unreachable
+}
+
+// Find returns the variant with the given ID, if it's still part of the link.
+// Variants with zero weight are treated as retired and never returned.
+func Find(variants []models.Variant, id int64) *models.Variant {
+	for i := range variants {
+		if variants[i].ID == id && variants[i].Weight > 0 {
+			return &variants[i]
+		}
+	}
+	return nil
+}
+
+// Validate checks variant count, names and weights. Destination URLs are validated by the caller.
+func Validate(variants []models.Variant) error {
+	if len(variants) == 0 {
+		return nil
+	}
+	if len(variants) < MinVariants || len(variants) > MaxVariants {
+		return fmt.Errorf("a split link needs between %d and %d variants", MinVariants, MaxVariants)
+	}
+
+	names := make(map[string]struct{}, len(variants))
+	total := 0
+	for i, v := range variants {
+		if v.Name == "" {
+			return fmt.Errorf("variant %d: name is required", i+1)
+		}
+		if _, dup := names[v.Name]; dup {
+			return fmt.Errorf("variant %d: duplicate name %q", i+1, v.Name)
+		}
+		names[v.Name] = struct{}{}
+		if v.Weight < 0 || v.Weight > MaxWeight {
+			return fmt.Errorf("variant %d: weight must be between 0 and %d", i+1, MaxWeight)
+		}
+		total += v.Weight
+	}
+	if total == 0 {
+		return errors.New("at least one variant needs a positive weight")
+	}
+	return nil
+}
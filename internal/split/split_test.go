@@ -0,0 +1,76 @@
+package split
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+)
+
+func TestChooseIsSticky(t *testing.T) {
+	variants := []models.Variant{
+		{ID: 1, Name: "A", Weight: 50},
+		{ID: 2, Name: "B", Weight: 50},
+	}
+	first := Choose(variants, "203.0.113.7|Mozilla/5.0")
+	for i := 0; i < 10; i++ {
+		if got := Choose(variants, "203.0.113.7|Mozilla/5.0"); got.ID != first.ID {
+			t.Fatalf("Choose not deterministic: got %d, want %d", got.ID, first.ID)
+		}
+	}
+}
+
+func TestChooseRespectsWeights(t *testing.T) {
+	variants := []models.Variant{
+		{ID: 1, Name: "A", Weight: 70},
+		{ID: 2, Name: "B", Weight: 30},
+		{ID: 3, Name: "retired", Weight: 0},
+	}
+
+	const n = 20000
+	counts := map[int64]int{}
+	for i := 0; i < n; i++ {
+		counts[Choose(variants, fmt.Sprintf("visitor-%d", i)).ID]++
+	}
+
+	if counts[3] != 0 {
+		t.Errorf("zero-weight variant chosen %d times", counts[3])
+	}
+	if share := float64(counts[1]) / n; math.Abs(share-0.7) > 0.02 {
+		t.Errorf("variant A share = %.3f, want ~0.70", share)
+	}
+}
+
+func TestFind(t *testing.T) {
+	variants := []models.Variant{{ID: 1, Name: "A", Weight: 1}, {ID: 2, Name: "B", Weight: 0}}
+	if Find(variants, 1) == nil {
+		t.Error("expected to find variant 1")
+	}
+	if Find(variants, 2) != nil {
+		t.Error("zero-weight variant should not be returned")
+	}
+	if Find(variants, 99) != nil {
+		t.Error("unknown variant should not be returned")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	ok := []models.Variant{{Name: "A", Weight: 70}, {Name: "B", Weight: 30}}
+	if err := Validate(ok); err != nil {
+		t.Errorf("Validate(ok) = %v", err)
+	}
+
+	bad := [][]models.Variant{
+		{{Name: "A", Weight: 100}},
+		{{Name: "A", Weight: 1}, {Name: "A", Weight: 1}},
+		{{Name: "A", Weight: 0}, {Name: "B", Weight: 0}},
+		{{Name: "A", Weight: -1}, {Name: "B", Weight: 5}},
+		{{Name: "", Weight: 1}, {Name: "B", Weight: 1}},
+	}
+	for i, vs := range bad {
+		if err := Validate(vs); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
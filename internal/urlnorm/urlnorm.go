@@ -0,0 +1,286 @@
+// Package urlnorm canonicalizes destination URLs so equivalent spellings of the
+// same address are stored, compared and deduplicated as one string.
+//
+// Canonicalization lowercases the scheme and host, converts internationalized
+// hosts to punycode, drops default ports, resolves "." and ".." path segments,
+// decodes percent-escapes of unreserved characters and uppercases the rest.
+// Sorting the query and stripping tracking parameters are optional because
+// some servers depend on parameter order.
+package urlnorm
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// MaxLength is the longest URL accepted, before and after canonicalization.
+// 2048 characters work in every browser and client (see DESIGN.md).
+const MaxLength = 2048
+
+var (
+	ErrTooLong           = fmt.Errorf("URL is longer than %d characters", MaxLength)
+	ErrUnsupportedScheme = errors.New("only http and https URLs are allowed")
+	ErrMissingHost       = errors.New("URL has no host")
+	ErrUserInfo          = errors.New("URLs with credentials (user@host) are not allowed")
+)
+
+// DefaultTrackingParams are stripped when Options.StripTracking is set.
+// Entries ending in "*" match any parameter with that prefix.
+var DefaultTrackingParams = []string{
+	"utm_*", "fbclid", "gclid", "dclid", "gbraid", "wbraid", "msclkid", "yclid",
+	"mc_cid", "mc_eid", "igshid", "_ga", "_gl", "_hsenc", "_hsmi", "mkt_tok",
+}
+
+// Options enables the optional rewrites.
+type Options struct {
+	SortQuery      bool     // order query parameters by name (stable for repeated names)
+	StripTracking  bool     // drop TrackingParams (DefaultTrackingParams if nil)
+	TrackingParams []string // case-insensitive names, "prefix*" for prefixes
+}
+
+// Canonicalize validates rawURL and returns its canonical form.
+func Canonicalize(rawURL string, opts Options) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if len(rawURL) > MaxLength {
+		return "", ErrTooLong
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", ErrUnsupportedScheme
+	}
+	if u.User != nil {
+		// https://paypal.com@evil.example/ goes to evil.example
+		return "", ErrUserInfo
+	}
+	if u.Opaque != "" {
+		return "", ErrMissingHost
+	}
+
+	host, err := canonicalHost(u.Scheme, u.Host)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString(u.Scheme)
+	b.WriteString("://")
+	b.WriteString(host)
+
+	path := removeDotSegments(normalizeEscapes(u.EscapedPath()))
+	if path == "" {
+		path = "/"
+	}
+	b.WriteString(path)
+
+	query := normalizeEscapes(u.RawQuery)
+	if opts.SortQuery || opts.StripTracking {
+		query = rewriteQuery(query, opts)
+	}
+	if query != "" {
+		b.WriteByte('?')
+		b.WriteString(query)
+	}
+
+	if u.Fragment != "" {
+		b.WriteByte('#')
+		b.WriteString(normalizeEscapes(u.EscapedFragment()))
+	}
+
+	out := b.String()
+	if len(out) > MaxLength {
+		return "", ErrTooLong
+	}
+	return out, nil
+}
+
+// canonicalHost lowercases the host, converts IDNs to punycode and drops the
+// scheme's default port.
+func canonicalHost(scheme, hostport string) (string, error) {
+	host, port := hostport, ""
+	if i := strings.LastIndexByte(hostport, ':'); i >= 0 && !strings.Contains(hostport[i:], "]") {
+		host, port = hostport[:i], hostport[i+1:]
+	}
+	if (scheme == "http" && port == "80") || (scheme == "https" && port == "443") {
+		port = ""
+	}
+
+	if strings.HasPrefix(host, "[") {
+		ip := net.ParseIP(strings.Trim(host, "[]"))
+		if ip == nil {
+			return "", fmt.Errorf("invalid IPv6 host %q", host)
+		}
+		host = "[" + ip.String() + "]"
+	} else {
+		host = strings.TrimSuffix(host, ".")
+		if host == "" {
+			return "", ErrMissingHost
+		}
+		unescaped, err := url.PathUnescape(host)
+		if err != nil {
+			return "", fmt.Errorf("invalid host %q", host)
+		}
+		ascii, err := idna.Lookup.ToASCII(unescaped)
+		if err != nil {
+			// Lookup rejects some hosts browsers accept (e.g. underscores); keep
+			// those as long as they are plain ASCII
+			if !isASCII(unescaped) {
+				return "", fmt.Errorf("invalid host %q: %v", host, err)
+			}
+			ascii = strings.ToLower(unescaped)
+		}
+		host = ascii
+	}
+
+	if port != "" {
+		return host + ":" + port, nil
+	}
+	return host, nil
+}
+
+// rewriteQuery strips tracking parameters and/or sorts parameters by name.
+// Names and values keep their (already normalized) encoding.
+func rewriteQuery(query string, opts Options) string {
+	tracking := opts.TrackingParams
+	if tracking == nil {
+		tracking = DefaultTrackingParams
+	}
+
+	var params []string
+	for _, p := range strings.Split(query, "&") {
+		if p == "" {
+			continue
+		}
+		if opts.StripTracking && isTracking(paramName(p), tracking) {
+			continue
+		}
+		params = append(params, p)
+	}
+	if opts.SortQuery {
+		sort.SliceStable(params, func(i, j int) bool { return paramName(params[i]) < paramName(params[j]) })
+	}
+	return strings.Join(params, "&")
+}
+
+func paramName(p string) string {
+	name, _, _ := strings.Cut(p, "=")
+	if n, err := url.QueryUnescape(name); err == nil {
+		return n
+	}
+	return name
+}
+
+func isTracking(name string, tracking []string) bool {
+	name = strings.ToLower(name)
+	for _, t := range tracking {
+		t = strings.ToLower(t)
+		if prefix, ok := strings.CutSuffix(t, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == t {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeEscapes decodes percent-escapes of unreserved characters, uppercases
+// the hex digits of the remaining escapes and escapes bytes that may not appear
+// literally in a URL. Reserved characters keep their encoded or literal form,
+// since decoding "%2F" or "%26" would change the meaning.
+func normalizeEscapes(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]) {
+			d := unhex(s[i+1])<<4 | unhex(s[i+2])
+			if isUnreserved(d) {
+				b.WriteByte(d)
+			} else {
+				fmt.Fprintf(&b, "%%%02X", d)
+			}
+			i += 2
+			continue
+		}
+		if c == '%' || mustEscape(c) {
+			// A "%" that doesn't start an escape can only be a literal percent sign
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// removeDotSegments implements RFC 3986 section 5.2.4.
+func removeDotSegments(path string) string {
+	if !strings.Contains(path, ".") {
+		return path
+	}
+	var out []string
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		last := i == len(segments)-1
+		switch seg {
+		case ".":
+			if last {
+				out = append(out, "")
+			}
+		case "..":
+			if len(out) > 1 {
+				out = out[:len(out)-1]
+			}
+			if last {
+				out = append(out, "")
+			}
+		default:
+			out = append(out, seg)
+		}
+	}
+	return strings.Join(out, "/")
+}
+
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+// mustEscape reports bytes that are never valid literally in a URL.
+func mustEscape(c byte) bool {
+	return c <= 0x20 || c >= 0x7f || strings.IndexByte("\"<>\\^`{|}", c) >= 0
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package urlnorm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"HTTPS://Example.COM", "https://example.com/"},
+		{"http://example.com:80/a", "http://example.com/a"},
+		{"https://example.com:443/a", "https://example.com/a"},
+		{"http://example.com:8080/a", "http://example.com:8080/a"},
+		{"https://example.com:80/", "https://example.com:80/"},
+		{"https://example.com./x", "https://example.com/x"},
+		{"https://münchen.de/straße", "https://xn--mnchen-3ya.de/stra%C3%9Fe"},
+		{"https://xn--mnchen-3ya.de/", "https://xn--mnchen-3ya.de/"},
+		{"https://example.com/%7euser/%41b%2fc", "https://example.com/~user/Ab%2Fc"},
+		{"https://example.com/a%2fb?q=%e2%82%ac", "https://example.com/a%2Fb?q=%E2%82%AC"},
+		{"https://example.com/a b", "https://example.com/a%20b"},
+		{"https://example.com/a/./b/../c", "https://example.com/a/c"},
+		{"https://example.com/../x", "https://example.com/x"},
+		{"https://example.com/a?x=1&b=2#Frag", "https://example.com/a?x=1&b=2#Frag"},
+		{"https://example.com/?q=100%", "https://example.com/?q=100%25"},
+		{"http://[2001:DB8::1]:80/", "http://[2001:db8::1]/"},
+		{"  https://example.com/  ", "https://example.com/"},
+	}
+	for _, tt := range tests {
+		got, err := Canonicalize(tt.in, Options{})
+		if err != nil {
+			t.Errorf("Canonicalize(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Canonicalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		// Canonical forms are fixed points
+		if again, _ := Canonicalize(got, Options{}); again != got {
+			t.Errorf("Canonicalize(%q) = %q, not idempotent", got, again)
+		}
+	}
+}
+
+func TestCanonicalizeQueryOptions(t *testing.T) {
+	in := "https://example.com/p?z=1&utm_source=news&a=2&FBCLID=x&a=1&&ref=home"
+	tests := []struct {
+		opts Options
+		want string
+	}{
+		{Options{}, "https://example.com/p?z=1&utm_source=news&a=2&FBCLID=x&a=1&&ref=home"},
+		{Options{StripTracking: true}, "https://example.com/p?z=1&a=2&a=1&ref=home"},
+		{Options{SortQuery: true}, "https://example.com/p?FBCLID=x&a=2&a=1&ref=home&utm_source=news&z=1"},
+		{Options{SortQuery: true, StripTracking: true}, "https://example.com/p?a=2&a=1&ref=home&z=1"},
+		{Options{StripTracking: true, TrackingParams: []string{"ref"}}, "https://example.com/p?z=1&utm_source=news&a=2&FBCLID=x&a=1"},
+	}
+	for _, tt := range tests {
+		if got, err := Canonicalize(in, tt.opts); err != nil || got != tt.want {
+			t.Errorf("%+v: got %q, %v; want %q", tt.opts, got, err, tt.want)
+		}
+	}
+
+	// A query made only of tracking parameters disappears entirely
+	got, _ := Canonicalize("https://example.com/?utm_medium=email&gclid=1", Options{StripTracking: true})
+	if got != "https://example.com/" {
+		t.Errorf("got %q, want bare URL", got)
+	}
+}
+
+func TestCanonicalizeRejects(t *testing.T) {
+	tests := []struct {
+		in   string
+		want error
+	}{
+		{"ftp://example.com/file", ErrUnsupportedScheme},
+		{"javascript:alert(1)", ErrUnsupportedScheme},
+		{"example.com/path", ErrUnsupportedScheme},
+		{"https://", ErrMissingHost},
+		{"https:example.com", ErrMissingHost},
+		{"https://paypal.com@evil.example/", ErrUserInfo},
+		{"https://example.com/" + strings.Repeat("a", MaxLength), ErrTooLong},
+		// Short enough as typed, too long once the spaces are escaped
+		{"https://example.com/" + strings.Repeat("a ", MaxLength/3), ErrTooLong},
+	}
+	for _, tt := range tests {
+		if _, err := Canonicalize(tt.in, Options{}); !errors.Is(err, tt.want) {
+			t.Errorf("Canonicalize(%.40q) error %v, want %v", tt.in, err, tt.want)
+		}
+	}
+	if _, err := Canonicalize("https://exa mple.com/", Options{}); err == nil {
+		t.Error("host with a space: want error")
+	}
+}
@@ -158,5 +158,7 @@ func (c *LRUCache) Peek(key string) (interface{}, bool) {
 }
 
 func (c *LRUCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return len(c.cache)
 }
@@ -8,8 +8,22 @@ import (
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Siddarth2230/url-shortener/pkg/tracing"
 )
 
+var tracer = otel.Tracer("github.com/Siddarth2230/url-shortener/pkg/cache")
+
+// span starts a client span for a Redis command.
+func span(ctx context.Context, op string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "RedisCache."+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system", "redis"), attribute.String("db.operation", op)))
+}
+
 var (
 	// ErrCacheMiss indicates the key was not found in cache
 	ErrCacheMiss = errors.New("cache miss")
@@ -72,7 +86,16 @@ func NewRedisCacheFromConfig(cfg RedisCacheConfig) *RedisCache {
 }
 
 // Get retrieves a value from Redis and unmarshals into v
-func (r *RedisCache) Get(ctx context.Context, key string, v interface{}) error {
+func (r *RedisCache) Get(ctx context.Context, key string, v interface{}) (err error) {
+	ctx, sp := span(ctx, "Get")
+	defer func() {
+		sp.SetAttributes(attribute.Bool("cache.hit", err == nil))
+		if err == ErrCacheMiss {
+			sp.End() // a miss isn't a failure
+			return
+		}
+		tracing.End(sp, err)
+	}()
 	// Add prefix to key for namespacing
 	fullKey := r.prefix + key
 
@@ -96,7 +119,10 @@ func (r *RedisCache) Set(ctx context.Context, key string, v interface{}) error {
 	return r.SetWithTTL(ctx, key, v, r.ttl)
 }
 
-func (r *RedisCache) SetWithTTL(ctx context.Context, key string, v interface{}, ttl time.Duration) error {
+func (r *RedisCache) SetWithTTL(ctx context.Context, key string, v interface{}, ttl time.Duration) (err error) {
+	ctx, sp := span(ctx, "Set")
+	defer func() { tracing.End(sp, err) }()
+
 	fullKey := r.prefix + key
 
 	data, err := json.Marshal(v)
@@ -112,14 +138,74 @@ func (r *RedisCache) SetWithTTL(ctx context.Context, key string, v interface{},
 }
 
 // Delete removes a key from Redis
-func (r *RedisCache) Delete(ctx context.Context, key string) error {
+func (r *RedisCache) Delete(ctx context.Context, key string) (err error) {
+	ctx, sp := span(ctx, "Delete")
+	defer func() { tracing.End(sp, err) }()
+
 	fullKey := r.prefix + key
 	return r.client.Del(ctx, fullKey).Err()
 }
 
 // Exists checks if a key exists
-func (r *RedisCache) Exists(ctx context.Context, key string) (bool, error) {
+func (r *RedisCache) Exists(ctx context.Context, key string) (_ bool, err error) {
+	ctx, sp := span(ctx, "Exists")
+	defer func() { tracing.End(sp, err) }()
+
 	fullKey := r.prefix + key
 	n, err := r.client.Exists(ctx, fullKey).Result()
 	return n > 0, err
 }
+
+// invalidationChannel is the pub/sub channel used to tell every server to drop
+// a key from its in-process (L1) cache.
+const invalidationChannel = "invalidate"
+
+// PublishInvalidation broadcasts that key changed so other processes can evict it from L1.
+func (r *RedisCache) PublishInvalidation(ctx context.Context, key string) (err error) {
+	ctx, sp := span(ctx, "PublishInvalidation")
+	defer func() { tracing.End(sp, err) }()
+
+	return r.client.Publish(ctx, r.prefix+invalidationChannel, key).Err()
+}
+
+// SubscribeInvalidations calls fn for every key published via PublishInvalidation
+// until ctx is canceled. It blocks, so run it in its own goroutine.
+func (r *RedisCache) SubscribeInvalidations(ctx context.Context, fn func(key string)) error {
+	sub := r.client.Subscribe(ctx, r.prefix+invalidationChannel)
+	defer sub.Close()
+
+	// Wait for confirmation so callers know the subscription is live
+	if _, err := sub.Receive(ctx); err != nil {
+		return fmt.Errorf("redis subscribe error: %w", err)
+	}
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			fn(msg.Payload)
+		}
+	}
+}
+
+// IncrWithTTL atomically increments a counter and returns the new value. The TTL
+// is set when the counter is created, so the counter resets ttl after the first hit.
+func (r *RedisCache) IncrWithTTL(ctx context.Context, key string, ttl time.Duration) (_ int64, err error) {
+	ctx, sp := span(ctx, "IncrWithTTL")
+	defer func() { tracing.End(sp, err) }()
+
+	fullKey := r.prefix + key
+
+	pipe := r.client.TxPipeline()
+	incr := pipe.Incr(ctx, fullKey)
+	pipe.ExpireNX(ctx, fullKey, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("redis incr error: %w", err)
+	}
+	return incr.Val(), nil
+}
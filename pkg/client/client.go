@@ -0,0 +1,356 @@
+// Package client is a Go client for the URL shortener's HTTP API, as described
+// by api/openapi.json. Request and response types are the ones the server's
+// handlers use, so the two can't drift apart.
+//
+//	c := client.New("https://sho.rt")
+//	c.APIKey = os.Getenv("SHORTENER_API_KEY")
+//	link, err := c.Shorten(ctx, client.ShortenRequest{URL: "https://example.com/launch"})
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Siddarth2230/url-shortener/internal/models"
+	"github.com/Siddarth2230/url-shortener/pkg/problem"
+)
+
+// Types exchanged with the API.
+type (
+	ShortenRequest             = models.ShortenRequest
+	ShortenResponse            = models.ShortenResponse
+	Link                       = models.URL
+	LinkPage                   = models.URLPage
+	LinkPreview                = models.LinkPreview
+	ClickStats                 = models.ClickStats
+	AbuseReport                = models.AbuseReport
+	WebhookSubscriptionRequest = models.WebhookSubscriptionRequest
+	WebhookSubscription        = models.WebhookSubscription
+	WebhookDelivery            = models.WebhookDelivery
+)
+
+// Client calls the API at BaseURL. Set the exported fields before first use.
+type Client struct {
+	BaseURL string
+	// APIKey is sent as a bearer token when set. Webhook endpoints require
+	// one; links shortened with one are owned by it.
+	APIKey string
+	// HTTPClient defaults to a client with a 10s timeout. Redirects are never
+	// followed, so Resolve can report where a link goes.
+	HTTPClient *http.Client
+}
+
+// New returns a client for the API at baseURL, e.g. "https://sho.rt".
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Error is a non-2xx answer from the API, read from its problem details.
+type Error struct {
+	StatusCode int
+	// Code is the machine-readable error code, e.g. "invalid_custom_code"
+	// or "custom_code_taken". Branch on it rather than on Message.
+	Code    string
+	Field   string // request field at fault, if any
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("shortener: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// ErrorCode returns the Code of an *Error in err's chain, or "".
+func ErrorCode(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
+
+// IsNotFound reports whether err is a 404 from the API.
+func IsNotFound(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.StatusCode == http.StatusNotFound
+}
+
+// Shorten creates a short link (POST /shorten).
+func (c *Client) Shorten(ctx context.Context, req ShortenRequest) (*ShortenResponse, error) {
+	var resp ShortenResponse
+	if err := c.do(ctx, http.MethodPost, "/shorten", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Resolve returns the destination a short code redirects to (GET /{shortCode})
+// without following it. It counts as a click. Links that answer with a page
+// instead (password prompt, interstitial, quarantine warning) return an *Error
+// with that status.
+func (c *Client) Resolve(ctx context.Context, shortCode string) (string, error) {
+	resp, err := c.send(ctx, http.MethodGet, "/"+url.PathEscape(shortCode), nil, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusFound {
+		return resp.Header.Get("Location"), nil
+	}
+	if err := checkStatus(resp); err != nil {
+		return "", err
+	}
+	return "", &Error{StatusCode: resp.StatusCode, Message: "link answered with a page instead of a redirect"}
+}
+
+// Preview describes a link without following it or counting a click
+// (GET /{shortCode}+).
+func (c *Client) Preview(ctx context.Context, shortCode string) (*LinkPreview, error) {
+	var p LinkPreview
+	q := url.Values{"format": {"json"}}
+	if err := c.do(ctx, http.MethodGet, "/"+url.PathEscape(shortCode)+"+", q, nil, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ListOptions filter and page GET /api/links. Zero values are left out.
+type ListOptions struct {
+	Query         string
+	Tag           string
+	Domain        *string // nil = every domain, "" = the default domain
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	Expiry        string // "active" or "expired"
+	Sort          string // e.g. "-created_at" (default) or "short_code"
+	Cursor        string // NextCursor of the previous page
+	Limit         int
+}
+
+// ListLinks returns one page of the API key's links (GET /api/links).
+// Destinations of password-protected links are left out.
+func (c *Client) ListLinks(ctx context.Context, opts ListOptions) (*LinkPage, error) {
+	q := url.Values{}
+	set := func(k, v string) {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	set("q", opts.Query)
+	set("tag", opts.Tag)
+	if opts.Domain != nil {
+		q.Set("domain", *opts.Domain)
+	}
+	if !opts.CreatedAfter.IsZero() {
+		q.Set("created_after", opts.CreatedAfter.Format(time.RFC3339))
+	}
+	if !opts.CreatedBefore.IsZero() {
+		q.Set("created_before", opts.CreatedBefore.Format(time.RFC3339))
+	}
+	set("expiry", opts.Expiry)
+	set("sort", opts.Sort)
+	set("cursor", opts.Cursor)
+	if opts.Limit > 0 {
+		q.Set("limit", strconv.Itoa(opts.Limit))
+	}
+
+	var page LinkPage
+	if err := c.do(ctx, http.MethodGet, "/api/links", q, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// Stats returns click statistics with daily counts for the last days days
+// (GET /api/links/{shortCode}/stats). Zero days means the server default (30).
+func (c *Client) Stats(ctx context.Context, domain, shortCode string, days int) (*ClickStats, error) {
+	q := domainQuery(domain)
+	if days > 0 {
+		q.Set("days", strconv.Itoa(days))
+	}
+	var stats ClickStats
+	if err := c.do(ctx, http.MethodGet, "/api/links/"+url.PathEscape(shortCode)+"/stats", q, nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// QROptions select the QR code image. Zero values use the server defaults.
+type QROptions struct {
+	Format string // "png" (default) or "svg"
+	Size   int
+	Margin *int
+	Level  string // "L", "M", "Q" or "H"
+	FG, BG string // "#rrggbb"
+}
+
+// QRCode returns a QR code image of the short URL and its content type
+// (GET /api/links/{shortCode}/qr).
+func (c *Client) QRCode(ctx context.Context, domain, shortCode string, opts QROptions) ([]byte, string, error) {
+	q := domainQuery(domain)
+	for k, v := range map[string]string{"format": opts.Format, "level": opts.Level, "fg": opts.FG, "bg": opts.BG} {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	if opts.Size > 0 {
+		q.Set("size", strconv.Itoa(opts.Size))
+	}
+	if opts.Margin != nil {
+		q.Set("margin", strconv.Itoa(*opts.Margin))
+	}
+
+	resp, err := c.send(ctx, http.MethodGet, "/api/links/"+url.PathEscape(shortCode)+"/qr", q, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp); err != nil {
+		return nil, "", err
+	}
+	img, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return img, resp.Header.Get("Content-Type"), nil
+}
+
+// Report flags a link as abusive (POST /api/report/{shortCode}).
+func (c *Client) Report(ctx context.Context, domain, shortCode string, report AbuseReport) error {
+	return c.do(ctx, http.MethodPost, "/api/report/"+url.PathEscape(shortCode), domainQuery(domain), report, nil)
+}
+
+// CreateWebhook subscribes a URL to the API key's link events (POST /api/webhooks).
+// The returned subscription holds the signing secret; it isn't shown again.
+func (c *Client) CreateWebhook(ctx context.Context, req WebhookSubscriptionRequest) (*WebhookSubscription, error) {
+	var sub WebhookSubscription
+	if err := c.do(ctx, http.MethodPost, "/api/webhooks", nil, req, &sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// ListWebhooks returns the API key's subscriptions (GET /api/webhooks).
+func (c *Client) ListWebhooks(ctx context.Context) ([]WebhookSubscription, error) {
+	var subs []WebhookSubscription
+	if err := c.do(ctx, http.MethodGet, "/api/webhooks", nil, nil, &subs); err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+// DeleteWebhook removes a subscription (DELETE /api/webhooks/{id}).
+func (c *Client) DeleteWebhook(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, "/api/webhooks/"+strconv.FormatInt(id, 10), nil, nil, nil)
+}
+
+// DeadLetters returns deliveries that ran out of retries (GET /api/webhooks/dead-letters).
+// Zero limit means the server default.
+func (c *Client) DeadLetters(ctx context.Context, limit int) ([]WebhookDelivery, error) {
+	q := url.Values{}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	var deliveries []WebhookDelivery
+	if err := c.do(ctx, http.MethodGet, "/api/webhooks/dead-letters", q, nil, &deliveries); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// ReplayDelivery queues a dead delivery again (POST /api/webhooks/deliveries/{id}/replay).
+func (c *Client) ReplayDelivery(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodPost, "/api/webhooks/deliveries/"+strconv.FormatInt(id, 10)+"/replay", nil, nil, nil)
+}
+
+func domainQuery(domain string) url.Values {
+	q := url.Values{}
+	if domain != "" {
+		q.Set("domain", domain)
+	}
+	return q
+}
+
+// do sends body as JSON and decodes a 2xx JSON answer into out (if not nil).
+func (c *Client) do(ctx context.Context, method, path string, q url.Values, body, out interface{}) error {
+	resp, err := c.send(ctx, method, path, q, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp); err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("shortener: decode %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+func (c *Client) send(ctx context.Context, method, path string, q url.Values, body interface{}) (*http.Response, error) {
+	u := c.BaseURL + path
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json, "+problem.ContentType)
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+
+	hc := *c.httpClient()
+	hc.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	return hc.Do(req)
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// checkStatus turns a non-2xx answer into an *Error, reading its problem details.
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	e := &Error{StatusCode: resp.StatusCode}
+	var p problem.Details
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&p); err == nil {
+		e.Code, e.Field, e.Message = p.Code, p.Field, p.Error()
+	}
+	if e.Message == "" {
+		e.Message = http.StatusText(resp.StatusCode)
+	}
+	return e
+}
@@ -0,0 +1,298 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gorilla/mux"
+
+	"github.com/Siddarth2230/url-shortener/api"
+	"github.com/Siddarth2230/url-shortener/internal/handler"
+	"github.com/Siddarth2230/url-shortener/internal/middleware"
+	"github.com/Siddarth2230/url-shortener/internal/repository"
+	"github.com/Siddarth2230/url-shortener/internal/service"
+	"github.com/Siddarth2230/url-shortener/pkg/client"
+)
+
+// urlRow has the columns FindByShortCode and FindAnyByShortCode select.
+var urlRow = []string{"id", "domain", "short_code", "long_url", "created_at", "expires_at", "owner", "password_hash",
+	"max_clicks", "clicks_remaining", "rules", "status", "status_reason", "interstitial", "title", "notes",
+	"query_policy", "utm", "variants"}
+
+// newServer serves the real routes, validation middleware, handlers, service
+// and repository, with sqlmock in place of PostgreSQL.
+func newServer(t *testing.T) (*client.Client, sqlmock.Sqlmock, *mux.Router) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.MatchExpectationsInOrder(false)
+	t.Cleanup(func() { db.Close() })
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repo := repository.NewURLRepository(db, logger)
+	svc := service.NewURLService(repo, nil, "", 100, logger)
+
+	spec, err := api.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	validate, err := middleware.ValidateRequests(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := mux.NewRouter()
+	r.Use(validate)
+	handler.NewURLHandler(svc, logger).RegisterRoutes(r)
+
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	svc.BaseURL = srv.URL
+	return client.New(srv.URL), mock, r
+}
+
+func TestShortenAndResolve(t *testing.T) {
+	c, mock, _ := newServer(t)
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM reserved_codes")).WillReturnRows(sqlmock.NewRows([]string{"word", "kind"}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS")).WithArgs("", "launch").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO urls")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(7, "active"))
+
+	resp, err := c.Shorten(ctx, client.ShortenRequest{URL: "https://example.com/launch", CustomCode: "launch"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ShortCode != "launch" || resp.ShortURL != c.BaseURL+"/launch" || resp.LongURL != "https://example.com/launch" {
+		t.Errorf("Shorten = %+v", resp)
+	}
+
+	// The new link is served from the cache; only the click is written
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO clicks")).WillReturnResult(sqlmock.NewResult(0, 1))
+	dest, err := c.Resolve(ctx, "launch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dest != "https://example.com/launch" {
+		t.Errorf("Resolve = %q", dest)
+	}
+
+	// The click is recorded after the redirect
+	deadline := time.Now().Add(2 * time.Second)
+	for mock.ExpectationsWereMet() != nil && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPreview(t *testing.T) {
+	c, mock, _ := newServer(t)
+
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM urls")).WithArgs("", "docs").WillReturnRows(sqlmock.NewRows(urlRow).
+		AddRow(3, "", "docs", "https://example.com/docs", created, nil, nil, nil, nil, nil, nil, "active", nil, false, nil, nil, "ignore", nil, []byte("[]")))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM clicks")).WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(42))
+
+	p, err := c.Preview(context.Background(), "docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Destination != "https://example.com/docs" || p.Clicks != 42 || !p.CreatedAt.Equal(created) {
+		t.Errorf("Preview = %+v", p)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStatsNotFound(t *testing.T) {
+	c, mock, _ := newServer(t)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM urls")).WithArgs("", "missing").WillReturnRows(sqlmock.NewRows(urlRow))
+
+	_, err := c.Stats(context.Background(), "", "missing", 7)
+	if !client.IsNotFound(err) || client.ErrorCode(err) != "not_found" {
+		t.Fatalf("Stats = %v, want not found", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// Requests that don't match the document are rejected before any handler runs.
+func TestRequestValidation(t *testing.T) {
+	c, mock, _ := newServer(t)
+	ctx := context.Background()
+	zero := 0
+
+	tests := map[string]func() error{
+		"empty url": func() error {
+			_, err := c.Shorten(ctx, client.ShortenRequest{})
+			return err
+		},
+		"max_clicks below minimum": func() error {
+			_, err := c.Shorten(ctx, client.ShortenRequest{URL: "https://example.com", MaxClicks: &zero})
+			return err
+		},
+		"custom code too long": func() error {
+			_, err := c.Shorten(ctx, client.ShortenRequest{URL: "https://example.com", CustomCode: strings.Repeat("a", 33)})
+			return err
+		},
+		"unknown query policy": func() error {
+			_, err := c.Shorten(ctx, client.ShortenRequest{URL: "https://example.com", QueryPolicy: "append"})
+			return err
+		},
+		"days out of range": func() error {
+			_, err := c.Stats(ctx, "", "docs", 400)
+			return err
+		},
+		"unknown report reason": func() error {
+			return c.Report(ctx, "", "docs", client.AbuseReport{Reason: "boring"})
+		},
+		"unknown list sort": func() error {
+			_, err := c.ListLinks(ctx, client.ListOptions{Sort: "clicks"})
+			return err
+		},
+	}
+	for name, call := range tests {
+		err := call()
+		var apiErr *client.Error
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.Message == "" {
+			t.Errorf("%s: err = %v, want 400 with a message", name, err)
+		}
+		if code := client.ErrorCode(err); code != "invalid_request" {
+			t.Errorf("%s: code = %q, want invalid_request", name, code)
+		}
+	}
+	var apiErr *client.Error
+	if err := tests["max_clicks below minimum"](); !errors.As(err, &apiErr) || apiErr.Field != "max_clicks" {
+		t.Errorf("max_clicks below minimum: err = %#v, want field max_clicks", err)
+	}
+	// None of them reached the database
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// Service errors arrive as problem details with their status, code and field.
+func TestServiceErrors(t *testing.T) {
+	c, mock, _ := newServer(t)
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM reserved_codes")).WillReturnRows(sqlmock.NewRows([]string{"word", "kind"}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS")).WithArgs("", "taken").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM code_tombstones")).WillReturnRows(sqlmock.NewRows([]string{"available_at"}))
+
+	tests := []struct {
+		name   string
+		call   func() error
+		status int
+		code   string
+		field  string
+	}{
+		{"policy rejects custom code", func() error {
+			_, err := c.Shorten(ctx, client.ShortenRequest{URL: "https://example.com", CustomCode: "ab"})
+			return err
+		}, http.StatusBadRequest, "invalid_custom_code", "custom_code"},
+		{"custom code taken", func() error {
+			_, err := c.Shorten(ctx, client.ShortenRequest{URL: "https://example.com", CustomCode: "taken"})
+			return err
+		}, http.StatusConflict, "custom_code_taken", "custom_code"},
+		{"unknown domain", func() error {
+			_, err := c.Shorten(ctx, client.ShortenRequest{URL: "https://example.com", Domain: "go.example.org"})
+			return err
+		}, http.StatusBadRequest, "unknown_domain", "domain"},
+		{"webhooks without a key", func() error {
+			_, err := c.ListWebhooks(ctx)
+			return err
+		}, http.StatusUnauthorized, "api_key_required", ""},
+	}
+	for _, tt := range tests {
+		err := tt.call()
+		var apiErr *client.Error
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status || apiErr.Code != tt.code || apiErr.Field != tt.field {
+			t.Errorf("%s: err = %#v, want %d %s %q", tt.name, err, tt.status, tt.code, tt.field)
+		}
+	}
+}
+
+func TestWebhooksRequireAPIKey(t *testing.T) {
+	c, _, _ := newServer(t)
+	_, err := c.ListWebhooks(context.Background())
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("ListWebhooks without a key = %v, want 401", err)
+	}
+}
+
+func TestDocs(t *testing.T) {
+	c, _, _ := newServer(t)
+
+	resp, err := http.Get(c.BaseURL + "/api/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != string(api.Spec) {
+		t.Errorf("GET /api/openapi.json = %d, body differs from the embedded document", resp.StatusCode)
+	}
+
+	resp, err = http.Get(c.BaseURL + "/api/docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "/api/links/{shortCode}/stats") {
+		t.Errorf("GET /api/docs = %d:\n%s", resp.StatusCode, body)
+	}
+}
+
+// Every route is documented and every documented operation is routed.
+func TestSpecMatchesRoutes(t *testing.T) {
+	_, _, r := newServer(t)
+	spec, err := api.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var routed []string
+	r.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		tpl, err1 := route.GetPathTemplate()
+		methods, err2 := route.GetMethods()
+		if err1 == nil && err2 == nil {
+			for _, m := range methods {
+				routed = append(routed, m+" "+tpl)
+			}
+		}
+		return nil
+	})
+	var documented []string
+	for path, item := range spec.Paths.Map() {
+		for method := range item.Operations() {
+			documented = append(documented, method+" "+path)
+		}
+	}
+	sort.Strings(routed)
+	sort.Strings(documented)
+	if strings.Join(routed, "\n") != strings.Join(documented, "\n") {
+		t.Errorf("routes and OpenAPI paths differ\nrouted:\n%s\n\ndocumented:\n%s", strings.Join(routed, "\n"), strings.Join(documented, "\n"))
+	}
+}
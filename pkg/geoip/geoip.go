@@ -0,0 +1,44 @@
+// Package geoip resolves IP addresses to countries from a local MaxMind-format
+// (MMDB) database such as GeoLite2-Country. Lookups never leave the process.
+package geoip
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Reader looks up countries in an MMDB file. It is safe for concurrent use.
+type Reader struct {
+	db *maxminddb.Reader
+}
+
+type countryRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+}
+
+// Open memory-maps the MMDB file at path.
+func Open(path string) (*Reader, error) {
+	db, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open geoip database: %w", err)
+	}
+	return &Reader{db: db}, nil
+}
+
+// Country returns the ISO 3166-1 alpha-2 code for ip, or "" if it's not in the database.
+func (r *Reader) Country(ip net.IP) (string, error) {
+	var rec countryRecord
+	if err := r.db.Lookup(ip, &rec); err != nil {
+		return "", err
+	}
+	return rec.Country.ISOCode, nil
+}
+
+// Close unmaps the database.
+func (r *Reader) Close() error {
+	return r.db.Close()
+}
@@ -1,34 +1,59 @@
 package idgen
 
-const alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+// Encoding converts numeric IDs to short codes over an alphabet.
+type Encoding struct {
+	alphabet  string
+	charIndex map[rune]int
+}
 
-var charIndex = func() map[rune]int {
+// NewEncoding returns an encoding whose base is the length of alphabet.
+func NewEncoding(alphabet string) *Encoding {
 	m := make(map[rune]int)
 	for i, r := range alphabet {
 		m[r] = i
 	}
-	return m
-}()
+	return &Encoding{alphabet: alphabet, charIndex: m}
+}
+
+var (
+	// Base62 is the default encoding: digits, upper-case, then lower-case letters.
+	Base62 = NewEncoding("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz")
+
+	// Base36 only uses lowercase letters, for deployments with case-insensitive codes.
+	Base36 = NewEncoding("0123456789abcdefghijklmnopqrstuvwxyz")
+)
+
+// Encode encodes n with Base62.
+func Encode(n uint64) string {
+	return Base62.Encode(n)
+}
+
+// Decode decodes a Base62 code.
+func Decode(s string) uint64 {
+	return Base62.Decode(s)
+}
 
 // Time complexity is O(k^2) where k is length of the resulting string
 // because of repeated prepending to the slice. Could be optimized if needed.
-func Encode(n uint64) string {
+func (e *Encoding) Encode(n uint64) string {
 	if n == 0 {
-		return "0"
+		return e.alphabet[:1]
 	}
+	base := uint64(len(e.alphabet))
 	var b []byte
 	for n > 0 {
-		rem := n % 62
-		b = append([]byte{alphabet[rem]}, b...) // prepend
-		n /= 62
+		rem := n % base
+		b = append([]byte{e.alphabet[rem]}, b...) // prepend
+		n /= base
 	}
 	return string(b)
 }
 
-func Decode(s string) uint64 {
+func (e *Encoding) Decode(s string) uint64 {
 	var n uint64
+	base := uint64(len(e.alphabet))
 	for _, ch := range s {
-		n = n*62 + uint64(charIndex[ch])
+		n = n*base + uint64(e.charIndex[ch])
 	}
 	return n
 }
@@ -52,3 +52,24 @@ func TestEncodeDecodeRoundtrip(t *testing.T) {
 		}
 	}
 }
+
+func TestBase36(t *testing.T) {
+	tests := []struct {
+		input    uint64
+		expected string
+	}{
+		{0, "0"},
+		{36, "10"},
+		{12345, "9ix"},
+		{60466175, "zzzzz"}, // 36^5 - 1
+	}
+
+	for _, tt := range tests {
+		if result := Base36.Encode(tt.input); result != tt.expected {
+			t.Errorf("Base36.Encode(%d) = %s; want %s", tt.input, result, tt.expected)
+		}
+		if result := Base36.Decode(tt.expected); result != tt.input {
+			t.Errorf("Base36.Decode(%s) = %d; want %d", tt.expected, result, tt.input)
+		}
+	}
+}
@@ -9,6 +9,9 @@ import (
 
 type CounterGenerator struct {
 	redis *redis.Client
+
+	// Encoding turns counter values into codes (Base62 when nil).
+	Encoding *Encoding
 }
 
 func NewCounterGenerator(redisClient *redis.Client) *CounterGenerator {
@@ -22,7 +25,11 @@ func (g *CounterGenerator) Generate(ctx context.Context) (string, error) {
 		return "", fmt.Errorf("failed to increment counter: %w", err)
 	}
 
-	shortCode := Encode(uint64(val))
+	enc := g.Encoding
+	if enc == nil {
+		enc = Base62
+	}
+	shortCode := enc.Encode(uint64(val))
 
 	return shortCode, nil
 }
@@ -0,0 +1,108 @@
+// Package logging builds the structured (log/slog) loggers used across the
+// service and carries per-request loggers in contexts.
+//
+// Loggers redact attributes that may hold secrets (passwords, API keys,
+// webhook secrets, authorization headers) and truncate client IP addresses to
+// their network prefix before anything is written.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+)
+
+// Redacted replaces the value of secret attributes.
+const Redacted = "[REDACTED]"
+
+// Options select the level and output format.
+type Options struct {
+	Level  slog.Level
+	Format string // "json" (default) or "text"
+}
+
+// New returns a logger writing to w.
+func New(w io.Writer, opts Options) (*slog.Logger, error) {
+	ho := &slog.HandlerOptions{Level: opts.Level, ReplaceAttr: redact}
+	switch opts.Format {
+	case "", "json":
+		return slog.New(slog.NewJSONHandler(w, ho)), nil
+	case "text":
+		return slog.New(slog.NewTextHandler(w, ho)), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q (want json or text)", opts.Format)
+	}
+}
+
+// ParseLevel parses debug, info, warn or error (case-insensitive).
+func ParseLevel(s string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q (want debug, info, warn or error)", s)
+	}
+	return l, nil
+}
+
+type ctxKey struct{}
+
+// WithContext returns a context carrying logger.
+func WithContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or fallback if there is none.
+func FromContext(ctx context.Context, fallback *slog.Logger) *slog.Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return l
+	}
+	if fallback != nil {
+		return fallback
+	}
+	return slog.Default()
+}
+
+// secretKeys are attribute names whose values are never logged.
+var secretKeys = []string{"password", "secret", "token", "authorization", "api_key", "apikey", "cookie"}
+
+// ipKeys are attribute names holding client addresses.
+var ipKeys = map[string]bool{"ip": true, "client_ip": true, "remote_addr": true}
+
+func redact(_ []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+	for _, k := range secretKeys {
+		if strings.Contains(key, k) {
+			return slog.String(a.Key, Redacted)
+		}
+	}
+	if a.Value.Kind() != slog.KindString {
+		return a
+	}
+	if ipKeys[key] {
+		return slog.String(a.Key, RedactIP(a.Value.String()))
+	}
+	// Issued secrets are recognizable by prefix whatever the attribute is called
+	if v := a.Value.String(); strings.HasPrefix(v, "sk_") || strings.HasPrefix(v, "whsec_") {
+		return slog.String(a.Key, Redacted)
+	}
+	return a
+}
+
+// RedactIP keeps the /24 of an IPv4 and the /48 of an IPv6 address, with or
+// without a port, so logs show the network but not the visitor.
+func RedactIP(addr string) string {
+	host := addr
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		host = h
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return Redacted
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return ip.Mask(net.CIDRMask(48, 128)).String()
+}
@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, Options{Level: slog.LevelInfo})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("test",
+		"password", "hunter2",
+		"Authorization", "Bearer abc",
+		"webhook_secret", "s3cret",
+		"key", "sk_0123456789",
+		"ip", "203.0.113.77",
+		"remote_addr", "[2001:db8:1234:5678::1]:443",
+		"code", "promo1",
+	)
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, buf.String())
+	}
+	want := map[string]string{
+		"password":       Redacted,
+		"Authorization":  Redacted,
+		"webhook_secret": Redacted,
+		"key":            Redacted,
+		"ip":             "203.0.113.0",
+		"remote_addr":    "2001:db8:1234::",
+		"code":           "promo1",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %v, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestRedactIP(t *testing.T) {
+	tests := map[string]string{
+		"192.0.2.15":       "192.0.2.0",
+		"192.0.2.15:8080":  "192.0.2.0",
+		"::ffff:192.0.2.1": "192.0.2.0",
+		"2001:db8::1":      "2001:db8::",
+		"not-an-ip":        Redacted,
+		"":                 Redacted,
+	}
+	for in, want := range tests {
+		if got := RedactIP(in); got != want {
+			t.Errorf("RedactIP(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for in, want := range map[string]slog.Level{"debug": slog.LevelDebug, "INFO": slog.LevelInfo, "warn": slog.LevelWarn, "error": slog.LevelError} {
+		got, err := ParseLevel(in)
+		if err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("ParseLevel(verbose) succeeded")
+	}
+	if _, err := New(&bytes.Buffer{}, Options{Format: "xml"}); err == nil {
+		t.Error("New accepted format xml")
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	base := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	if got := FromContext(context.Background(), base); got != base {
+		t.Error("FromContext without a logger didn't return the fallback")
+	}
+	reqLogger := base.With("request_id", "abc")
+	ctx := WithContext(context.Background(), reqLogger)
+	if got := FromContext(ctx, base); got != reqLogger {
+		t.Error("FromContext didn't return the request logger")
+	}
+}
@@ -1,8 +1,11 @@
 package metrics
 
 import (
+	"context"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -38,7 +41,7 @@ var (
 			Help:    "Request duration in seconds",
 			Buckets: []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1},
 		},
-		[]string{"method", "status"},
+		[]string{"method", "route", "status"}, // route is the mux template, e.g. "/{shortCode}"
 	)
 
 	RequestTotal = promauto.NewCounterVec(
@@ -46,7 +49,82 @@ var (
 			Name: "url_requests_total",
 			Help: "Total number of requests",
 		},
-		[]string{"method", "status"},
+		[]string{"method", "route", "status"},
+	)
+
+	ResponseSize = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "url_response_size_bytes",
+			Help:    "Response body size in bytes",
+			Buckets: prometheus.ExponentialBuckets(64, 4, 8), // 64B .. 1MiB
+		},
+		[]string{"method", "route"},
+	)
+
+	RequestsInFlight = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "url_requests_in_flight",
+			Help: "Number of requests currently being served",
+		},
+		[]string{"route"},
+	)
+
+	// gRPC metrics, labeled by full method name, e.g. "/shortener.v1.Shortener/Shorten"
+	GRPCRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "url_grpc_request_duration_seconds",
+			Help:    "gRPC request duration in seconds",
+			Buckets: []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1},
+		},
+		[]string{"method", "code"}, // code is the status code name, e.g. "NotFound"
+	)
+
+	GRPCRequestTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "url_grpc_requests_total",
+			Help: "Total number of gRPC requests",
+		},
+		[]string{"method", "code"},
+	)
+
+	GRPCRequestsInFlight = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "url_grpc_requests_in_flight",
+			Help: "Number of gRPC requests currently being served",
+		},
+		[]string{"method"},
+	)
+
+	// Business metrics
+	LinksCreated = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "url_links_created_total",
+			Help: "Total number of links created through the shorten endpoint",
+		},
+		[]string{"code"}, // "custom" or "generated"
+	)
+
+	GeneratorRetries = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "url_generator_retries_total",
+			Help: "Total number of generated short codes discarded and redrawn",
+		},
+		[]string{"reason"}, // "empty", "cooling_down" or "collision"
+	)
+
+	GeneratorExhausted = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "url_generator_exhausted_total",
+			Help: "Total number of shorten requests that ran out of generator attempts",
+		},
+	)
+
+	LookupFailures = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "url_lookup_failures_total",
+			Help: "Total number of short code lookups answered with not found or expired",
+		},
+		[]string{"reason"}, // "not_found" or "expired"
 	)
 
 	// Database metrics
@@ -58,4 +136,43 @@ var (
 		},
 		[]string{"operation"},
 	)
+
+	// Webhook metrics
+	WebhookDeliveries = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "url_webhook_deliveries_total",
+			Help: "Total number of webhook delivery attempts",
+		},
+		[]string{"result"}, // "delivered", "retry" or "dead"
+	)
+
+	// Expiry sweeper metrics
+	ExpiredLinksSwept = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "url_expired_links_swept_total",
+			Help: "Total number of expired links removed by the sweeper",
+		},
+		[]string{"mode"}, // "archive" or "delete"
+	)
+
+	SweepDuration = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "url_expiry_sweep_duration_seconds",
+			Help:    "Duration of expiry sweeps in seconds",
+			Buckets: []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60},
+		},
+	)
 )
+
+// Observe records v, attaching the trace ID of ctx's span as an exemplar when
+// the span is sampled, so a slow bucket links to a trace that landed in it.
+// Exemplars are only exposed in the OpenMetrics format.
+func Observe(ctx context.Context, o prometheus.Observer, v float64) {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsSampled() {
+		if eo, ok := o.(prometheus.ExemplarObserver); ok {
+			eo.ObserveWithExemplar(v, prometheus.Labels{"trace_id": sc.TraceID().String()})
+			return
+		}
+	}
+	o.Observe(v)
+}
@@ -0,0 +1,54 @@
+// Package problem writes RFC 7807 problem details, the JSON error format
+// served as application/problem+json:
+//
+//	{"type":"about:blank","title":"Bad Request","status":400,
+//	 "detail":"invalid URL: URL is required","code":"invalid_url","field":"url"}
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ContentType is the media type of problem details.
+const ContentType = "application/problem+json"
+
+// Details is a problem details object. Code and Field are extension members:
+// a stable machine-readable identifier and the request field at fault.
+type Details struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code,omitempty"`
+	Field    string `json:"field,omitempty"`
+}
+
+// New returns details for status with the standard title. The type is
+// "about:blank": Code, not Type, tells problems of one status apart.
+func New(status int, code, detail string) *Details {
+	return &Details{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// Error returns the detail, or the title when there is none.
+func (d *Details) Error() string {
+	if d.Detail != "" {
+		return d.Detail
+	}
+	return d.Title
+}
+
+// Write sends d with its status.
+func Write(w http.ResponseWriter, d *Details) {
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(d.Status)
+	json.NewEncoder(w).Encode(d)
+}
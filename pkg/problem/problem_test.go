@@ -0,0 +1,60 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	d := New(http.StatusConflict, "custom_code_taken", "custom short code already taken")
+	d.Field = "custom_code"
+	d.Instance = "/shorten"
+
+	rec := httptest.NewRecorder()
+	Write(rec, d)
+
+	if rec.Code != http.StatusConflict {
+		t.Errorf("status = %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"type":     "about:blank",
+		"title":    "Conflict",
+		"status":   float64(409),
+		"detail":   "custom short code already taken",
+		"instance": "/shorten",
+		"code":     "custom_code_taken",
+		"field":    "custom_code",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %v, want %v", k, got[k], v)
+		}
+	}
+}
+
+func TestOptionalMembersOmitted(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Write(rec, New(http.StatusInternalServerError, "internal_error", ""))
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"detail", "instance", "field"} {
+		if _, ok := got[k]; ok {
+			t.Errorf("%s present in %s", k, rec.Body)
+		}
+	}
+	if d := New(http.StatusNotFound, "not_found", ""); d.Error() != "Not Found" {
+		t.Errorf("Error() = %q, want the title", d.Error())
+	}
+}
@@ -0,0 +1,215 @@
+// Package qrcode renders QR codes as PNG or SVG entirely in-process.
+package qrcode
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+
+	qr "github.com/skip2/go-qrcode"
+)
+
+// Format is an output image format.
+type Format string
+
+const (
+	PNG Format = "png"
+	SVG Format = "svg"
+)
+
+// ContentType returns the MIME type of the format.
+func (f Format) ContentType() string {
+	if f == SVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// Limits for Options.
+const (
+	MinSize   = 64
+	MaxSize   = 2048
+	MaxMargin = 16
+)
+
+// Options controls how a QR code is drawn. Zero values pick the defaults:
+// 256px, a 4-module quiet zone, level M, black on white.
+type Options struct {
+	Size       int         // image width and height in pixels
+	Margin     *int        // quiet zone in modules (nil = 4)
+	Level      string      // error correction: L, M, Q or H
+	Foreground color.NRGBA // zero value = black
+	Background color.NRGBA // zero value = white
+}
+
+var (
+	black = color.NRGBA{A: 0xff}
+	white = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+)
+
+// levels maps the standard level letters to the encoder's recovery levels.
+var levels = map[string]qr.RecoveryLevel{
+	"L": qr.Low,
+	"M": qr.Medium,
+	"Q": qr.High,
+	"H": qr.Highest,
+}
+
+// Normalize fills in defaults and validates the options.
+func (o Options) Normalize() (Options, error) {
+	if o.Size == 0 {
+		o.Size = 256
+	}
+	if o.Size < MinSize || o.Size > MaxSize {
+		return o, fmt.Errorf("size must be between %d and %d", MinSize, MaxSize)
+	}
+	if o.Margin == nil {
+		m := 4
+		o.Margin = &m
+	}
+	if *o.Margin < 0 || *o.Margin > MaxMargin {
+		return o, fmt.Errorf("margin must be between 0 and %d", MaxMargin)
+	}
+	o.Level = strings.ToUpper(o.Level)
+	if o.Level == "" {
+		o.Level = "M"
+	}
+	if _, ok := levels[o.Level]; !ok {
+		return o, errors.New("level must be L, M, Q or H")
+	}
+	if o.Foreground == (color.NRGBA{}) {
+		o.Foreground = black
+	}
+	if o.Background == (color.NRGBA{}) {
+		o.Background = white
+	}
+	return o, nil
+}
+
+// Render draws content as a QR code. Options are normalized first.
+func Render(content string, f Format, opts Options) ([]byte, error) {
+	opts, err := opts.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	code, err := qr.New(content, levels[opts.Level])
+	if err != nil {
+		return nil, err
+	}
+	code.DisableBorder = true // we draw our own quiet zone
+	modules := code.Bitmap()
+
+	switch f {
+	case PNG:
+		return renderPNG(modules, opts)
+	case SVG:
+		return renderSVG(modules, opts), nil
+	}
+	return nil, fmt.Errorf("unknown format %q", f)
+}
+
+// DataURI renders content as a PNG with default options, encoded as a data: URI.
+func DataURI(content string) (string, error) {
+	img, err := Render(content, PNG, Options{})
+	if err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(img), nil
+}
+
+// ETag identifies the image Render produces for these inputs, for HTTP caching.
+// It returns "" for options Render would reject.
+func ETag(content string, f Format, opts Options) string {
+	opts, err := opts.Normalize()
+	if err != nil {
+		return ""
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%d\x00%d\x00%s\x00%v\x00%v",
+		content, f, opts.Size, *opts.Margin, opts.Level, opts.Foreground, opts.Background)
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+func renderPNG(modules [][]bool, opts Options) ([]byte, error) {
+	n := len(modules) + 2*(*opts.Margin)
+	img := image.NewPaletted(image.Rect(0, 0, opts.Size, opts.Size), color.Palette{opts.Background, opts.Foreground})
+	// Map every pixel back to a module so any size works, not just multiples of n
+	for y := 0; y < opts.Size; y++ {
+		my := y*n/opts.Size - *opts.Margin
+		for x := 0; x < opts.Size; x++ {
+			mx := x*n/opts.Size - *opts.Margin
+			if my >= 0 && my < len(modules) && mx >= 0 && mx < len(modules) && modules[my][mx] {
+				img.SetColorIndex(x, y, 1)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func renderSVG(modules [][]bool, opts Options) []byte {
+	n := len(modules) + 2*(*opts.Margin)
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		opts.Size, opts.Size, n, n)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="%s"%s/>`, n, n, Hex(opts.Background), opacity(opts.Background))
+	fmt.Fprintf(&b, `<path fill="%s"%s d="`, Hex(opts.Foreground), opacity(opts.Foreground))
+	for y, row := range modules {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			// Merge horizontal runs into one rectangle to keep the path short
+			run := 1
+			for x+run < len(row) && row[x+run] {
+				run++
+			}
+			fmt.Fprintf(&b, "M%d %dh%dv1h-%dz", x+*opts.Margin, y+*opts.Margin, run, run)
+			x += run - 1
+		}
+	}
+	b.WriteString(`"/></svg>`)
+	return b.Bytes()
+}
+
+func opacity(c color.NRGBA) string {
+	if c.A == 0xff {
+		return ""
+	}
+	return fmt.Sprintf(` fill-opacity="%.3f"`, float64(c.A)/255)
+}
+
+// ParseColor parses "#rgb", "#rrggbb" or "#rrggbbaa" (the "#" is optional).
+func ParseColor(s string) (color.NRGBA, error) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) == 6 {
+		s += "ff"
+	}
+	if len(s) != 8 {
+		return color.NRGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
+// Hex formats a color as "#rrggbb", ignoring alpha.
+func Hex(c color.NRGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,122 @@
+package qrcode
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestRenderPNG(t *testing.T) {
+	margin := 2
+	fg := color.NRGBA{R: 0x12, G: 0x34, B: 0x56, A: 0xff}
+	data, err := Render("https://sho.rt/abc123", PNG, Options{Size: 300, Margin: &margin, Level: "h", Foreground: fg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 300 || b.Dy() != 300 {
+		t.Fatalf("size %v, want 300x300", b)
+	}
+
+	toNRGBA := func(c color.Color) color.NRGBA { return color.NRGBAModel.Convert(c).(color.NRGBA) }
+	// The quiet zone is background; the top-left finder pattern starts right after it
+	if got := toNRGBA(img.At(0, 0)); got != white {
+		t.Errorf("corner = %v, want white background", got)
+	}
+	n := 0
+	for x := 0; x < 300 && toNRGBA(img.At(x, 150)) != fg; x++ {
+		n++
+	}
+	if n == 0 || n >= 300 {
+		t.Errorf("no foreground module found on the middle row")
+	}
+}
+
+func TestRenderSVG(t *testing.T) {
+	bg := color.NRGBA{R: 0xff, G: 0xee, B: 0xdd, A: 0x80}
+	data, err := Render("https://sho.rt/abc123", SVG, Options{Size: 128, Background: bg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svg := string(data)
+	for _, want := range []string{`width="128"`, `fill="#ffeedd"`, `fill-opacity="0.502"`, `fill="#000000"`, "<path"} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("svg missing %s", want)
+		}
+	}
+}
+
+func TestOptionsValidation(t *testing.T) {
+	neg := -1
+	bad := []Options{
+		{Size: 10},
+		{Size: MaxSize + 1},
+		{Margin: &neg},
+		{Level: "X"},
+	}
+	for _, o := range bad {
+		if _, err := Render("x", PNG, o); err == nil {
+			t.Errorf("Render accepted %+v", o)
+		}
+		if ETag("x", PNG, o) != "" {
+			t.Errorf("ETag for invalid %+v should be empty", o)
+		}
+	}
+	if _, err := Render("x", "gif", Options{}); err == nil {
+		t.Error("Render accepted an unknown format")
+	}
+}
+
+func TestETag(t *testing.T) {
+	four := 4
+	base := ETag("https://sho.rt/a", PNG, Options{})
+	if base == "" || base != ETag("https://sho.rt/a", PNG, Options{Size: 256, Margin: &four, Level: "m"}) {
+		t.Error("defaults and explicit defaults should share an ETag")
+	}
+	others := []string{
+		ETag("https://sho.rt/b", PNG, Options{}),
+		ETag("https://sho.rt/a", SVG, Options{}),
+		ETag("https://sho.rt/a", PNG, Options{Size: 512}),
+		ETag("https://sho.rt/a", PNG, Options{Foreground: color.NRGBA{A: 0x80}}),
+	}
+	for i, e := range others {
+		if e == base {
+			t.Errorf("variant %d shares the default ETag", i)
+		}
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in   string
+		want color.NRGBA
+		ok   bool
+	}{
+		{"#000", color.NRGBA{A: 0xff}, true},
+		{"ff8800", color.NRGBA{R: 0xff, G: 0x88, A: 0xff}, true},
+		{"#FF880080", color.NRGBA{R: 0xff, G: 0x88, A: 0x80}, true},
+		{"#ff88", color.NRGBA{}, false},
+		{"#gggggg", color.NRGBA{}, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseColor(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseColor(%q) = %v, %v; want %v, ok=%v", tt.in, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDataURI(t *testing.T) {
+	uri, err := DataURI("https://sho.rt/abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(uri, "data:image/png;base64,") {
+		t.Errorf("unexpected data URI prefix: %.40s", uri)
+	}
+}
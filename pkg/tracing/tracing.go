@@ -0,0 +1,86 @@
+// Package tracing sets up OpenTelemetry tracing: the global tracer provider
+// with the configured exporter, and W3C trace context propagation.
+//
+// Instrumented packages get their tracer with otel.Tracer at package level;
+// it picks up the provider installed by Setup even if created earlier.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Options select where spans go.
+type Options struct {
+	// Exporter is "otlp" (OTLP over HTTP, configured by the standard
+	// OTEL_EXPORTER_OTLP_* variables), "stdout" or "none" (default).
+	Exporter    string
+	ServiceName string
+	// SampleRatio is the fraction of new traces recorded; traces started
+	// upstream follow the caller's decision. Zero records everything.
+	SampleRatio float64
+	// Writer receives stdout spans (os.Stdout when nil).
+	Writer io.Writer
+}
+
+// Setup installs the global tracer provider and propagator. The returned
+// function flushes buffered spans; call it on shutdown.
+func Setup(ctx context.Context, opts Options) (shutdown func(context.Context) error, err error) {
+	// Propagate context even without an exporter, so traces stay connected across services
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exp sdktrace.SpanExporter
+	switch opts.Exporter {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		w := opts.Writer
+		if w == nil {
+			w = os.Stdout
+		}
+		exp, err = stdouttrace.New(stdouttrace.WithWriter(w))
+	case "otlp":
+		exp, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q (want otlp, stdout or none)", opts.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ratio := opts.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", opts.ServiceName))),
+	)
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// End records err, if any, on span and ends it. With a named error result:
+//
+//	ctx, span := tracer.Start(ctx, "Op")
+//	defer func() { tracing.End(span, err) }()
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	secret := "whsec_test"
+	body := []byte(`{"type":"link.created"}`)
+	now := time.Unix(1700000000, 0)
+	header := Sign(secret, now, body)
+
+	if !strings.HasPrefix(header, "t=1700000000,v1=") {
+		t.Fatalf("header %q", header)
+	}
+	if err := Verify(secret, header, body, 5*time.Minute, now.Add(time.Minute)); err != nil {
+		t.Errorf("valid signature: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		now    time.Time
+		want   error
+	}{
+		{"wrong secret", "whsec_other", header, body, now, ErrSignatureMissing},
+		{"tampered body", secret, header, []byte(`{"type":"link.deleted"}`), now, ErrSignatureMissing},
+		{"stale", secret, header, body, now.Add(10 * time.Minute), ErrStaleTimestamp},
+		{"from the future", secret, header, body, now.Add(-10 * time.Minute), ErrStaleTimestamp},
+		{"garbage", secret, "nonsense", body, now, ErrInvalidHeader},
+		{"bad hex", secret, "t=1700000000,v1=zz", body, now, ErrInvalidHeader},
+		{"no timestamp", secret, "v1=00", body, now, ErrInvalidHeader},
+	}
+	for _, tt := range tests {
+		if err := Verify(tt.secret, tt.header, tt.body, 5*time.Minute, tt.now); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	// Rotating receivers may see several v1 signatures; any match is accepted
+	rotated := header + ",v1=" + strings.Repeat("0", 64)
+	if err := Verify(secret, rotated, body, 0, now.Add(time.Hour)); err != nil {
+		t.Errorf("multiple signatures, no tolerance: %v", err)
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	a, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := NewSecret()
+	if a == b || !strings.HasPrefix(a, "whsec_") || len(a) != len("whsec_")+64 {
+		t.Errorf("secrets %q, %q", a, b)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	b := Backoff{Base: time.Second, Max: time.Minute}
+	tests := []struct {
+		attempts int
+		min      time.Duration
+	}{
+		{0, time.Second},
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{4, 8 * time.Second},
+		{7, time.Minute}, // 64s capped
+		{100, time.Minute},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			d := b.Delay(tt.attempts)
+			if d < tt.min || d > tt.min+tt.min/5 {
+				t.Errorf("Delay(%d) = %s, want %s plus at most 20%%", tt.attempts, d, tt.min)
+				break
+			}
+		}
+	}
+
+	if d := (Backoff{}).Delay(1); d < 30*time.Second || d > 36*time.Second {
+		t.Errorf("default first delay %s", d)
+	}
+}
@@ -0,0 +1,10 @@
+-- Migration to case-insensitive short codes (CASE_INSENSITIVE_CODES=true).
+--
+-- 1. Run `urlctl collisions` and rename or delete all but one code of each group.
+-- 2. Apply this script. It fails if codes still collide.
+-- 3. Restart the API and urlctl with CASE_INSENSITIVE_CODES=true.
+
+-- Codes stay unique ignoring case, and case-folded lookups use this index
+CREATE UNIQUE INDEX IF NOT EXISTS idx_urls_domain_lower_short_code ON urls(domain, lower(short_code));
+
+CREATE INDEX IF NOT EXISTS idx_code_tombstones_domain_lower_short_code ON code_tombstones(domain, lower(short_code));
@@ -17,4 +17,253 @@ CREATE TABLE IF NOT EXISTS clicks (
 
 CREATE INDEX IF NOT EXISTS idx_urls_short_code ON urls(short_code);
 
-CREATE INDEX IF NOT EXISTS idx_clicks_short_code ON clicks(short_code);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_clicks_short_code ON clicks(short_code);
+
+-- Ownership and tagging (bulk import/export filters)
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS owner TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_urls_owner ON urls(owner);
+
+CREATE INDEX IF NOT EXISTS idx_urls_created_at ON urls(created_at);
+
+CREATE TABLE IF NOT EXISTS tags (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT UNIQUE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS url_tags (
+    url_id BIGINT REFERENCES urls(id) ON DELETE CASCADE,
+    tag_id BIGINT REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (url_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_url_tags_tag_id ON url_tags(tag_id);
+
+-- Password-protected links (bcrypt hash, NULL for public links)
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS password_hash TEXT;
+
+-- One-time / max-click links (NULL = unlimited)
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS max_clicks INTEGER;
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS clicks_remaining INTEGER;
+
+-- Conditional redirect rules (ordered JSON array, NULL = always use long_url)
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS rules JSONB;
+
+-- A/B split links: weighted destinations per short code
+CREATE TABLE IF NOT EXISTS url_variants (
+    id BIGSERIAL PRIMARY KEY,
+    url_id BIGINT NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    destination TEXT NOT NULL,
+    weight INTEGER NOT NULL CHECK (weight >= 0),
+    UNIQUE (url_id, position)
+);
+
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS variant_id BIGINT REFERENCES url_variants(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_clicks_variant_id ON clicks(variant_id);
+
+-- Branded domains: short codes are unique per domain ('' is the default domain).
+-- Clicks reference urls by id because short_code alone is no longer unique.
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS domain TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS url_id BIGINT REFERENCES urls(id) ON DELETE CASCADE;
+
+UPDATE clicks c SET url_id = u.id FROM urls u WHERE c.url_id IS NULL AND u.short_code = c.short_code;
+
+ALTER TABLE clicks DROP CONSTRAINT IF EXISTS clicks_short_code_fkey;
+
+ALTER TABLE urls DROP CONSTRAINT IF EXISTS urls_short_code_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_urls_domain_short_code ON urls(domain, short_code);
+
+CREATE INDEX IF NOT EXISTS idx_clicks_url_id ON clicks(url_id);
+
+-- Link status: disabled links never redirect, quarantined ones show a warning first.
+-- status_changed_at lets automatic quarantine count only reports since the last review.
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active'
+    CHECK (status IN ('active', 'disabled', 'quarantined'));
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS status_reason TEXT;
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMP WITH TIME ZONE;
+
+-- Written with NOW(), so existing values are in the session time zone
+ALTER TABLE urls ALTER COLUMN status_changed_at TYPE TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE IF NOT EXISTS abuse_reports (
+    id BIGSERIAL PRIMARY KEY,
+    url_id BIGINT NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    details TEXT,
+    reporter_ip TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (url_id, reporter_ip)
+);
+
+-- Reports used to be stored as UTC wall-clock time without a zone
+DO $$
+BEGIN
+    IF (SELECT data_type FROM information_schema.columns
+        WHERE table_name = 'abuse_reports' AND column_name = 'created_at') = 'timestamp without time zone' THEN
+        ALTER TABLE abuse_reports ALTER COLUMN created_at TYPE TIMESTAMP WITH TIME ZONE USING created_at AT TIME ZONE 'UTC';
+    END IF;
+END $$;
+
+-- Opt-in "you are leaving" page before redirecting
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS interstitial BOOLEAN NOT NULL DEFAULT false;
+
+-- Titles and notes, with full-text search over title (stemmed) and destination (split into words)
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS title TEXT;
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS notes TEXT;
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('simple', regexp_replace(long_url, '[^[:alnum:]]+', ' ', 'g')), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_urls_search_vector ON urls USING GIN (search_vector);
+
+CREATE INDEX IF NOT EXISTS idx_urls_short_code_id ON urls(short_code, id);
+
+-- Repeated shorten requests for the same canonical destination reuse a link
+CREATE INDEX IF NOT EXISTS idx_urls_domain_long_url ON urls(domain, long_url);
+
+-- Query passthrough policy and default UTM parameters applied at redirect time
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS query_policy TEXT NOT NULL DEFAULT 'ignore'
+    CHECK (query_policy IN ('ignore', 'merge', 'override'));
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS utm JSONB;
+
+-- API keys identify integrators; links created with a key are owned by its name
+CREATE TABLE IF NOT EXISTS api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT UNIQUE NOT NULL,
+    key_hash TEXT UNIQUE NOT NULL, -- SHA-256 of the key, which is only shown once
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Webhook subscriptions per API key. The secret signs payloads, so it is stored as is.
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id BIGSERIAL PRIMARY KEY,
+    api_key_id BIGINT NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT[] NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_api_key_id ON webhook_subscriptions(api_key_id);
+
+-- Transactional outbox: one row per event and subscription, written in the same
+-- transaction as the change, then delivered (and retried) by the dispatcher
+CREATE TABLE IF NOT EXISTS webhook_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id BIGINT NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_attempt_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    last_status_code INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Key and webhook times used to be stored as UTC wall-clock time without a zone.
+-- The dead-letter view reads the outbox columns, so it is dropped and recreated below.
+DO $$
+DECLARE
+    col RECORD;
+BEGIN
+    FOR col IN
+        SELECT table_name, column_name FROM information_schema.columns
+        WHERE data_type = 'timestamp without time zone' AND (table_name, column_name) IN (
+            ('api_keys', 'created_at'), ('api_keys', 'revoked_at'),
+            ('webhook_subscriptions', 'created_at'),
+            ('webhook_outbox', 'next_attempt_at'), ('webhook_outbox', 'last_attempt_at'),
+            ('webhook_outbox', 'created_at'))
+    LOOP
+        DROP VIEW IF EXISTS webhook_dead_letters;
+        EXECUTE format('ALTER TABLE %I ALTER COLUMN %I TYPE TIMESTAMP WITH TIME ZONE USING %I AT TIME ZONE ''UTC''',
+            col.table_name, col.column_name, col.column_name);
+    END LOOP;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_webhook_outbox_due ON webhook_outbox(next_attempt_at) WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_webhook_outbox_subscription_id ON webhook_outbox(subscription_id, status);
+
+-- Deliveries that exhausted their retries
+CREATE OR REPLACE VIEW webhook_dead_letters AS
+SELECT o.id, o.subscription_id, s.api_key_id, s.url, o.event_id, o.event_type, o.payload,
+       o.attempts, o.last_error, o.last_status_code, o.created_at, o.last_attempt_at
+FROM webhook_outbox o
+JOIN webhook_subscriptions s ON s.id = o.subscription_id
+WHERE o.status = 'dead';
+
+-- Expired links moved out of urls by the sweeper. Variants, tags and clicks are
+-- deleted with the link; snapshot keeps the full row and clicks their count.
+CREATE TABLE IF NOT EXISTS urls_archive (
+    id BIGINT PRIMARY KEY,
+    domain TEXT NOT NULL,
+    short_code TEXT NOT NULL,
+    long_url TEXT NOT NULL,
+    owner TEXT,
+    created_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    clicks BIGINT NOT NULL DEFAULT 0,
+    snapshot JSONB NOT NULL,
+    archived_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_urls_archive_domain_short_code ON urls_archive(domain, short_code);
+
+CREATE INDEX IF NOT EXISTS idx_urls_expires_at ON urls(expires_at) WHERE expires_at IS NOT NULL;
+
+-- Codes released by a delete or an expiry sweep. They can't be claimed again
+-- until available_at, so a new link doesn't inherit the old one's traffic.
+-- created_at is when the code was first released.
+CREATE TABLE IF NOT EXISTS code_tombstones (
+    domain TEXT NOT NULL,
+    short_code TEXT NOT NULL,
+    reason TEXT NOT NULL CHECK (reason IN ('deleted', 'expired')),
+    available_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (domain, short_code)
+);
+
+-- available_at used to be called released_at
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_name = 'code_tombstones' AND column_name = 'released_at') THEN
+        ALTER TABLE code_tombstones RENAME COLUMN released_at TO available_at;
+    END IF;
+END $$;
+
+ALTER INDEX IF EXISTS idx_code_tombstones_released_at RENAME TO idx_code_tombstones_available_at;
+
+CREATE INDEX IF NOT EXISTS idx_code_tombstones_available_at ON code_tombstones(available_at);
+
+-- Custom code length limits go up to 32 characters on some tiers
+ALTER TABLE urls ALTER COLUMN short_code TYPE VARCHAR(32);
+ALTER TABLE clicks ALTER COLUMN short_code TYPE VARCHAR(32);
+
+-- Tier of an API key, selecting its custom code length limit ('' for the default)
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS tier TEXT NOT NULL DEFAULT '';
+
+-- Admin-managed reserved list: 'reserved' words can't be claimed as codes,
+-- 'blocked' words (stored leetspeak-folded) can't appear anywhere in one
+CREATE TABLE IF NOT EXISTS reserved_codes (
+    word TEXT PRIMARY KEY,
+    kind TEXT NOT NULL DEFAULT 'reserved' CHECK (kind IN ('reserved', 'blocked')),
+    note TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);